@@ -9,15 +9,21 @@ import (
 	"github.com/go-resty/resty/v2"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 
 	agent_handler "github.com/a-palonskaa/metrics-server/internal/handlers/agent"
 	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	pb "github.com/a-palonskaa/metrics-server/internal/proto"
 )
 
 func init() {
 	Cmd.PersistentFlags().StringVarP(&Flags.EndpointAddr, "address", "a", "localhost:8080", "Server endpoint address")
 	Cmd.PersistentFlags().IntVarP(&Flags.PollInterval, "pollinterval", "p", 2, "Metrics polling interval")
 	Cmd.PersistentFlags().IntVarP(&Flags.ReportInterval, "reportinterval", "r", 10, "Metrics reporting interval")
+	Cmd.PersistentFlags().StringVar(&Flags.Encoding, "encoding", agent_handler.EncodingJSON, "Body format of http reports: json or protobuf")
+	Cmd.PersistentFlags().StringVarP(&Flags.Transport, "transport", "t", transportHTTP, "Transport used to report metrics: http or grpc")
+	Cmd.PersistentFlags().StringVar(&Flags.StatusAddr, "status-address", "", "Local address of the agent status endpoint, disabled if empty")
 }
 
 var Cmd = &cobra.Command{
@@ -46,6 +52,7 @@ var Cmd = &cobra.Command{
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		memStats := &runtime.MemStats{}
+		agent_handler.SetEncoding(Flags.Encoding)
 		client := resty.New()
 
 		backoffScedule := []time.Duration{
@@ -59,11 +66,33 @@ var Cmd = &cobra.Command{
 		tickerSend := time.NewTicker(time.Duration(Flags.ReportInterval) * time.Second)
 		defer tickerSend.Stop()
 
+		if Flags.StatusAddr != "" {
+			// a report may take a while with retries, the agent is stale once
+			// a few of them in a row failed
+			agent_handler.SetStaleAfter(3 * time.Duration(Flags.ReportInterval) * time.Second)
+			if err := agent_handler.ListenAndServeStatus(Flags.StatusAddr); err != nil {
+				log.Fatal().Msgf("error starting status server: %s", err)
+			}
+		}
+
 		sendMetrics := agent_handler.MakeSendMetricsFunc(client, Flags.EndpointAddr, backoffScedule)
+		if Flags.Transport == transportGRPC {
+			conn, err := grpc.NewClient(Flags.EndpointAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				log.Fatal().Msgf("failed to create grpc client: %s", err)
+			}
+			defer func() {
+				if err := conn.Close(); err != nil {
+					log.Error().Err(err).Msg("failed to close grpc connection")
+				}
+			}()
+			sendMetrics = agent_handler.MakeGRPCSendMetricsFunc(pb.NewMetricsClient(conn), backoffScedule)
+		}
 		for {
 			select {
 			case <-tickerUpdate.C:
 				memstorage.MS.Update(memStats)
+				memstorage.MS.ObserveGCPauses(memStats)
 			case <-tickerSend.C:
 				sendMetrics()
 			}
@@ -5,6 +5,8 @@ import (
 	"strconv"
 
 	"github.com/rs/zerolog/log"
+
+	agent_handler "github.com/a-palonskaa/metrics-server/internal/handlers/agent"
 )
 
 const (
@@ -12,10 +14,18 @@ const (
 	maxPort int = 65535
 )
 
+const (
+	transportHTTP = "http"
+	transportGRPC = "grpc"
+)
+
 type Config struct {
 	EndpointAddr   string `env:"ADDRESS"`
 	ReportInterval int    `env:"REPORT_INTERVAL"`
 	PollInterval   int    `env:"POLL_INTERVAL"`
+	Transport      string `env:"TRANSPORT"`
+	Encoding       string `env:"ENCODING"`
+	StatusAddr     string `env:"STATUS_ADDRESS"`
 }
 
 var Flags Config
@@ -30,6 +40,15 @@ func setFlags(cfg *Config) {
 	if cfg.ReportInterval != 0 {
 		Flags.ReportInterval = cfg.PollInterval
 	}
+	if cfg.Transport != "" {
+		Flags.Transport = cfg.Transport
+	}
+	if cfg.Encoding != "" {
+		Flags.Encoding = cfg.Encoding
+	}
+	if cfg.StatusAddr != "" {
+		Flags.StatusAddr = cfg.StatusAddr
+	}
 }
 
 func validateFlags() {
@@ -37,6 +56,14 @@ func validateFlags() {
 		log.Fatal().Msgf("Error: PollInterval & ReportInterval must be greater than 0")
 	}
 
+	if Flags.Transport != transportHTTP && Flags.Transport != transportGRPC {
+		log.Fatal().Msgf("transport must be %s or %s", transportHTTP, transportGRPC)
+	}
+
+	if Flags.Encoding != agent_handler.EncodingJSON && Flags.Encoding != agent_handler.EncodingProtobuf {
+		log.Fatal().Msgf("encoding must be %s or %s", agent_handler.EncodingJSON, agent_handler.EncodingProtobuf)
+	}
+
 	_, portStr, err := net.SplitHostPort(Flags.EndpointAddr)
 	if err != nil {
 		log.Fatal().Msgf("invalid address format: %s", err)
@@ -50,4 +77,10 @@ func validateFlags() {
 	if port < minPort || port > maxPort {
 		log.Fatal().Msgf("port must be between %d and %d", minPort, maxPort)
 	}
+
+	if Flags.StatusAddr != "" {
+		if _, _, err := net.SplitHostPort(Flags.StatusAddr); err != nil {
+			log.Fatal().Msgf("invalid status address format: %s", err)
+		}
+	}
 }
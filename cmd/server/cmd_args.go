@@ -3,15 +3,24 @@ package main
 import (
 	"net/http"
 	"os"
+	"regexp"
+	"time"
 
 	"github.com/caarlos0/env/v6"
 	"github.com/fatih/color"
 	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 
+	"github.com/a-palonskaa/metrics-server/internal/alerting"
+	"github.com/a-palonskaa/metrics-server/internal/graphite"
 	server_handler "github.com/a-palonskaa/metrics-server/internal/handlers/server"
 	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	"github.com/a-palonskaa/metrics-server/internal/quota"
+	"github.com/a-palonskaa/metrics-server/internal/recording"
+	"github.com/a-palonskaa/metrics-server/internal/selfmetrics"
+	"github.com/a-palonskaa/metrics-server/internal/statsd"
 )
 
 func init() {
@@ -19,6 +28,27 @@ func init() {
 	cmd.PersistentFlags().IntVarP(&Flags.StoreInterval, "i", "i", 300, "Saving server data interval")
 	cmd.PersistentFlags().BoolVarP(&Flags.Restore, "r", "r", true, "Saving or not data saved before")
 	cmd.PersistentFlags().StringVarP(&Flags.FileStoragePath, "f", "f", "server-data.txt", "Filepath")
+	cmd.PersistentFlags().StringVar(&Flags.RateWindows, "rate-windows", "1m,5m", "Comma separated windows of derived counter rates")
+	cmd.PersistentFlags().StringVar(&Flags.StatsdUDPAddr, "statsd-udp", "", "StatsD UDP listener address, disabled if empty")
+	cmd.PersistentFlags().StringVar(&Flags.StatsdTCPAddr, "statsd-tcp", "", "StatsD TCP listener address, disabled if empty")
+	cmd.PersistentFlags().IntVar(&Flags.StatsdFlushInterval, "statsd-flush-interval", 10, "StatsD timers flush interval")
+	cmd.PersistentFlags().StringVar(&Flags.GraphiteAddr, "graphite", "", "Graphite plaintext TCP listener address, disabled if empty")
+	cmd.PersistentFlags().StringArrayVar(&Flags.GraphiteTemplates, "graphite-template", nil, "Graphite path template `[filter ]template`, may be repeated")
+	cmd.PersistentFlags().StringVar(&Flags.GRPCAddr, "grpc", "", "gRPC server address, disabled if empty")
+	cmd.PersistentFlags().StringVar(&Flags.AlertRules, "alert-rules", "", "JSON file of alerting rules, alerting is disabled if empty")
+	cmd.PersistentFlags().IntVar(&Flags.AlertInterval, "alert-interval", 15, "Alerting rules evaluation interval in seconds")
+	cmd.PersistentFlags().StringVar(&Flags.RecordingRules, "recording-rules", "", "JSON file of recording rules, disabled if empty")
+	cmd.PersistentFlags().IntVar(&Flags.RecordingInterval, "recording-interval", 15, "Recording rules evaluation interval in seconds")
+	cmd.PersistentFlags().StringVar(&Flags.AdminToken, "admin-token", "", "Bearer token of the admin API, the admin API is disabled if empty")
+	cmd.PersistentFlags().StringVar(&Flags.AdminAuditLog, "admin-audit-log", "", "File admin actions are appended to, the server log if empty")
+	cmd.PersistentFlags().StringArrayVar(&Flags.TTLs, "ttl", nil, "Series TTL `[type:]pattern=ttl`, the pattern may be omitted with a type, may be repeated, the first match applies")
+	cmd.PersistentFlags().StringVar(&Flags.TTLEvictAfter, "ttl-evict-after", "10m", "How long stale series are kept before they are evicted")
+	cmd.PersistentFlags().StringVar(&Flags.SchemaFile, "schema", "", "JSON file declaring the accepted metrics, every name is accepted if empty")
+	cmd.PersistentFlags().IntVar(&Flags.MaxSeries, "max-series", 0, "Number of series the server stores, unlimited if 0")
+	cmd.PersistentFlags().IntVar(&Flags.AgentMaxSeries, "agent-max-series", 0, "Number of series an agent may update, unlimited if 0")
+	cmd.PersistentFlags().Float64Var(&Flags.AgentRate, "agent-rate", 0, "Updates per second an agent may send, unlimited if 0")
+	cmd.PersistentFlags().IntVar(&Flags.AgentBurst, "agent-burst", 100, "Updates an agent may send at once above its rate")
+	cmd.PersistentFlags().StringVar(&Flags.InfluxCounterFields, "influx-counter-fields", "", "Pattern of <measurement>_<field> cumulative integer fields stored as counters")
 }
 
 var cmd = &cobra.Command{
@@ -45,6 +75,35 @@ var cmd = &cobra.Command{
 		validateFlags()
 	},
 	Run: func(cmd *cobra.Command, args []string) {
+		memstorage.SetRateWindows(parsed.rateWindows)
+
+		if parsed.schema != nil {
+			memstorage.SetAdmission(parsed.schema.Check)
+			server_handler.SetSchema(parsed.schema)
+		}
+
+		memstorage.SetMaxSeries(Flags.MaxSeries)
+		// the StatsD and Graphite listeners share the quota, their clients are
+		// identified by host
+		var limiter *quota.Limiter
+		if Flags.AgentMaxSeries != 0 || Flags.AgentRate != 0 {
+			limiter = quota.NewLimiter(quota.Limits{
+				MaxSeries: Flags.AgentMaxSeries,
+				Rate:      Flags.AgentRate,
+				Burst:     Flags.AgentBurst,
+			})
+			server_handler.SetQuota(limiter)
+		}
+
+		if len(parsed.ttlRules) != 0 {
+			memstorage.SetTTLRules(parsed.ttlRules, parsed.ttlEvictAfter)
+			memstorage.RunExpiryRoutine(memstorage.ExpiryCheckInterval)
+		}
+
+		if Flags.InfluxCounterFields != "" {
+			server_handler.SetInfluxCounterFields(regexp.MustCompile(Flags.InfluxCounterFields))
+		}
+
 		istream, err := os.OpenFile(Flags.FileStoragePath, os.O_RDONLY|os.O_CREATE, 0666)
 		if err != nil {
 			log.Fatal().Err(err)
@@ -70,10 +129,20 @@ var cmd = &cobra.Command{
 			}
 		}()
 
+		selfmetrics.SetGauge("storage_series", "Number of stored series.", func() float64 {
+			return float64(memstorage.MS.SeriesCount())
+		})
+		selfmetrics.SetGauge("stream_subscribers", "Number of SSE and WebSocket subscribers.", func() float64 {
+			return float64(server_handler.StreamHub.Len())
+		})
+
 		r := chi.NewRouter()
 
-		r.Use(server_handler.WithCompression)
+		// the access log comes first so that every later middleware logs
+		// with the request ID and rejected requests are logged too
 		r.Use(server_handler.WithLogging)
+		r.Use(server_handler.WithInstrumentation)
+		r.Use(server_handler.WithCompression)
 
 		if Flags.StoreInterval == 0 {
 			r.Use(server_handler.MakeSavingHandler(ostream))
@@ -81,6 +150,61 @@ var cmd = &cobra.Command{
 			memstorage.RunSavingStorageRoutine(ostream, Flags.StoreInterval)
 		}
 
+		if Flags.StatsdUDPAddr != "" || Flags.StatsdTCPAddr != "" {
+			listener := statsd.NewListener(memstorage.MS, time.Duration(Flags.StatsdFlushInterval)*time.Second)
+			listener.SetQuota(limiter)
+			if err := listener.ListenAndServe(Flags.StatsdUDPAddr, Flags.StatsdTCPAddr); err != nil {
+				log.Fatal().Msgf("error starting statsd listener: %s", err)
+			}
+		}
+
+		if Flags.GraphiteAddr != "" {
+			receiver := graphite.NewReceiver(memstorage.MS, parsed.graphiteTemplates)
+			receiver.SetQuota(limiter)
+			if err := receiver.ListenAndServe(Flags.GraphiteAddr); err != nil {
+				log.Fatal().Msgf("error starting graphite listener: %s", err)
+			}
+		}
+
+		if Flags.GRPCAddr != "" {
+			if err := server_handler.ListenAndServeGRPC(Flags.GRPCAddr); err != nil {
+				log.Fatal().Msgf("error starting grpc server: %s", err)
+			}
+		}
+
+		if Flags.RecordingRules != "" {
+			recording.NewEvaluator(memstorage.MS, parsed.recordingRules).RunEvalRoutine(time.Duration(Flags.RecordingInterval) * time.Second)
+		}
+
+		if Flags.AlertRules != "" {
+			alertCfg := parsed.alertConfig
+			for _, rule := range alertCfg.Rules {
+				memstorage.RetainCounterHistory(rule.Condition.Window())
+			}
+			engine := alerting.NewEngine(memstorage.MS, alertCfg.Rules)
+			if len(alertCfg.Webhooks) > 0 {
+				notifier := alerting.NewWebhookNotifier(alertCfg.Webhooks)
+				notifier.RunFlushRoutine(alertCfg.GroupWait)
+				engine.SetNotifier(notifier)
+			}
+			engine.RunEvalRoutine(time.Duration(Flags.AlertInterval) * time.Second)
+			server_handler.SetAlertEngine(engine)
+		}
+
+		if Flags.AdminToken != "" {
+			opts := server_handler.AdminOptions{Token: Flags.AdminToken, SnapshotFile: ostream}
+			if Flags.AdminAuditLog != "" {
+				auditStream, err := os.OpenFile(Flags.AdminAuditLog, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+				if err != nil {
+					log.Fatal().Msgf("error opening admin audit log: %s", err)
+				}
+				defer auditStream.Close()
+				audit := zerolog.New(auditStream).With().Timestamp().Logger()
+				opts.Audit = &audit
+			}
+			server_handler.SetAdminOptions(opts)
+		}
+
 		server_handler.RouteRequests(r)
 
 		if err := http.ListenAndServe(Flags.EndpointAddr, r); err != nil {
@@ -1,11 +1,21 @@
 package main
 
 import (
+	"fmt"
 	"net"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/a-palonskaa/metrics-server/internal/alerting"
+	"github.com/a-palonskaa/metrics-server/internal/graphite"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	"github.com/a-palonskaa/metrics-server/internal/recording"
+	"github.com/a-palonskaa/metrics-server/internal/schema"
 )
 
 const (
@@ -18,10 +28,53 @@ type Config struct {
 	StoreInterval   int    `env:"STORE_INTERVAL"`
 	FileStoragePath string `env:"FILE_STORAGE_PATH"`
 	Restore         bool   `env:"RESTORE"`
+	RateWindows     string `env:"RATE_WINDOWS"`
+
+	StatsdUDPAddr       string `env:"STATSD_UDP_ADDRESS"`
+	StatsdTCPAddr       string `env:"STATSD_TCP_ADDRESS"`
+	StatsdFlushInterval int    `env:"STATSD_FLUSH_INTERVAL"`
+
+	InfluxCounterFields string `env:"INFLUX_COUNTER_FIELDS"`
+
+	GraphiteAddr      string   `env:"GRAPHITE_ADDRESS"`
+	GraphiteTemplates []string `env:"GRAPHITE_TEMPLATES" envSeparator:";"`
+
+	GRPCAddr string `env:"GRPC_ADDRESS"`
+
+	AlertRules    string `env:"ALERT_RULES"`
+	AlertInterval int    `env:"ALERT_INTERVAL"`
+
+	RecordingRules    string `env:"RECORDING_RULES"`
+	RecordingInterval int    `env:"RECORDING_INTERVAL"`
+
+	AdminToken    string `env:"ADMIN_TOKEN"`
+	AdminAuditLog string `env:"ADMIN_AUDIT_LOG"`
+
+	TTLs          []string `env:"TTL" envSeparator:";"`
+	TTLEvictAfter string   `env:"TTL_EVICT_AFTER"`
+
+	SchemaFile string `env:"SCHEMA_FILE"`
+
+	MaxSeries      int     `env:"MAX_SERIES"`
+	AgentMaxSeries int     `env:"AGENT_MAX_SERIES"`
+	AgentRate      float64 `env:"AGENT_RATE"`
+	AgentBurst     int     `env:"AGENT_BURST"`
 }
 
 var Flags Config
 
+// parsed holds what validateFlags parsed out of Flags, so that Run does not
+// parse it again.
+var parsed struct {
+	rateWindows       []time.Duration
+	graphiteTemplates []graphite.Template
+	ttlRules          []memstorage.TTLRule
+	ttlEvictAfter     time.Duration
+	recordingRules    []recording.Rule
+	alertConfig       alerting.Config
+	schema            *schema.Registry
+}
+
 func setFlags(cfg *Config) {
 	if cfg.EndpointAddr != "" {
 		Flags.EndpointAddr = cfg.EndpointAddr
@@ -38,6 +91,90 @@ func setFlags(cfg *Config) {
 	if _, exists := os.LookupEnv("STORE_INTERVAL"); exists {
 		Flags.StoreInterval = cfg.StoreInterval
 	}
+
+	if cfg.RateWindows != "" {
+		Flags.RateWindows = cfg.RateWindows
+	}
+
+	if cfg.StatsdUDPAddr != "" {
+		Flags.StatsdUDPAddr = cfg.StatsdUDPAddr
+	}
+
+	if cfg.StatsdTCPAddr != "" {
+		Flags.StatsdTCPAddr = cfg.StatsdTCPAddr
+	}
+
+	if cfg.StatsdFlushInterval != 0 {
+		Flags.StatsdFlushInterval = cfg.StatsdFlushInterval
+	}
+
+	if cfg.InfluxCounterFields != "" {
+		Flags.InfluxCounterFields = cfg.InfluxCounterFields
+	}
+
+	if cfg.GraphiteAddr != "" {
+		Flags.GraphiteAddr = cfg.GraphiteAddr
+	}
+
+	if len(cfg.GraphiteTemplates) != 0 {
+		Flags.GraphiteTemplates = cfg.GraphiteTemplates
+	}
+
+	if cfg.GRPCAddr != "" {
+		Flags.GRPCAddr = cfg.GRPCAddr
+	}
+
+	if cfg.AlertRules != "" {
+		Flags.AlertRules = cfg.AlertRules
+	}
+
+	if cfg.AlertInterval != 0 {
+		Flags.AlertInterval = cfg.AlertInterval
+	}
+
+	if cfg.RecordingRules != "" {
+		Flags.RecordingRules = cfg.RecordingRules
+	}
+
+	if cfg.RecordingInterval != 0 {
+		Flags.RecordingInterval = cfg.RecordingInterval
+	}
+
+	if cfg.AdminToken != "" {
+		Flags.AdminToken = cfg.AdminToken
+	}
+
+	if cfg.AdminAuditLog != "" {
+		Flags.AdminAuditLog = cfg.AdminAuditLog
+	}
+
+	if len(cfg.TTLs) != 0 {
+		Flags.TTLs = cfg.TTLs
+	}
+
+	if cfg.TTLEvictAfter != "" {
+		Flags.TTLEvictAfter = cfg.TTLEvictAfter
+	}
+
+	if cfg.SchemaFile != "" {
+		Flags.SchemaFile = cfg.SchemaFile
+	}
+
+	if cfg.MaxSeries != 0 {
+		Flags.MaxSeries = cfg.MaxSeries
+	}
+
+	if cfg.AgentMaxSeries != 0 {
+		Flags.AgentMaxSeries = cfg.AgentMaxSeries
+	}
+
+	if cfg.AgentRate != 0 {
+		Flags.AgentRate = cfg.AgentRate
+	}
+
+	if cfg.AgentBurst != 0 {
+		Flags.AgentBurst = cfg.AgentBurst
+	}
 }
 
 func validateFlags() {
@@ -54,4 +191,115 @@ func validateFlags() {
 	if port < minPort || port > maxPort {
 		log.Fatal().Msgf("port must be between %d and %d", minPort, maxPort)
 	}
+
+	rateWindows, err := parseRateWindows(Flags.RateWindows)
+	if err != nil {
+		log.Fatal().Msgf("invalid rate windows: %s", err)
+	}
+	parsed.rateWindows = rateWindows
+
+	if Flags.StatsdFlushInterval <= 0 {
+		log.Fatal().Msgf("statsd flush interval must be greater than 0")
+	}
+
+	if _, err := regexp.Compile(Flags.InfluxCounterFields); err != nil {
+		log.Fatal().Msgf("invalid influx counter fields pattern: %s", err)
+	}
+
+	templates, err := parseGraphiteTemplates(Flags.GraphiteTemplates)
+	if err != nil {
+		log.Fatal().Msgf("invalid graphite template: %s", err)
+	}
+	parsed.graphiteTemplates = templates
+
+	ttlRules, err := parseTTLRules(Flags.TTLs)
+	if err != nil {
+		log.Fatal().Msgf("invalid ttl: %s", err)
+	}
+	parsed.ttlRules = ttlRules
+
+	evictAfter, err := time.ParseDuration(Flags.TTLEvictAfter)
+	if err != nil || evictAfter < 0 {
+		log.Fatal().Msgf("ttl eviction delay must be a non-negative duration: %s", Flags.TTLEvictAfter)
+	}
+	parsed.ttlEvictAfter = evictAfter
+
+	if Flags.SchemaFile != "" {
+		registry, err := schema.Load(Flags.SchemaFile)
+		if err != nil {
+			log.Fatal().Msgf("invalid schema: %s", err)
+		}
+		parsed.schema = registry
+	}
+
+	if Flags.MaxSeries < 0 || Flags.AgentMaxSeries < 0 {
+		log.Fatal().Msgf("series limits must not be negative")
+	}
+
+	if Flags.AgentRate < 0 || Flags.AgentBurst < 0 {
+		log.Fatal().Msgf("agent rate and burst must not be negative")
+	}
+
+	if Flags.AlertInterval <= 0 {
+		log.Fatal().Msgf("alert evaluation interval must be greater than 0")
+	}
+
+	if Flags.AlertRules != "" {
+		cfg, err := alerting.LoadConfig(Flags.AlertRules)
+		if err != nil {
+			log.Fatal().Msgf("invalid alert rules: %s", err)
+		}
+		parsed.alertConfig = cfg
+	}
+
+	if Flags.RecordingInterval <= 0 {
+		log.Fatal().Msgf("recording rules evaluation interval must be greater than 0")
+	}
+
+	if Flags.RecordingRules != "" {
+		rules, err := recording.LoadRules(Flags.RecordingRules)
+		if err != nil {
+			log.Fatal().Msgf("invalid recording rules: %s", err)
+		}
+		parsed.recordingRules = rules
+	}
+}
+
+func parseTTLRules(specs []string) ([]memstorage.TTLRule, error) {
+	rules := make([]memstorage.TTLRule, 0, len(specs))
+	for _, s := range specs {
+		rule, err := memstorage.ParseTTLRule(s)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func parseGraphiteTemplates(specs []string) ([]graphite.Template, error) {
+	templates := make([]graphite.Template, 0, len(specs))
+	for _, s := range specs {
+		t, err := graphite.ParseTemplate(s)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+func parseRateWindows(s string) ([]time.Duration, error) {
+	var windows []time.Duration
+	for _, part := range strings.Split(s, ",") {
+		window, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		if window <= 0 {
+			return nil, fmt.Errorf("window must be positive: %s", part)
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
 }
@@ -0,0 +1,331 @@
+package alerting
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+const (
+	StatePending  = "pending"
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+// ResolvedRetention is how long resolved alerts are still listed.
+var ResolvedRetention = 15 * time.Minute
+
+//easyjson:json
+type Alert struct {
+	Rule        string            `json:"rule"`
+	State       string            `json:"state"`
+	Series      string            `json:"series"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Value       float64           `json:"value"`
+	ActiveAt    time.Time         `json:"activeAt"`
+	FiredAt     *time.Time        `json:"firedAt,omitempty"`
+	ResolvedAt  *time.Time        `json:"resolvedAt,omitempty"`
+	Silenced    bool              `json:"silenced,omitempty"`
+
+	notified string // the last state passed to the notifier
+}
+
+//easyjson:json
+type AlertList []Alert
+
+// Engine evaluates rules against a storage. Every series matching a rule
+// condition has its own alert, which is pending until the condition held for
+// the rule's For, then firing until the condition no longer holds.
+type Engine struct {
+	storage  *memstorage.MetricsStorage
+	rules    []Rule
+	silences *Silences
+	notifier Notifier
+
+	mu      sync.Mutex
+	started time.Time
+	alerts  map[alertKey]*Alert
+}
+
+type alertKey struct {
+	rule   string
+	series string
+}
+
+// sample is a series matching a condition together with the compared value.
+type sample struct {
+	series string
+	labels map[string]string
+	value  float64
+}
+
+func NewEngine(storage *memstorage.MetricsStorage, rules []Rule) *Engine {
+	return &Engine{
+		storage:  storage,
+		rules:    rules,
+		silences: NewSilences(),
+		alerts:   make(map[alertKey]*Alert),
+	}
+}
+
+// SetNotifier sets the receiver of firing and resolved alerts, it must be
+// called before the first evaluation.
+func (e *Engine) SetNotifier(notifier Notifier) {
+	e.notifier = notifier
+}
+
+func (e *Engine) Silences() *Silences {
+	return e.silences
+}
+
+// Rules returns the evaluated rules.
+func (e *Engine) Rules() []Rule {
+	return e.rules
+}
+
+// Eval evaluates every rule once and updates the alert states.
+func (e *Engine) Eval(now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.started.IsZero() {
+		e.started = now
+	}
+
+	for _, rule := range e.rules {
+		active := make(map[alertKey]bool)
+		for _, s := range e.evalCondition(rule.Condition, now) {
+			key := alertKey{rule: rule.Name, series: s.series}
+			active[key] = true
+
+			alert, ok := e.alerts[key]
+			if !ok || alert.State == StateResolved {
+				alert = &Alert{
+					Rule:        rule.Name,
+					State:       StatePending,
+					Series:      s.series,
+					Labels:      mergeLabels(s.labels, rule.Labels),
+					Annotations: rule.Annotations,
+					ActiveAt:    now,
+				}
+				e.alerts[key] = alert
+			}
+			alert.Value = s.value
+			if alert.ActiveAt.Equal(now) && rule.For > 0 {
+				logTransition(alert)
+			}
+
+			if alert.State == StatePending && now.Sub(alert.ActiveAt) >= rule.For {
+				fired := now
+				alert.State = StateFiring
+				alert.FiredAt = &fired
+				logTransition(alert)
+			}
+		}
+
+		for key, alert := range e.alerts {
+			if key.rule != rule.Name || active[key] {
+				continue
+			}
+			switch alert.State {
+			case StatePending:
+				delete(e.alerts, key)
+			case StateFiring:
+				resolved := now
+				alert.State = StateResolved
+				alert.ResolvedAt = &resolved
+				logTransition(alert)
+			case StateResolved:
+				if now.Sub(*alert.ResolvedAt) > ResolvedRetention {
+					delete(e.alerts, key)
+				}
+			}
+		}
+	}
+
+	e.notify(now)
+}
+
+// notify passes every alert to the notifier once per state. Silenced alerts
+// are held back until the silence ends, but the resolution of an alert whose
+// firing was notified is always passed on.
+func (e *Engine) notify(now time.Time) {
+	for _, alert := range e.alerts {
+		alert.Silenced = e.silences.Silenced(*alert, now)
+		if e.notifier == nil || alert.notified == alert.State {
+			continue
+		}
+
+		switch alert.State {
+		case StateFiring:
+			if alert.Silenced {
+				continue
+			}
+		case StateResolved:
+			if alert.notified != StateFiring {
+				continue
+			}
+		default:
+			continue
+		}
+		alert.notified = alert.State
+		e.notifier.Notify(*alert)
+	}
+}
+
+// Alerts returns copies of the pending, firing and recently resolved alerts
+// ordered by rule and series.
+func (e *Engine) Alerts() AlertList {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	list := make(AlertList, 0, len(e.alerts))
+	for _, alert := range e.alerts {
+		list = append(list, *alert)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Rule != list[j].Rule {
+			return list[i].Rule < list[j].Rule
+		}
+		return list[i].Series < list[j].Series
+	})
+	return list
+}
+
+// RunEvalRoutine evaluates the rules every interval in the background.
+func (e *Engine) RunEvalRoutine(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			e.Eval(now)
+		}
+	}()
+}
+
+func (e *Engine) evalCondition(c Condition, now time.Time) []sample {
+	var samples []sample
+	switch c.fn {
+	case fnAbsent:
+		return e.evalAbsent(c, now)
+	case fnRate, fnIncrease:
+		for _, series := range e.storage.Series(metrics.CounterName) {
+			labels, ok := c.selects(series)
+			if !ok {
+				continue
+			}
+			var val metrics.Gauge
+			if c.fn == fnRate {
+				val, ok = e.storage.CounterRate(series, c.window)
+			} else {
+				val, ok = e.storage.CounterIncrease(series, c.window)
+			}
+			if ok {
+				samples = append(samples, sample{series: series, labels: labels, value: float64(val)})
+			}
+		}
+	default:
+		seen := make(map[string]bool)
+		for _, series := range e.storage.Series(metrics.GaugeName) {
+			labels, ok := c.selects(series)
+			if !ok {
+				continue
+			}
+			if val, ok := e.storage.GetGaugeValue(series); ok {
+				seen[series] = true
+				samples = append(samples, sample{series: series, labels: labels, value: float64(val)})
+			}
+		}
+		// a counter is only compared if there is no gauge of the same name
+		for _, series := range e.storage.Series(metrics.CounterName) {
+			labels, ok := c.selects(series)
+			if !ok || seen[series] {
+				continue
+			}
+			if val, ok := e.storage.GetCounterValue(series); ok {
+				samples = append(samples, sample{series: series, labels: labels, value: float64(val)})
+			}
+		}
+	}
+
+	matching := samples[:0]
+	for _, s := range samples {
+		if c.compare(s.value) {
+			matching = append(matching, s)
+		}
+	}
+	return matching
+}
+
+// evalAbsent returns the matching series of any type not updated for the
+// window, the value is the number of seconds since the last update. Series
+// never updated since the first evaluation count from that evaluation. If no
+// series matches at all, a single sample named after the metric is returned.
+func (e *Engine) evalAbsent(c Condition, now time.Time) []sample {
+	type lastSeen struct {
+		labels map[string]string
+		time   time.Time
+	}
+	seen := make(map[string]lastSeen)
+
+	types := []string{metrics.GaugeName, metrics.CounterName, metrics.HistogramName, metrics.SummaryName, metrics.SetName}
+	for _, mType := range types {
+		for _, series := range e.storage.Series(mType) {
+			labels, ok := c.selects(series)
+			if !ok {
+				continue
+			}
+			last, ok := e.storage.LastUpdate(mType, series)
+			if !ok || last.Before(e.started) {
+				last = e.started
+			}
+			if prev, ok := seen[series]; !ok || last.After(prev.time) {
+				seen[series] = lastSeen{labels: labels, time: last}
+			}
+		}
+	}
+
+	var samples []sample
+	if len(seen) == 0 {
+		if age := now.Sub(e.started); age >= c.window {
+			labels := c.absentLabels()
+			samples = append(samples, sample{series: metrics.SeriesName(c.name, labels), labels: labels, value: age.Seconds()})
+		}
+		return samples
+	}
+
+	for series, s := range seen {
+		if age := now.Sub(s.time); age >= c.window {
+			samples = append(samples, sample{series: series, labels: s.labels, value: age.Seconds()})
+		}
+	}
+	return samples
+}
+
+func mergeLabels(series, rule map[string]string) map[string]string {
+	labels := make(map[string]string, len(series)+len(rule))
+	for k, v := range series {
+		labels[k] = v
+	}
+	for k, v := range rule {
+		labels[k] = v
+	}
+	return labels
+}
+
+func logTransition(alert *Alert) {
+	event := log.Info()
+	if alert.State == StateFiring {
+		event = log.Warn()
+	}
+	event.Str("rule", alert.Rule).
+		Str("series", alert.Series).
+		Float64("value", alert.Value).
+		Msg("alert " + alert.State)
+}
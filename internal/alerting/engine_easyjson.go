@@ -0,0 +1,301 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package alerting
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson42c839f2DecodeGithubComAPalonskaaMetricsServerInternalAlerting(in *jlexer.Lexer, out *AlertList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(AlertList, 0, 0)
+			} else {
+				*out = AlertList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 Alert
+			(v1).UnmarshalEasyJSON(in)
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson42c839f2EncodeGithubComAPalonskaaMetricsServerInternalAlerting(out *jwriter.Writer, in AlertList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			(v3).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v AlertList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson42c839f2EncodeGithubComAPalonskaaMetricsServerInternalAlerting(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v AlertList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson42c839f2EncodeGithubComAPalonskaaMetricsServerInternalAlerting(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *AlertList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson42c839f2DecodeGithubComAPalonskaaMetricsServerInternalAlerting(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *AlertList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson42c839f2DecodeGithubComAPalonskaaMetricsServerInternalAlerting(l, v)
+}
+func easyjson42c839f2DecodeGithubComAPalonskaaMetricsServerInternalAlerting1(in *jlexer.Lexer, out *Alert) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "rule":
+			out.Rule = string(in.String())
+		case "state":
+			out.State = string(in.String())
+		case "series":
+			out.Series = string(in.String())
+		case "labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Labels = make(map[string]string)
+				} else {
+					out.Labels = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v4 string
+					v4 = string(in.String())
+					(out.Labels)[key] = v4
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "annotations":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Annotations = make(map[string]string)
+				} else {
+					out.Annotations = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v5 string
+					v5 = string(in.String())
+					(out.Annotations)[key] = v5
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "value":
+			out.Value = float64(in.Float64())
+		case "activeAt":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.ActiveAt).UnmarshalJSON(data))
+			}
+		case "firedAt":
+			if in.IsNull() {
+				in.Skip()
+				out.FiredAt = nil
+			} else {
+				if out.FiredAt == nil {
+					out.FiredAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.FiredAt).UnmarshalJSON(data))
+				}
+			}
+		case "resolvedAt":
+			if in.IsNull() {
+				in.Skip()
+				out.ResolvedAt = nil
+			} else {
+				if out.ResolvedAt == nil {
+					out.ResolvedAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.ResolvedAt).UnmarshalJSON(data))
+				}
+			}
+		case "silenced":
+			out.Silenced = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson42c839f2EncodeGithubComAPalonskaaMetricsServerInternalAlerting1(out *jwriter.Writer, in Alert) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"rule\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Rule))
+	}
+	{
+		const prefix string = ",\"state\":"
+		out.RawString(prefix)
+		out.String(string(in.State))
+	}
+	{
+		const prefix string = ",\"series\":"
+		out.RawString(prefix)
+		out.String(string(in.Series))
+	}
+	if len(in.Labels) != 0 {
+		const prefix string = ",\"labels\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v6First := true
+			for v6Name, v6Value := range in.Labels {
+				if v6First {
+					v6First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v6Name))
+				out.RawByte(':')
+				out.String(string(v6Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.Annotations) != 0 {
+		const prefix string = ",\"annotations\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v7First := true
+			for v7Name, v7Value := range in.Annotations {
+				if v7First {
+					v7First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v7Name))
+				out.RawByte(':')
+				out.String(string(v7Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"value\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Value))
+	}
+	{
+		const prefix string = ",\"activeAt\":"
+		out.RawString(prefix)
+		out.Raw((in.ActiveAt).MarshalJSON())
+	}
+	if in.FiredAt != nil {
+		const prefix string = ",\"firedAt\":"
+		out.RawString(prefix)
+		out.Raw((*in.FiredAt).MarshalJSON())
+	}
+	if in.ResolvedAt != nil {
+		const prefix string = ",\"resolvedAt\":"
+		out.RawString(prefix)
+		out.Raw((*in.ResolvedAt).MarshalJSON())
+	}
+	if in.Silenced {
+		const prefix string = ",\"silenced\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Silenced))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Alert) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson42c839f2EncodeGithubComAPalonskaaMetricsServerInternalAlerting1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Alert) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson42c839f2EncodeGithubComAPalonskaaMetricsServerInternalAlerting1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Alert) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson42c839f2DecodeGithubComAPalonskaaMetricsServerInternalAlerting1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Alert) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson42c839f2DecodeGithubComAPalonskaaMetricsServerInternalAlerting1(l, v)
+}
@@ -0,0 +1,118 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+func mustRule(t *testing.T, cfg RuleConfig) Rule {
+	t.Helper()
+	rule, err := ParseRule(cfg)
+	require.NoError(t, err)
+	return rule
+}
+
+func TestEngine_Threshold(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	storage.AddGauge(`HeapAlloc{host="a"}`, 2e9)
+	storage.AddGauge(`HeapAlloc{host="b"}`, 1e6)
+
+	engine := NewEngine(storage, []Rule{mustRule(t, RuleConfig{
+		Name:   "HighHeap",
+		Expr:   "HeapAlloc > 1e9",
+		For:    "5m",
+		Labels: map[string]string{"severity": "warning"},
+	})})
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	engine.Eval(start)
+	alerts := engine.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StatePending, alerts[0].State)
+	assert.Equal(t, `HeapAlloc{host="a"}`, alerts[0].Series)
+	assert.Equal(t, map[string]string{"host": "a", "severity": "warning"}, alerts[0].Labels)
+	assert.Equal(t, 2e9, alerts[0].Value)
+
+	engine.Eval(start.Add(5 * time.Minute))
+	alerts = engine.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StateFiring, alerts[0].State)
+	require.NotNil(t, alerts[0].FiredAt)
+
+	storage.AddGauge(`HeapAlloc{host="a"}`, 1e6)
+	engine.Eval(start.Add(6 * time.Minute))
+	alerts = engine.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StateResolved, alerts[0].State)
+	require.NotNil(t, alerts[0].ResolvedAt)
+
+	engine.Eval(start.Add(7*time.Minute + ResolvedRetention))
+	assert.Empty(t, engine.Alerts())
+}
+
+func TestEngine_PendingCleared(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	storage.AddGauge("Load", 10)
+
+	engine := NewEngine(storage, []Rule{mustRule(t, RuleConfig{Name: "HighLoad", Expr: "Load >= 10", For: "1m"})})
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	engine.Eval(start)
+	require.Len(t, engine.Alerts(), 1)
+
+	storage.AddGauge("Load", 1)
+	engine.Eval(start.Add(30 * time.Second))
+	assert.Empty(t, engine.Alerts())
+}
+
+func TestEngine_Rate(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	storage.AddCounter(`requests{host="a"}`, 0)
+
+	engine := NewEngine(storage, []Rule{mustRule(t, RuleConfig{Name: "NoTraffic", Expr: "increase(requests[1m]) < 1"})})
+	engine.Eval(time.Now())
+	alerts := engine.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StateFiring, alerts[0].State)
+
+	storage.AddCounter(`requests{host="a"}`, 5)
+	engine.Eval(time.Now())
+	alerts = engine.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StateResolved, alerts[0].State)
+}
+
+func TestEngine_Absent(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	engine := NewEngine(storage, []Rule{
+		mustRule(t, RuleConfig{Name: "AgentDown", Expr: `absent(PollCount{agent="a"}[2m])`}),
+	})
+
+	engine.Eval(time.Now().Add(-2 * time.Minute))
+	assert.Empty(t, engine.Alerts())
+
+	// nothing was reported since the first evaluation
+	engine.Eval(time.Now())
+	alerts := engine.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StateFiring, alerts[0].State)
+	assert.Equal(t, metrics.SeriesName("PollCount", map[string]string{"agent": "a"}), alerts[0].Series)
+
+	storage.AddCounter(`PollCount{agent="a"}`, 1)
+	engine.Eval(time.Now())
+	alerts = engine.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StateResolved, alerts[0].State)
+
+	// the agent stopped reporting
+	engine.Eval(time.Now().Add(3 * time.Minute))
+	alerts = engine.Alerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StateFiring, alerts[0].State)
+	assert.GreaterOrEqual(t, alerts[0].Value, float64(180))
+}
@@ -0,0 +1,236 @@
+package alerting
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+)
+
+const (
+	fnValue    = ""
+	fnRate     = "rate"
+	fnIncrease = "increase"
+	fnAbsent   = "absent"
+)
+
+var comparisons = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// Condition is a parsed rule expression:
+//
+//	HeapAlloc > 1e9                        gauge or counter value
+//	rate(requests{host="a"}[5m]) < 10      per-second counter increase
+//	increase(errors[1m]) > 0               counter increase
+//	absent(PollCount{agent=~"web.*"}[3m])  no update for the window
+type Condition struct {
+	fn        string
+	name      string
+	matchers  []metrics.LabelMatcher
+	window    time.Duration
+	op        string
+	threshold float64
+}
+
+// ParseCondition parses a rule expression, see Condition.
+func ParseCondition(expr string) (Condition, error) {
+	var c Condition
+	s := strings.TrimSpace(expr)
+
+	for _, fn := range []string{fnRate, fnIncrease, fnAbsent} {
+		rest, ok := strings.CutPrefix(s, fn+"(")
+		if !ok {
+			continue
+		}
+		end := strings.LastIndex(rest, ")")
+		if end < 0 {
+			return c, fmt.Errorf("missing ) in %q", expr)
+		}
+		c.fn = fn
+		if err := c.parseSelector(rest[:end], true); err != nil {
+			return c, err
+		}
+		s = strings.TrimSpace(rest[end+1:])
+		break
+	}
+
+	if c.fn == fnAbsent {
+		if s != "" {
+			return c, fmt.Errorf("unexpected %q after absent()", s)
+		}
+		return c, nil
+	}
+
+	if c.fn == fnValue {
+		// the comparison follows the label matchers, which contain = and !
+		from := strings.LastIndex(s, "}") + 1
+		i := strings.IndexAny(s[from:], "<>=!")
+		if i < 0 {
+			return c, fmt.Errorf("missing comparison in %q", expr)
+		}
+		i += from
+		if err := c.parseSelector(s[:i], false); err != nil {
+			return c, err
+		}
+		s = s[i:]
+	}
+
+	for _, op := range comparisons {
+		if rest, ok := strings.CutPrefix(s, op); ok {
+			c.op = op
+			s = strings.TrimSpace(rest)
+			break
+		}
+	}
+	if c.op == "" {
+		return c, fmt.Errorf("missing comparison in %q", expr)
+	}
+
+	threshold, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return c, fmt.Errorf("invalid threshold %q", s)
+	}
+	c.threshold = threshold
+	return c, nil
+}
+
+// parseSelector parses `name{matchers}` followed by `[window]` if withWindow
+// is set. The matcher values may be quoted.
+func (c *Condition) parseSelector(s string, withWindow bool) error {
+	s = strings.TrimSpace(s)
+
+	if withWindow {
+		open := strings.LastIndex(s, "[")
+		if open < 0 || !strings.HasSuffix(s, "]") {
+			return fmt.Errorf("%s() needs a [window] in %q", c.fn, s)
+		}
+		window, err := time.ParseDuration(s[open+1 : len(s)-1])
+		if err != nil {
+			return err
+		}
+		if window <= 0 {
+			return fmt.Errorf("window must be positive: %s", s[open:])
+		}
+		c.window = window
+		s = strings.TrimSpace(s[:open])
+	}
+
+	name, labels, ok := strings.Cut(s, "{")
+	c.name = strings.TrimSpace(name)
+	if c.name == "" || strings.ContainsAny(c.name, " \t}") {
+		return fmt.Errorf("invalid metric name %q", name)
+	}
+	if !ok {
+		return nil
+	}
+	labels, ok = strings.CutSuffix(strings.TrimSpace(labels), "}")
+	if !ok {
+		return fmt.Errorf("missing } in %q", s)
+	}
+
+	for _, part := range splitMatchers(labels) {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		matcher, err := parseMatcher(part)
+		if err != nil {
+			return err
+		}
+		c.matchers = append(c.matchers, matcher)
+	}
+	return nil
+}
+
+// splitMatchers splits on commas outside of quoted values.
+func splitMatchers(s string) []string {
+	var parts []string
+	start, quoted := 0, false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// parseMatcher parses `name op value` where op is the first operator after
+// the label name, so that values may contain operator characters.
+func parseMatcher(s string) (metrics.LabelMatcher, error) {
+	i := strings.IndexAny(s, "=!")
+	if i <= 0 {
+		return metrics.LabelMatcher{}, fmt.Errorf("invalid label matcher %q", s)
+	}
+	op := s[i : i+1]
+	if i+1 < len(s) && (s[i+1] == '=' || s[i+1] == '~') {
+		op = s[i : i+2]
+	}
+
+	value := strings.TrimSpace(s[i+len(op):])
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return metrics.LabelMatcher{}, fmt.Errorf("invalid label value %s", value)
+		}
+		value = unquoted
+	}
+	return metrics.NewLabelMatcher(strings.TrimSpace(s[:i]), op, value)
+}
+
+// Window is the rate, increase or absence window, zero for plain values.
+func (c Condition) Window() time.Duration {
+	return c.window
+}
+
+func (c Condition) compare(val float64) bool {
+	switch c.op {
+	case ">":
+		return val > c.threshold
+	case "<":
+		return val < c.threshold
+	case ">=":
+		return val >= c.threshold
+	case "<=":
+		return val <= c.threshold
+	case "==":
+		return val == c.threshold
+	case "!=":
+		return val != c.threshold
+	}
+	return false
+}
+
+// selects reports whether a series belongs to the condition and returns its
+// labels.
+func (c Condition) selects(series string) (map[string]string, bool) {
+	name, labels, err := metrics.ParseSeriesName(series)
+	if err != nil || name != c.name {
+		return nil, false
+	}
+	for _, matcher := range c.matchers {
+		if !matcher.Matches(labels) {
+			return nil, false
+		}
+	}
+	return labels, true
+}
+
+// absentLabels are the labels of the alert raised when no series matches at
+// all, taken from the equality matchers.
+func (c Condition) absentLabels() map[string]string {
+	labels := make(map[string]string)
+	for _, matcher := range c.matchers {
+		if matcher.Op == metrics.MatchEqual && matcher.Value != "" {
+			labels[matcher.Name] = matcher.Value
+		}
+	}
+	return labels
+}
@@ -0,0 +1,112 @@
+package alerting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCondition(t *testing.T) {
+	tests := []struct {
+		name      string
+		expr      string
+		fn        string
+		metric    string
+		matchers  []string
+		window    time.Duration
+		op        string
+		threshold float64
+		wantErr   bool
+	}{
+		{name: "gauge threshold", expr: "HeapAlloc > 1e9", metric: "HeapAlloc", op: ">", threshold: 1e9},
+		{name: "no spaces", expr: "HeapAlloc>=10", metric: "HeapAlloc", op: ">=", threshold: 10},
+		{
+			name:      "labels",
+			expr:      `Load{host="a,b", dc!~"eu.*"} != 0`,
+			metric:    "Load",
+			matchers:  []string{`host="a,b"`, `dc!~"eu.*"`},
+			op:        "!=",
+			threshold: 0,
+		},
+		{
+			name:      "rate",
+			expr:      "rate(requests{code=~\"5..\"}[5m]) < 0.5",
+			fn:        fnRate,
+			metric:    "requests",
+			matchers:  []string{`code=~"5.."`},
+			window:    5 * time.Minute,
+			op:        "<",
+			threshold: 0.5,
+		},
+		{name: "increase", expr: "increase(errors[1m]) == 0", fn: fnIncrease, metric: "errors", window: time.Minute, op: "==", threshold: 0},
+		{
+			name:     "absent",
+			expr:     `absent(PollCount{agent="a"}[3m])`,
+			fn:       fnAbsent,
+			metric:   "PollCount",
+			matchers: []string{`agent="a"`},
+			window:   3 * time.Minute,
+		},
+		{name: "missing comparison", expr: "HeapAlloc", wantErr: true},
+		{name: "missing threshold", expr: "HeapAlloc >", wantErr: true},
+		{name: "missing name", expr: "> 5", wantErr: true},
+		{name: "rate without window", expr: "rate(requests) > 1", wantErr: true},
+		{name: "absent with comparison", expr: "absent(x[1m]) > 1", wantErr: true},
+		{name: "bad matcher", expr: "x{host} > 1", wantErr: true},
+		{name: "bad regexp", expr: `x{host=~"("} > 1`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := ParseCondition(test.expr)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.fn, c.fn)
+			assert.Equal(t, test.metric, c.name)
+			assert.Equal(t, test.window, c.window)
+			assert.Equal(t, test.op, c.op)
+			assert.Equal(t, test.threshold, c.threshold)
+
+			var matchers []string
+			for _, m := range c.matchers {
+				matchers = append(matchers, m.String())
+			}
+			assert.Equal(t, test.matchers, matchers)
+		})
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{
+		"rules": [
+			{"name": "HighHeap", "expr": "HeapAlloc > 1e9", "for": "5m", "labels": {"severity": "warning"}},
+			{"name": "AgentDown", "expr": "absent(PollCount[2m])"}
+		],
+		"webhooks": [{"url": "http://localhost/hook", "groupBy": ["rule"]}],
+		"groupWait": "30s"
+	}`))
+	require.NoError(t, err)
+	require.Len(t, cfg.Rules, 2)
+	assert.Equal(t, 5*time.Minute, cfg.Rules[0].For)
+	assert.Equal(t, map[string]string{"severity": "warning"}, cfg.Rules[0].Labels)
+	assert.Equal(t, time.Duration(0), cfg.Rules[1].For)
+	assert.Len(t, cfg.Webhooks, 1)
+	assert.Equal(t, 30*time.Second, cfg.GroupWait)
+
+	for _, data := range []string{
+		`{"rules": [{"name": "a", "expr": "x > 1"}, {"name": "a", "expr": "y > 1"}]}`,
+		`{"rules": [{"name": "a", "expr": "x > 1", "for": "soon"}]}`,
+		`{"webhooks": [{"groupBy": ["rule"]}]}`,
+		`{"webhooks": [{"url": "http://localhost/hook", "template": "{{ .Status"}]}`,
+		`{"groupWait": "-1s"}`,
+	} {
+		_, err = ParseConfig([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
@@ -0,0 +1,307 @@
+package alerting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Notifier receives firing and resolved alerts. It is called by the engine
+// during evaluation and must not block.
+type Notifier interface {
+	Notify(alert Alert)
+}
+
+const (
+	defaultRetries = 3
+	defaultBackoff = time.Second
+	defaultTimeout = 10 * time.Second
+	maxBackoff     = time.Minute
+)
+
+// WebhookConfig is a webhook as written in the rules file:
+//
+//	{"url": "http://alerts.example/hook", "groupBy": ["rule", "host"],
+//	 "template": "{\"text\": {{ printf \"%s is %s\" .GroupKey .Status | json }}}"}
+//
+// Without a template the body is the JSON WebhookPayload.
+//
+//easyjson:json
+type WebhookConfig struct {
+	URL      string            `json:"url"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	GroupBy  []string          `json:"groupBy,omitempty"`
+	Template string            `json:"template,omitempty"`
+	Retries  *int              `json:"retries,omitempty"`
+	Backoff  string            `json:"backoff,omitempty"`
+	Timeout  string            `json:"timeout,omitempty"`
+}
+
+// WebhookPayload is a batch of alerts of one group.
+//
+//easyjson:json
+type WebhookPayload struct {
+	Status      string            `json:"status"`
+	GroupKey    string            `json:"groupKey"`
+	GroupLabels map[string]string `json:"groupLabels"`
+	Alerts      AlertList         `json:"alerts"`
+}
+
+// Webhook delivers payloads to a single URL, retrying network errors and 5xx
+// responses with an exponential backoff.
+type Webhook struct {
+	url      string
+	headers  map[string]string
+	groupBy  []string
+	template *template.Template
+	retries  int
+	backoff  time.Duration
+	client   *http.Client
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join": strings.Join,
+}
+
+func NewWebhook(cfg WebhookConfig) (*Webhook, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("webhook without a url")
+	}
+
+	w := &Webhook{
+		url:     cfg.URL,
+		headers: cfg.Headers,
+		groupBy: cfg.GroupBy,
+		retries: defaultRetries,
+		backoff: defaultBackoff,
+		client:  &http.Client{Timeout: defaultTimeout},
+	}
+	if cfg.Retries != nil {
+		if *cfg.Retries < 0 {
+			return nil, fmt.Errorf("webhook %s: negative retries", cfg.URL)
+		}
+		w.retries = *cfg.Retries
+	}
+
+	var err error
+	if cfg.Backoff != "" {
+		if w.backoff, err = time.ParseDuration(cfg.Backoff); err != nil {
+			return nil, fmt.Errorf("webhook %s: invalid backoff: %w", cfg.URL, err)
+		}
+		if w.backoff <= 0 {
+			return nil, fmt.Errorf("webhook %s: backoff must be greater than 0", cfg.URL)
+		}
+	}
+	if cfg.Timeout != "" {
+		if w.client.Timeout, err = time.ParseDuration(cfg.Timeout); err != nil {
+			return nil, fmt.Errorf("webhook %s: invalid timeout: %w", cfg.URL, err)
+		}
+		// a zero client timeout would mean none at all
+		if w.client.Timeout <= 0 {
+			return nil, fmt.Errorf("webhook %s: timeout must be greater than 0", cfg.URL)
+		}
+	}
+	if cfg.Template != "" {
+		if w.template, err = template.New(cfg.URL).Funcs(templateFuncs).Parse(cfg.Template); err != nil {
+			return nil, fmt.Errorf("webhook %s: %w", cfg.URL, err)
+		}
+	}
+	return w, nil
+}
+
+// groupLabels returns the values of the groupBy labels of an alert.
+func (w *Webhook) groupLabels(alert Alert) map[string]string {
+	labels := alertLabels(alert)
+	group := make(map[string]string, len(w.groupBy))
+	for _, name := range w.groupBy {
+		group[name] = labels[name]
+	}
+	return group
+}
+
+func (w *Webhook) body(payload WebhookPayload) ([]byte, error) {
+	if w.template == nil {
+		return payload.MarshalJSON()
+	}
+	var buf bytes.Buffer
+	if err := w.template.Execute(&buf, payload); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Send delivers a payload, retrying failed attempts.
+func (w *Webhook) Send(payload WebhookPayload) error {
+	body, err := w.body(payload)
+	if err != nil {
+		return err
+	}
+
+	backoff := w.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= w.retries {
+			return err
+		}
+		log.Error().Err(err).Msgf("webhook %s failed, retrying in %s", w.url, backoff)
+		time.Sleep(backoff)
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
+
+// post sends the body once and reports whether a failure is worth retrying.
+func (w *Webhook) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	if err := resp.Body.Close(); err != nil {
+		log.Error().Err(err).Msg("failed to close webhook response body")
+	}
+
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook %s: %s", w.url, resp.Status)
+	default:
+		return false, fmt.Errorf("webhook %s: %s", w.url, resp.Status)
+	}
+}
+
+// WebhookNotifier groups the alerts notified between two flushes by the
+// groupBy labels of every webhook and sends one payload per group. An alert
+// notified twice before a flush is sent once with its latest state.
+type WebhookNotifier struct {
+	webhooks []*Webhook
+
+	mu     sync.Mutex
+	queued []map[string]*group // per webhook, by group key
+}
+
+type group struct {
+	labels map[string]string
+	alerts map[alertKey]Alert
+}
+
+func NewWebhookNotifier(webhooks []*Webhook) *WebhookNotifier {
+	n := &WebhookNotifier{webhooks: webhooks}
+	n.reset()
+	return n
+}
+
+func (n *WebhookNotifier) reset() {
+	n.queued = make([]map[string]*group, len(n.webhooks))
+	for i := range n.queued {
+		n.queued[i] = make(map[string]*group)
+	}
+}
+
+func (n *WebhookNotifier) Notify(alert Alert) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for i, w := range n.webhooks {
+		labels := w.groupLabels(alert)
+		key := groupKey(labels)
+		g, ok := n.queued[i][key]
+		if !ok {
+			g = &group{labels: labels, alerts: make(map[alertKey]Alert)}
+			n.queued[i][key] = g
+		}
+		g.alerts[alertKey{rule: alert.Rule, series: alert.Series}] = alert
+	}
+}
+
+// Flush sends the queued groups and waits for the deliveries.
+func (n *WebhookNotifier) Flush() {
+	n.mu.Lock()
+	queued := n.queued
+	n.reset()
+	n.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i, groups := range queued {
+		for key, g := range groups {
+			payload := g.payload(key)
+			wg.Add(1)
+			go func(w *Webhook) {
+				defer wg.Done()
+				if err := w.Send(payload); err != nil {
+					log.Error().Err(err).Msgf("failed to deliver %d alerts of group %q", len(payload.Alerts), key)
+				}
+			}(n.webhooks[i])
+		}
+	}
+	wg.Wait()
+}
+
+// RunFlushRoutine flushes the queued alerts every interval in the
+// background.
+func (n *WebhookNotifier) RunFlushRoutine(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			n.Flush()
+		}
+	}()
+}
+
+// payload orders the alerts by rule and series. The status is firing if any
+// alert of the group fires.
+func (g *group) payload(key string) WebhookPayload {
+	payload := WebhookPayload{Status: StateResolved, GroupKey: key, GroupLabels: g.labels}
+	for _, alert := range g.alerts {
+		if alert.State == StateFiring {
+			payload.Status = StateFiring
+		}
+		payload.Alerts = append(payload.Alerts, alert)
+	}
+	sort.Slice(payload.Alerts, func(i, j int) bool {
+		if payload.Alerts[i].Rule != payload.Alerts[j].Rule {
+			return payload.Alerts[i].Rule < payload.Alerts[j].Rule
+		}
+		return payload.Alerts[i].Series < payload.Alerts[j].Series
+	})
+	return payload
+}
+
+// groupKey renders group labels as `{a="1",b="2"}`.
+func groupKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%q", name, labels[name])
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
@@ -0,0 +1,314 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package alerting
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonAba0bf1bDecodeGithubComAPalonskaaMetricsServerInternalAlerting(in *jlexer.Lexer, out *WebhookPayload) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "status":
+			out.Status = string(in.String())
+		case "groupKey":
+			out.GroupKey = string(in.String())
+		case "groupLabels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.GroupLabels = make(map[string]string)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v1 string
+					v1 = string(in.String())
+					(out.GroupLabels)[key] = v1
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "alerts":
+			(out.Alerts).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonAba0bf1bEncodeGithubComAPalonskaaMetricsServerInternalAlerting(out *jwriter.Writer, in WebhookPayload) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Status))
+	}
+	{
+		const prefix string = ",\"groupKey\":"
+		out.RawString(prefix)
+		out.String(string(in.GroupKey))
+	}
+	{
+		const prefix string = ",\"groupLabels\":"
+		out.RawString(prefix)
+		if in.GroupLabels == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v2First := true
+			for v2Name, v2Value := range in.GroupLabels {
+				if v2First {
+					v2First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v2Name))
+				out.RawByte(':')
+				out.String(string(v2Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"alerts\":"
+		out.RawString(prefix)
+		(in.Alerts).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v WebhookPayload) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonAba0bf1bEncodeGithubComAPalonskaaMetricsServerInternalAlerting(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v WebhookPayload) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonAba0bf1bEncodeGithubComAPalonskaaMetricsServerInternalAlerting(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *WebhookPayload) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonAba0bf1bDecodeGithubComAPalonskaaMetricsServerInternalAlerting(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *WebhookPayload) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonAba0bf1bDecodeGithubComAPalonskaaMetricsServerInternalAlerting(l, v)
+}
+func easyjsonAba0bf1bDecodeGithubComAPalonskaaMetricsServerInternalAlerting1(in *jlexer.Lexer, out *WebhookConfig) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "url":
+			out.URL = string(in.String())
+		case "headers":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Headers = make(map[string]string)
+				} else {
+					out.Headers = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v3 string
+					v3 = string(in.String())
+					(out.Headers)[key] = v3
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "groupBy":
+			if in.IsNull() {
+				in.Skip()
+				out.GroupBy = nil
+			} else {
+				in.Delim('[')
+				if out.GroupBy == nil {
+					if !in.IsDelim(']') {
+						out.GroupBy = make([]string, 0, 4)
+					} else {
+						out.GroupBy = []string{}
+					}
+				} else {
+					out.GroupBy = (out.GroupBy)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 string
+					v4 = string(in.String())
+					out.GroupBy = append(out.GroupBy, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "template":
+			out.Template = string(in.String())
+		case "retries":
+			if in.IsNull() {
+				in.Skip()
+				out.Retries = nil
+			} else {
+				if out.Retries == nil {
+					out.Retries = new(int)
+				}
+				*out.Retries = int(in.Int())
+			}
+		case "backoff":
+			out.Backoff = string(in.String())
+		case "timeout":
+			out.Timeout = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonAba0bf1bEncodeGithubComAPalonskaaMetricsServerInternalAlerting1(out *jwriter.Writer, in WebhookConfig) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"url\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.URL))
+	}
+	if len(in.Headers) != 0 {
+		const prefix string = ",\"headers\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v5First := true
+			for v5Name, v5Value := range in.Headers {
+				if v5First {
+					v5First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v5Name))
+				out.RawByte(':')
+				out.String(string(v5Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.GroupBy) != 0 {
+		const prefix string = ",\"groupBy\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v6, v7 := range in.GroupBy {
+				if v6 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v7))
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.Template != "" {
+		const prefix string = ",\"template\":"
+		out.RawString(prefix)
+		out.String(string(in.Template))
+	}
+	if in.Retries != nil {
+		const prefix string = ",\"retries\":"
+		out.RawString(prefix)
+		out.Int(int(*in.Retries))
+	}
+	if in.Backoff != "" {
+		const prefix string = ",\"backoff\":"
+		out.RawString(prefix)
+		out.String(string(in.Backoff))
+	}
+	if in.Timeout != "" {
+		const prefix string = ",\"timeout\":"
+		out.RawString(prefix)
+		out.String(string(in.Timeout))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v WebhookConfig) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonAba0bf1bEncodeGithubComAPalonskaaMetricsServerInternalAlerting1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v WebhookConfig) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonAba0bf1bEncodeGithubComAPalonskaaMetricsServerInternalAlerting1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *WebhookConfig) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonAba0bf1bDecodeGithubComAPalonskaaMetricsServerInternalAlerting1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *WebhookConfig) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonAba0bf1bDecodeGithubComAPalonskaaMetricsServerInternalAlerting1(l, v)
+}
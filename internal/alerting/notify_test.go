@@ -0,0 +1,209 @@
+package alerting
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+// receiver records the bodies of webhook requests. The first failures
+// requests are answered with status instead.
+type receiver struct {
+	mu       sync.Mutex
+	bodies   []string
+	attempts int
+	failures int
+	status   int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.attempts++
+	if r.attempts <= r.failures {
+		w.WriteHeader(r.status)
+		return
+	}
+	body, _ := io.ReadAll(req.Body)
+	r.bodies = append(r.bodies, string(body))
+}
+
+func (r *receiver) payloads(t *testing.T) []WebhookPayload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payloads := make([]WebhookPayload, len(r.bodies))
+	for i, body := range r.bodies {
+		require.NoError(t, payloads[i].UnmarshalJSON([]byte(body)))
+	}
+	return payloads
+}
+
+func newTestWebhook(t *testing.T, url string, cfg WebhookConfig) *Webhook {
+	t.Helper()
+	cfg.URL = url
+	cfg.Backoff = "1ms"
+	w, err := NewWebhook(cfg)
+	require.NoError(t, err)
+	return w
+}
+
+func TestNewWebhook(t *testing.T) {
+	retries := -1
+	for name, cfg := range map[string]WebhookConfig{
+		"no url":           {},
+		"negative retries": {URL: "http://localhost", Retries: &retries},
+		"invalid backoff":  {URL: "http://localhost", Backoff: "soon"},
+		"zero backoff":     {URL: "http://localhost", Backoff: "0s"},
+		"negative backoff": {URL: "http://localhost", Backoff: "-1s"},
+		"zero timeout":     {URL: "http://localhost", Timeout: "0s"},
+		"negative timeout": {URL: "http://localhost", Timeout: "-1s"},
+	} {
+		_, err := NewWebhook(cfg)
+		assert.Error(t, err, name)
+	}
+
+	w, err := NewWebhook(WebhookConfig{URL: "http://localhost", Backoff: "2s", Timeout: "3s"})
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, w.backoff)
+	assert.Equal(t, 3*time.Second, w.client.Timeout)
+}
+
+func TestWebhookNotifier_Grouping(t *testing.T) {
+	recv := &receiver{}
+	srv := httptest.NewServer(recv)
+	defer srv.Close()
+
+	storage := memstorage.NewMetricsStorage()
+	storage.AddGauge(`Load{host="a"}`, 5)
+	storage.AddGauge(`Load{host="b"}`, 5)
+	storage.AddGauge(`Temp{host="a"}`, 90)
+
+	notifier := NewWebhookNotifier([]*Webhook{newTestWebhook(t, srv.URL, WebhookConfig{GroupBy: []string{"rule"}})})
+	engine := NewEngine(storage, []Rule{
+		mustRule(t, RuleConfig{Name: "HighLoad", Expr: "Load > 1"}),
+		mustRule(t, RuleConfig{Name: "Hot", Expr: "Temp > 80"}),
+	})
+	engine.SetNotifier(notifier)
+
+	now := time.Now()
+	engine.Eval(now)
+	engine.Eval(now.Add(time.Second)) // still firing, not notified again
+	notifier.Flush()
+
+	payloads := recv.payloads(t)
+	require.Len(t, payloads, 2)
+	byRule := map[string]WebhookPayload{}
+	for _, p := range payloads {
+		byRule[p.GroupLabels["rule"]] = p
+	}
+	assert.Equal(t, StateFiring, byRule["HighLoad"].Status)
+	assert.Len(t, byRule["HighLoad"].Alerts, 2)
+	assert.Len(t, byRule["Hot"].Alerts, 1)
+
+	storage.AddGauge(`Load{host="a"}`, 0)
+	engine.Eval(now.Add(2 * time.Second))
+	notifier.Flush()
+
+	payloads = recv.payloads(t)
+	require.Len(t, payloads, 3)
+	assert.Equal(t, StateResolved, payloads[2].Status)
+	require.Len(t, payloads[2].Alerts, 1)
+	assert.Equal(t, `Load{host="a"}`, payloads[2].Alerts[0].Series)
+}
+
+func TestWebhook_Send(t *testing.T) {
+	payload := WebhookPayload{
+		Status:      StateFiring,
+		GroupKey:    `{rule="Hot"}`,
+		GroupLabels: map[string]string{"rule": "Hot"},
+		Alerts:      AlertList{{Rule: "Hot", State: StateFiring, Series: "Temp", Value: 90}},
+	}
+
+	tests := []struct {
+		name     string
+		cfg      WebhookConfig
+		failures int
+		status   int
+		wantErr  bool
+		attempts int
+		body     string
+	}{
+		{
+			name:     "template",
+			cfg:      WebhookConfig{Template: `{"text": {{ printf "%s: %d %s" .GroupKey (len .Alerts) .Status | json }}}`},
+			attempts: 1,
+			body:     `{"text": "{rule=\"Hot\"}: 1 firing"}`,
+		},
+		{name: "retried 5xx", failures: 2, status: http.StatusServiceUnavailable, attempts: 3},
+		{name: "429 not retried", failures: 1, status: http.StatusTooManyRequests, wantErr: true, attempts: 1},
+		{name: "retries exhausted", failures: 4, status: http.StatusInternalServerError, wantErr: true, attempts: 4},
+		{name: "client error", failures: 1, status: http.StatusBadRequest, wantErr: true, attempts: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recv := &receiver{failures: test.failures, status: test.status}
+			srv := httptest.NewServer(recv)
+			defer srv.Close()
+
+			err := newTestWebhook(t, srv.URL, test.cfg).Send(payload)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.attempts, recv.attempts)
+			if test.body != "" {
+				require.Len(t, recv.bodies, 1)
+				assert.Equal(t, test.body, recv.bodies[0])
+			}
+		})
+	}
+}
+
+type notifications []Alert
+
+func (n *notifications) Notify(alert Alert) {
+	*n = append(*n, alert)
+}
+
+func TestEngine_Silences(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	storage.AddGauge(`Load{host="a"}`, 5)
+	storage.AddGauge(`Load{host="b"}`, 5)
+
+	var notified notifications
+	engine := NewEngine(storage, []Rule{mustRule(t, RuleConfig{Name: "HighLoad", Expr: "Load > 1"})})
+	engine.SetNotifier(&notified)
+
+	now := time.Now()
+	silence, err := engine.Silences().Add([]string{"rule=HighLoad", "host=a"}, now, now.Add(time.Minute), "maintenance")
+	require.NoError(t, err)
+	_, err = engine.Silences().Add(nil, now, now.Add(time.Minute), "")
+	assert.Error(t, err)
+
+	engine.Eval(now)
+	require.Len(t, notified, 1)
+	assert.Equal(t, `Load{host="b"}`, notified[0].Series)
+	alerts := engine.Alerts()
+	require.Len(t, alerts, 2)
+	assert.True(t, alerts[0].Silenced)
+	assert.False(t, alerts[1].Silenced)
+
+	// the silence expires
+	engine.Eval(now.Add(time.Minute))
+	require.Len(t, notified, 2)
+	assert.Equal(t, `Load{host="a"}`, notified[1].Series)
+	assert.Empty(t, engine.Silences().List(now.Add(time.Minute)))
+	assert.False(t, engine.Silences().Delete(silence.ID))
+}
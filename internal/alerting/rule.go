@@ -0,0 +1,134 @@
+package alerting
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// RuleConfig is a rule as written in the rules file:
+//
+//	{"rules": [
+//	  {"name": "HighHeap", "expr": "HeapAlloc > 1e9", "for": "5m",
+//	   "labels": {"severity": "warning"},
+//	   "annotations": {"summary": "heap is above 1GB"}}
+//	]}
+//
+//easyjson:json
+type RuleConfig struct {
+	Name        string            `json:"name"`
+	Expr        string            `json:"expr"`
+	For         string            `json:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// RulesFile holds the rules and the webhooks notified of their alerts.
+//
+//easyjson:json
+type RulesFile struct {
+	Rules     []RuleConfig    `json:"rules"`
+	Webhooks  []WebhookConfig `json:"webhooks,omitempty"`
+	GroupWait string          `json:"groupWait,omitempty"`
+}
+
+// Rule is a parsed RuleConfig. An alert of the rule is raised for every
+// series matching the condition and fires once it matched for For.
+type Rule struct {
+	Name        string
+	Condition   Condition
+	For         time.Duration
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+func ParseRule(cfg RuleConfig) (Rule, error) {
+	if cfg.Name == "" {
+		return Rule{}, fmt.Errorf("rule without a name")
+	}
+
+	cond, err := ParseCondition(cfg.Expr)
+	if err != nil {
+		return Rule{}, fmt.Errorf("rule %s: %w", cfg.Name, err)
+	}
+
+	var pending time.Duration
+	if cfg.For != "" {
+		if pending, err = time.ParseDuration(cfg.For); err != nil {
+			return Rule{}, fmt.Errorf("rule %s: invalid for: %w", cfg.Name, err)
+		}
+		if pending < 0 {
+			return Rule{}, fmt.Errorf("rule %s: negative for %s", cfg.Name, cfg.For)
+		}
+	}
+
+	return Rule{
+		Name:        cfg.Name,
+		Condition:   cond,
+		For:         pending,
+		Labels:      cfg.Labels,
+		Annotations: cfg.Annotations,
+	}, nil
+}
+
+// Config is a parsed rules file.
+type Config struct {
+	Rules    []Rule
+	Webhooks []*Webhook
+
+	// GroupWait is the interval of webhook deliveries, alerts changing
+	// state within it are sent together.
+	GroupWait time.Duration
+}
+
+const defaultGroupWait = 10 * time.Second
+
+// ParseConfig parses a rules file. Rule names must be unique.
+func ParseConfig(data []byte) (Config, error) {
+	var file RulesFile
+	if err := file.UnmarshalJSON(data); err != nil {
+		return Config{}, err
+	}
+
+	cfg := Config{GroupWait: defaultGroupWait}
+	names := make(map[string]bool)
+	for _, ruleCfg := range file.Rules {
+		rule, err := ParseRule(ruleCfg)
+		if err != nil {
+			return Config{}, err
+		}
+		if names[rule.Name] {
+			return Config{}, fmt.Errorf("duplicate rule %s", rule.Name)
+		}
+		names[rule.Name] = true
+		cfg.Rules = append(cfg.Rules, rule)
+	}
+
+	for _, webhookCfg := range file.Webhooks {
+		webhook, err := NewWebhook(webhookCfg)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.Webhooks = append(cfg.Webhooks, webhook)
+	}
+
+	if file.GroupWait != "" {
+		groupWait, err := time.ParseDuration(file.GroupWait)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid groupWait: %w", err)
+		}
+		if groupWait <= 0 {
+			return Config{}, fmt.Errorf("groupWait must be positive: %s", file.GroupWait)
+		}
+		cfg.GroupWait = groupWait
+	}
+	return cfg, nil
+}
+
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	return ParseConfig(data)
+}
@@ -0,0 +1,319 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package alerting
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonF1a92ff2DecodeGithubComAPalonskaaMetricsServerInternalAlerting(in *jlexer.Lexer, out *RulesFile) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "rules":
+			if in.IsNull() {
+				in.Skip()
+				out.Rules = nil
+			} else {
+				in.Delim('[')
+				if out.Rules == nil {
+					if !in.IsDelim(']') {
+						out.Rules = make([]RuleConfig, 0, 1)
+					} else {
+						out.Rules = []RuleConfig{}
+					}
+				} else {
+					out.Rules = (out.Rules)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 RuleConfig
+					(v1).UnmarshalEasyJSON(in)
+					out.Rules = append(out.Rules, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "webhooks":
+			if in.IsNull() {
+				in.Skip()
+				out.Webhooks = nil
+			} else {
+				in.Delim('[')
+				if out.Webhooks == nil {
+					if !in.IsDelim(']') {
+						out.Webhooks = make([]WebhookConfig, 0, 0)
+					} else {
+						out.Webhooks = []WebhookConfig{}
+					}
+				} else {
+					out.Webhooks = (out.Webhooks)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v2 WebhookConfig
+					(v2).UnmarshalEasyJSON(in)
+					out.Webhooks = append(out.Webhooks, v2)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "groupWait":
+			out.GroupWait = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonF1a92ff2EncodeGithubComAPalonskaaMetricsServerInternalAlerting(out *jwriter.Writer, in RulesFile) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"rules\":"
+		out.RawString(prefix[1:])
+		if in.Rules == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v3, v4 := range in.Rules {
+				if v3 > 0 {
+					out.RawByte(',')
+				}
+				(v4).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	if len(in.Webhooks) != 0 {
+		const prefix string = ",\"webhooks\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v5, v6 := range in.Webhooks {
+				if v5 > 0 {
+					out.RawByte(',')
+				}
+				(v6).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.GroupWait != "" {
+		const prefix string = ",\"groupWait\":"
+		out.RawString(prefix)
+		out.String(string(in.GroupWait))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RulesFile) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonF1a92ff2EncodeGithubComAPalonskaaMetricsServerInternalAlerting(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RulesFile) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonF1a92ff2EncodeGithubComAPalonskaaMetricsServerInternalAlerting(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RulesFile) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonF1a92ff2DecodeGithubComAPalonskaaMetricsServerInternalAlerting(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RulesFile) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonF1a92ff2DecodeGithubComAPalonskaaMetricsServerInternalAlerting(l, v)
+}
+func easyjsonF1a92ff2DecodeGithubComAPalonskaaMetricsServerInternalAlerting1(in *jlexer.Lexer, out *RuleConfig) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "name":
+			out.Name = string(in.String())
+		case "expr":
+			out.Expr = string(in.String())
+		case "for":
+			out.For = string(in.String())
+		case "labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Labels = make(map[string]string)
+				} else {
+					out.Labels = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v7 string
+					v7 = string(in.String())
+					(out.Labels)[key] = v7
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "annotations":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Annotations = make(map[string]string)
+				} else {
+					out.Annotations = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v8 string
+					v8 = string(in.String())
+					(out.Annotations)[key] = v8
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonF1a92ff2EncodeGithubComAPalonskaaMetricsServerInternalAlerting1(out *jwriter.Writer, in RuleConfig) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"expr\":"
+		out.RawString(prefix)
+		out.String(string(in.Expr))
+	}
+	if in.For != "" {
+		const prefix string = ",\"for\":"
+		out.RawString(prefix)
+		out.String(string(in.For))
+	}
+	if len(in.Labels) != 0 {
+		const prefix string = ",\"labels\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v9First := true
+			for v9Name, v9Value := range in.Labels {
+				if v9First {
+					v9First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v9Name))
+				out.RawByte(':')
+				out.String(string(v9Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.Annotations) != 0 {
+		const prefix string = ",\"annotations\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v10First := true
+			for v10Name, v10Value := range in.Annotations {
+				if v10First {
+					v10First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v10Name))
+				out.RawByte(':')
+				out.String(string(v10Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RuleConfig) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonF1a92ff2EncodeGithubComAPalonskaaMetricsServerInternalAlerting1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RuleConfig) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonF1a92ff2EncodeGithubComAPalonskaaMetricsServerInternalAlerting1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RuleConfig) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonF1a92ff2DecodeGithubComAPalonskaaMetricsServerInternalAlerting1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RuleConfig) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonF1a92ff2DecodeGithubComAPalonskaaMetricsServerInternalAlerting1(l, v)
+}
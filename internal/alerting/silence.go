@@ -0,0 +1,142 @@
+package alerting
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+)
+
+// Silence mutes the notifications of alerts matching all of its matchers
+// between StartsAt and EndsAt. Matchers use the `name=value` syntax of the
+// listing label filter, the rule name is matched as the "rule" label.
+//
+//easyjson:json
+type Silence struct {
+	ID       string    `json:"id"`
+	Matchers []string  `json:"matchers"`
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+	Comment  string    `json:"comment,omitempty"`
+
+	matchers []metrics.LabelMatcher
+}
+
+//easyjson:json
+type SilenceList []Silence
+
+type Silences struct {
+	mu       sync.Mutex
+	silences map[string]Silence
+}
+
+func NewSilences() *Silences {
+	return &Silences{silences: make(map[string]Silence)}
+}
+
+func (s *Silences) Add(matchers []string, startsAt, endsAt time.Time, comment string) (Silence, error) {
+	if len(matchers) == 0 {
+		return Silence{}, fmt.Errorf("silence without matchers")
+	}
+	if !endsAt.After(startsAt) {
+		return Silence{}, fmt.Errorf("silence must end after it starts")
+	}
+
+	silence := Silence{
+		Matchers: matchers,
+		StartsAt: startsAt,
+		EndsAt:   endsAt,
+		Comment:  comment,
+	}
+	for _, m := range matchers {
+		matcher, err := metrics.ParseLabelMatcher(m)
+		if err != nil {
+			return Silence{}, err
+		}
+		silence.matchers = append(silence.matchers, matcher)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return Silence{}, err
+	}
+	silence.ID = hex.EncodeToString(id)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.silences[silence.ID] = silence
+	return silence, nil
+}
+
+func (s *Silences) Delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.silences[id]; !ok {
+		return false
+	}
+	delete(s.silences, id)
+	return true
+}
+
+// List returns the active and future silences ordered by end time. Expired
+// silences are dropped.
+func (s *Silences) List(now time.Time) SilenceList {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make(SilenceList, 0, len(s.silences))
+	for id, silence := range s.silences {
+		if !silence.EndsAt.After(now) {
+			delete(s.silences, id)
+			continue
+		}
+		list = append(list, silence)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].EndsAt.Equal(list[j].EndsAt) {
+			return list[i].EndsAt.Before(list[j].EndsAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// Silenced reports whether an active silence matches the alert.
+func (s *Silences) Silenced(alert Alert, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	labels := alertLabels(alert)
+	for _, silence := range s.silences {
+		if now.Before(silence.StartsAt) || !now.Before(silence.EndsAt) {
+			continue
+		}
+		matches := true
+		for _, matcher := range silence.matchers {
+			if !matcher.Matches(labels) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// alertLabels returns the labels of an alert together with the rule name as
+// the "rule" label, unless the alert has a label of that name.
+func alertLabels(alert Alert) map[string]string {
+	labels := make(map[string]string, len(alert.Labels)+1)
+	labels["rule"] = alert.Rule
+	for k, v := range alert.Labels {
+		labels[k] = v
+	}
+	return labels
+}
@@ -0,0 +1,215 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package alerting
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson5b49b24fDecodeGithubComAPalonskaaMetricsServerInternalAlerting(in *jlexer.Lexer, out *SilenceList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(SilenceList, 0, 0)
+			} else {
+				*out = SilenceList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 Silence
+			(v1).UnmarshalEasyJSON(in)
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson5b49b24fEncodeGithubComAPalonskaaMetricsServerInternalAlerting(out *jwriter.Writer, in SilenceList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			(v3).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v SilenceList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson5b49b24fEncodeGithubComAPalonskaaMetricsServerInternalAlerting(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v SilenceList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson5b49b24fEncodeGithubComAPalonskaaMetricsServerInternalAlerting(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *SilenceList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson5b49b24fDecodeGithubComAPalonskaaMetricsServerInternalAlerting(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *SilenceList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson5b49b24fDecodeGithubComAPalonskaaMetricsServerInternalAlerting(l, v)
+}
+func easyjson5b49b24fDecodeGithubComAPalonskaaMetricsServerInternalAlerting1(in *jlexer.Lexer, out *Silence) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = string(in.String())
+		case "matchers":
+			if in.IsNull() {
+				in.Skip()
+				out.Matchers = nil
+			} else {
+				in.Delim('[')
+				if out.Matchers == nil {
+					if !in.IsDelim(']') {
+						out.Matchers = make([]string, 0, 4)
+					} else {
+						out.Matchers = []string{}
+					}
+				} else {
+					out.Matchers = (out.Matchers)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 string
+					v4 = string(in.String())
+					out.Matchers = append(out.Matchers, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "startsAt":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.StartsAt).UnmarshalJSON(data))
+			}
+		case "endsAt":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.EndsAt).UnmarshalJSON(data))
+			}
+		case "comment":
+			out.Comment = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson5b49b24fEncodeGithubComAPalonskaaMetricsServerInternalAlerting1(out *jwriter.Writer, in Silence) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ID))
+	}
+	{
+		const prefix string = ",\"matchers\":"
+		out.RawString(prefix)
+		if in.Matchers == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v5, v6 := range in.Matchers {
+				if v5 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v6))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"startsAt\":"
+		out.RawString(prefix)
+		out.Raw((in.StartsAt).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"endsAt\":"
+		out.RawString(prefix)
+		out.Raw((in.EndsAt).MarshalJSON())
+	}
+	if in.Comment != "" {
+		const prefix string = ",\"comment\":"
+		out.RawString(prefix)
+		out.String(string(in.Comment))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Silence) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson5b49b24fEncodeGithubComAPalonskaaMetricsServerInternalAlerting1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Silence) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson5b49b24fEncodeGithubComAPalonskaaMetricsServerInternalAlerting1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Silence) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson5b49b24fDecodeGithubComAPalonskaaMetricsServerInternalAlerting1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Silence) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson5b49b24fDecodeGithubComAPalonskaaMetricsServerInternalAlerting1(l, v)
+}
@@ -0,0 +1,248 @@
+package graphite
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	"github.com/a-palonskaa/metrics-server/internal/quota"
+)
+
+const maxLineSize = 65535
+
+// TimestampTolerance is how far the timestamp of a line may be from the time
+// the receiver reads it. Points are stored at the time they are read, so lines
+// with timestamps further away are rejected rather than stored at the wrong
+// time.
+var TimestampTolerance = time.Minute
+
+// Point is a single parsed plaintext line `path value [timestamp]`.
+type Point struct {
+	Path  string
+	Value float64
+	Time  time.Time // zero if the line has no timestamp or it is -1
+}
+
+func ParseLine(line string) (Point, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || len(fields) > 3 {
+		return Point{}, fmt.Errorf("expected `path value [timestamp]`, got %q", line)
+	}
+
+	val, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || math.IsNaN(val) || math.IsInf(val, 0) {
+		return Point{}, fmt.Errorf("invalid value in %q", line)
+	}
+
+	p := Point{Path: fields[0], Value: val}
+	if len(fields) == 3 && fields[2] != "-1" {
+		ts, err := strconv.ParseFloat(fields[2], 64)
+		if err != nil {
+			return Point{}, fmt.Errorf("invalid timestamp in %q", line)
+		}
+		p.Time = time.Unix(0, int64(ts*float64(time.Second)))
+	}
+	return p, nil
+}
+
+// Template maps the segments of a dotted path onto a metric name and labels.
+// It is written as `[filter ]template`, e.g. `servers.* .host.measurement*`:
+//
+//   - the filter selects paths by their leading segments, "*" matches any
+//     segment, a template without a filter matches every path;
+//   - "measurement" segments are joined with "." into the metric name,
+//     "measurement*" takes all remaining segments;
+//   - any other name turns the segment into a label, an empty segment or "_"
+//     skips it.
+type Template struct {
+	filter []string
+	parts  []string
+}
+
+func ParseTemplate(s string) (Template, error) {
+	fields := strings.Fields(s)
+	var t Template
+	switch len(fields) {
+	case 1:
+		t.parts = strings.Split(fields[0], ".")
+	case 2:
+		t.filter = strings.Split(fields[0], ".")
+		t.parts = strings.Split(fields[1], ".")
+	default:
+		return Template{}, fmt.Errorf("expected `[filter ]template`, got %q", s)
+	}
+
+	hasMeasurement := false
+	for i, part := range t.parts {
+		if part == "measurement*" && i != len(t.parts)-1 {
+			return Template{}, fmt.Errorf("measurement* must be the last segment of %q", s)
+		}
+		hasMeasurement = hasMeasurement || part == "measurement" || part == "measurement*"
+	}
+	if !hasMeasurement {
+		return Template{}, fmt.Errorf("template %q has no measurement segment", s)
+	}
+	return t, nil
+}
+
+func (t Template) Matches(segments []string) bool {
+	if len(segments) < len(t.filter) {
+		return false
+	}
+	for i, f := range t.filter {
+		if f != "*" && f != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the metric name and labels of the path segments.
+func (t Template) Apply(segments []string) (string, map[string]string) {
+	var name []string
+	labels := make(map[string]string)
+	for i, part := range t.parts {
+		if i >= len(segments) {
+			break
+		}
+		switch part {
+		case "", "_":
+		case "measurement":
+			name = append(name, segments[i])
+		case "measurement*":
+			name = append(name, segments[i:]...)
+		default:
+			labels[part] = segments[i]
+		}
+	}
+	return strings.Join(name, "."), labels
+}
+
+// Receiver stores Graphite points as gauges.
+type Receiver struct {
+	storage   *memstorage.MetricsStorage
+	templates []Template
+	// quota limits the points of every client host, nil if no limit is set
+	quota *quota.Limiter
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewReceiver sorts templates so that those with longer filters are tried
+// first, otherwise keeping the given order.
+func NewReceiver(storage *memstorage.MetricsStorage, templates []Template) *Receiver {
+	sorted := make([]Template, len(templates))
+	copy(sorted, templates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].filter) > len(sorted[j].filter)
+	})
+	return &Receiver{storage: storage, templates: sorted, now: time.Now}
+}
+
+// SeriesName converts a path with the first matching template. Paths no
+// template matches are stored under the full path.
+func (r *Receiver) SeriesName(path string) string {
+	segments := strings.Split(path, ".")
+	for _, t := range r.templates {
+		if t.Matches(segments) {
+			name, labels := t.Apply(segments)
+			if name != "" {
+				return metrics.SeriesName(name, labels)
+			}
+		}
+	}
+	return path
+}
+
+// SetQuota limits the points of every client host like the updates of an
+// agent.
+func (r *Receiver) SetQuota(limiter *quota.Limiter) {
+	r.quota = limiter
+}
+
+// HandleLine stores a line sent by agent, the error tells why it was not
+// stored. A line may carry a timestamp only if it is within
+// TimestampTolerance of the time it is read.
+func (r *Receiver) HandleLine(agent string, line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil
+	}
+
+	p, err := ParseLine(line)
+	if err != nil {
+		return err
+	}
+	if !p.Time.IsZero() {
+		if skew := r.now().Sub(p.Time).Abs(); skew > TimestampTolerance {
+			return fmt.Errorf("timestamp %s is %s away from the server time, only points within %s are accepted",
+				p.Time.UTC().Format(time.RFC3339), skew.Round(time.Second), TimestampTolerance)
+		}
+	}
+	series := r.SeriesName(p.Path)
+	if r.quota != nil {
+		if err := r.quota.Admit(agent, metrics.GaugeName, series); err != nil {
+			return err
+		}
+	}
+	return r.storage.AddGauge(series, metrics.Gauge(p.Value))
+}
+
+// ServeTCP accepts connections from ln until it is closed. Every connection
+// carries newline separated lines.
+func (r *Receiver) ServeTCP(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go r.handleConn(conn)
+	}
+}
+
+func (r *Receiver) handleConn(conn net.Conn) {
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Error().Err(err).Msg("failed to close graphite connection")
+		}
+	}()
+
+	agent := quota.Host(conn.RemoteAddr())
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineSize)
+	for scanner.Scan() {
+		if err := r.HandleLine(agent, scanner.Text()); err != nil {
+			log.Error().Err(err).Str("agent", agent).Msg("graphite line rejected")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Error().Err(err).Msg("failed to read graphite connection")
+	}
+}
+
+// ListenAndServe starts the TCP listener in a background goroutine.
+func (r *Receiver) ListenAndServe(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := r.ServeTCP(ln); err != nil {
+			log.Error().Err(err).Msg("graphite listener stopped")
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,143 @@
+package graphite
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	"github.com/a-palonskaa/metrics-server/internal/quota"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    Point
+		wantErr bool
+	}{
+		{
+			name: "with timestamp",
+			line: "servers.web01.cpu 0.5 1700000000",
+			want: Point{Path: "servers.web01.cpu", Value: 0.5, Time: time.Unix(1700000000, 0)},
+		},
+		{
+			name: "without timestamp",
+			line: "load 3",
+			want: Point{Path: "load", Value: 3},
+		},
+		{
+			name: "timestamp -1",
+			line: "load 3 -1",
+			want: Point{Path: "load", Value: 3},
+		},
+		{name: "missing value", line: "load", wantErr: true},
+		{name: "invalid value", line: "load abc 1", wantErr: true},
+		{name: "nan value", line: "load nan 1", wantErr: true},
+		{name: "invalid timestamp", line: "load 1 now", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLine(tt.line)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want.Path, got.Path)
+			assert.Equal(t, tt.want.Value, got.Value)
+			assert.True(t, tt.want.Time.Equal(got.Time))
+		})
+	}
+}
+
+func TestParseTemplate(t *testing.T) {
+	for _, s := range []string{"", "host.cpu", "a b c", "measurement*.host", "host.measurements"} {
+		_, err := ParseTemplate(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestReceiver_SeriesName(t *testing.T) {
+	var templates []Template
+	for _, s := range []string{
+		"measurement*",
+		"servers.* .host.measurement*",
+		"servers.*.disk .host.measurement.device.measurement",
+		"stats.*.* _.env.host.measurement",
+	} {
+		tmpl, err := ParseTemplate(s)
+		require.NoError(t, err)
+		templates = append(templates, tmpl)
+	}
+	r := NewReceiver(memstorage.NewMetricsStorage(), templates)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"servers.web01.cpu.load", `cpu.load{host="web01"}`},
+		{"servers.web01.disk.sda.used", `disk.used{device="sda",host="web01"}`},
+		{"stats.prod.db1.qps", `qps{env="prod",host="db1"}`},
+		{"stats.prod", "stats.prod"},
+		{"uptime", "uptime"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, r.SeriesName(tt.path), tt.path)
+	}
+}
+
+func TestReceiver_ServeTCP(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	tmpl, err := ParseTemplate("servers.* .host.measurement*")
+	require.NoError(t, err)
+	r := NewReceiver(storage, []Template{tmpl})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go r.ServeTCP(ln)
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("servers.web01.cpu 0.25 1700000000\ninvalid line here x\nservers.web01.cpu 0.75\n"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	assert.Eventually(t, func() bool {
+		val, ok := storage.GetGaugeValue(`cpu{host="web01"}`)
+		return ok && val == metrics.Gauge(0.75)
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestReceiver_Quota(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	r := NewReceiver(storage, nil)
+	r.SetQuota(quota.NewLimiter(quota.Limits{MaxSeries: 1}))
+
+	require.NoError(t, r.HandleLine("a", "quota.cpu 1"))
+	assert.ErrorIs(t, r.HandleLine("a", "quota.mem 1"), quota.ErrSeriesLimit)
+	require.NoError(t, r.HandleLine("b", "quota.mem 2"))
+	assert.Equal(t, []string{"quota.cpu", "quota.mem"}, storage.Series(metrics.GaugeName))
+}
+
+func TestReceiver_Timestamp(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	r := NewReceiver(storage, nil)
+	now := time.Unix(1_700_000_000, 0)
+	r.now = func() time.Time { return now }
+
+	require.NoError(t, r.HandleLine("a", "load 1 1700000030"))
+	require.NoError(t, r.HandleLine("a", "load 2"))
+	assert.ErrorContains(t, r.HandleLine("a", "load 3 1699990000"), "timestamp 2023-11-14T19:26:40Z is 2h46m40s away")
+	assert.Error(t, r.HandleLine("a", "load 4 1700000100"))
+
+	val, ok := storage.GetGaugeValue("load")
+	require.True(t, ok)
+	assert.Equal(t, metrics.Gauge(2), val)
+}
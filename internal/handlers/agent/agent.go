@@ -3,74 +3,141 @@ package agent
 import (
 	"bytes"
 	"compress/gzip"
+	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/proto"
 
 	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
 	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	pb "github.com/a-palonskaa/metrics-server/internal/proto"
 )
 
-func SendRequest(client *resty.Client, endpoint string, mType string, name string, val fmt.Stringer) error {
-	body := metrics.Metrics{
-		ID:    name,
-		MType: mType,
-	}
+const (
+	EncodingJSON     = "json"
+	EncodingProtobuf = "protobuf"
+)
+
+// Encoding selects the body format of /update/ requests.
+var Encoding = EncodingJSON
+
+// errConvert marks the metrics that can not be sent at all, they are not
+// retried.
+var errConvert = errors.New("failed to convert metric")
+
+// errRejected marks the metrics the server refused with a client error other
+// than 429, sending them again would be refused as well.
+var errRejected = errors.New("metric rejected")
 
-	switch mType {
-	case "gauge":
-		gVal, _ := val.(metrics.Gauge)
-		fVal := float64(gVal)
-		body.Value = &fVal
-	case "counter":
-		cVal, _ := val.(metrics.Counter)
-		iVal := int64(cVal)
-		body.Delta = &iVal
-	default:
+func SetEncoding(encoding string) {
+	Encoding = encoding
+}
+
+func SendRequest(client *resty.Client, endpoint string, mType string, name string, val fmt.Stringer) error {
+	body, err := metrics.FromValue(name, mType, val)
+	if err != nil {
 		log.Error().Msg("unknown type")
-		return fmt.Errorf("unknown type %s", mType)
+		return fmt.Errorf("%w: %w", errConvert, err)
 	}
 
-	jsonData, err := body.MarshalJSON()
+	contentType := "application/json"
+	var data []byte
+	if Encoding == EncodingProtobuf {
+		contentType = "application/x-protobuf"
+		data, err = proto.Marshal(pb.FromMetrics(body))
+	} else {
+		data, err = body.MarshalJSON()
+	}
 	if err != nil {
 		return err
 	}
 
 	var buf bytes.Buffer
 	gz := gzip.NewWriter(&buf)
-	if _, err := gz.Write(jsonData); err != nil {
+	if _, err := gz.Write(data); err != nil {
 		return err
 	}
 	if err := gz.Close(); err != nil {
 		return err
 	}
 
-	_, err = client.SetBaseURL("http://"+endpoint).R().
-		SetHeader("Content-Type", "application/json").
+	resp, err := client.SetBaseURL("http://"+endpoint).R().
+		SetHeader("Content-Type", contentType).
 		SetHeader("Accept-Encoding", "gzip").
 		SetHeader("Content-Encoding", "gzip").
-		SetBody(buf).
+		SetBody(buf.Bytes()).
 		Post("/update/")
 	if err != nil {
 		log.Error().Err(err).Msg("failed to send request")
 		return err
 	}
+	if resp.IsError() {
+		err := fmt.Errorf("server responded %s: %s", resp.Status(), bytes.TrimSpace(resp.Body()))
+		if code := resp.StatusCode(); code < http.StatusInternalServerError && code != http.StatusTooManyRequests {
+			return fmt.Errorf("%w: %w", errRejected, err)
+		}
+		return err
+	}
 	return nil
 }
 
 func MakeSendMetricsFunc(client *resty.Client, endpointAddr string, backoffScedule []time.Duration) func() {
+	type entry struct {
+		key   string
+		mType string
+		val   fmt.Stringer
+	}
+
 	return func() {
+		storeStats()
+
+		var queue []entry
 		memstorage.MS.Iterate(func(key string, mType string, val fmt.Stringer) {
-			for _, backoff := range backoffScedule {
-				err := SendRequest(client, endpointAddr, mType, key, val)
-				if err == nil {
-					break
-				}
-				log.Error().Msgf("error sending %s metric %s(%v): %v\n", mType, key, val, err)
-				time.Sleep(backoff)
-			}
+			queue = append(queue, entry{key: key, mType: mType, val: val})
 		})
+		setQueueDepth(len(queue))
+
+		ok := true
+		for i, e := range queue {
+			if !sendWithRetries(client, endpointAddr, backoffScedule, e.mType, e.key, e.val) {
+				ok = false
+			}
+			setQueueDepth(len(queue) - i - 1)
+		}
+		finishReport(ok)
+	}
+}
+
+func sendWithRetries(client *resty.Client, endpointAddr string, backoffScedule []time.Duration,
+	mType string, key string, val fmt.Stringer) bool {
+	for attempt, backoff := range backoffScedule {
+		err := SendRequest(client, endpointAddr, mType, key, val)
+		if errors.Is(err, errConvert) {
+			countCollectorError()
+			return false
+		}
+		countSend(attempt, err)
+		if errors.Is(err, errRejected) {
+			log.Error().Err(err).Msgf("%s metric %s is not retried", mType, key)
+			return false
+		}
+		if err == nil {
+			// the server merges histograms and summaries, so only
+			// observations made since the last report are sent
+			switch mType {
+			case metrics.HistogramName:
+				memstorage.MS.ResetHistogram(key)
+			case metrics.SummaryName:
+				memstorage.MS.ResetSummary(key)
+			}
+			return true
+		}
+		log.Error().Msgf("error sending %s metric %s(%v): %v\n", mType, key, val, err)
+		time.Sleep(backoff)
 	}
+	return false
 }
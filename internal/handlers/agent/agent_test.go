@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
 	"github.com/go-resty/resty/v2"
@@ -55,6 +57,28 @@ func TestSendRequest(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "success-case-histogram",
+			args: args{
+				client:   client,
+				endpoint: ts.URL[7:],
+				mType:    "histogram",
+				name:     "GCPauseNs",
+				val:      metrics.NewHistogram([]float64{1, 2}),
+			},
+			wantErr: false,
+		},
+		{
+			name: "success-case-summary",
+			args: args{
+				client:   client,
+				endpoint: ts.URL[7:],
+				mType:    "summary",
+				name:     "GCPauseQuantiles",
+				val:      metrics.NewSummary(metrics.DefaultSummaryAccuracy),
+			},
+			wantErr: false,
+		},
 		{
 			name: "invalid-type-case",
 			args: args{
@@ -88,3 +112,52 @@ func TestSendRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestSendRequestProtobuf(t *testing.T) {
+	SetEncoding(EncodingProtobuf)
+	defer SetEncoding(EncodingJSON)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/x-protobuf" {
+			t.Errorf("unexpected content type %s", r.Header.Get("Content-Type"))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	if err := SendRequest(resty.New(), ts.URL[7:], "counter", "PollCount", metrics.Counter(5)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSendWithRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		requests int64
+	}{
+		{name: "bad request", status: http.StatusBadRequest, requests: 1},
+		{name: "not found", status: http.StatusNotFound, requests: 1},
+		{name: "too many requests", status: http.StatusTooManyRequests, requests: 3},
+		{name: "server error", status: http.StatusServiceUnavailable, requests: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int64
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer ts.Close()
+
+			backoff := []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond}
+			if sendWithRetries(resty.New(), ts.URL[7:], backoff, "gauge", "RetryTest", metrics.Gauge(1)) {
+				t.Error("sendWithRetries() succeeded")
+			}
+			if got := requests.Load(); got != tt.requests {
+				t.Errorf("got %d requests, want %d", got, tt.requests)
+			}
+		})
+	}
+}
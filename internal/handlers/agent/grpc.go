@@ -0,0 +1,85 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	pb "github.com/a-palonskaa/metrics-server/internal/proto"
+)
+
+const grpcRequestTimeout = 10 * time.Second
+
+// MakeGRPCSendMetricsFunc reports all metrics in a single UpdateMetrics call
+// instead of one HTTP request per metric.
+func MakeGRPCSendMetricsFunc(client pb.MetricsClient, backoffScedule []time.Duration) func() {
+	return func() {
+		storeStats()
+
+		req := &pb.UpdateMetricsRequest{}
+		var sent []metrics.Metrics
+		memstorage.MS.Iterate(func(key string, mType string, val fmt.Stringer) {
+			metric, err := metrics.FromValue(key, mType, val)
+			if err != nil {
+				countCollectorError()
+				log.Error().Err(err).Msgf("failed to convert %s metric %s", mType, key)
+				return
+			}
+			req.Metrics = append(req.Metrics, pb.FromMetrics(metric))
+			sent = append(sent, metric)
+		})
+		if len(req.Metrics) == 0 {
+			return
+		}
+
+		setQueueDepth(len(req.Metrics))
+		for attempt, backoff := range backoffScedule {
+			ctx, cancel := context.WithTimeout(context.Background(), grpcRequestTimeout)
+			_, err := client.UpdateMetrics(ctx, req, grpc.UseCompressor(gzip.Name))
+			cancel()
+			countSend(attempt, err)
+			if err == nil {
+				resetSent(sent)
+				finishReport(true)
+				return
+			}
+			log.Error().Err(err).Msgf("error sending %d metrics", len(req.Metrics))
+			if !retryableGRPC(err) {
+				break
+			}
+			time.Sleep(backoff)
+		}
+		finishReport(false)
+	}
+}
+
+// retryableGRPC reports whether a failed UpdateMetrics call may succeed when
+// repeated, the server rejects invalid batches with client error codes.
+func retryableGRPC(err error) bool {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied,
+		codes.Unauthenticated, codes.FailedPrecondition, codes.OutOfRange, codes.Unimplemented:
+		return false
+	}
+	return true
+}
+
+// resetSent clears histograms and summaries the server has merged.
+func resetSent(sent []metrics.Metrics) {
+	for _, metric := range sent {
+		switch metric.MType {
+		case metrics.HistogramName:
+			memstorage.MS.ResetHistogram(metric.SeriesName())
+		case metrics.SummaryName:
+			memstorage.MS.ResetSummary(metric.SeriesName())
+		}
+	}
+}
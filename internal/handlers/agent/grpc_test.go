@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	pb "github.com/a-palonskaa/metrics-server/internal/proto"
+)
+
+type fakeMetricsClient struct {
+	pb.MetricsClient
+	fails    int
+	err      error
+	requests []*pb.UpdateMetricsRequest
+}
+
+func (c *fakeMetricsClient) UpdateMetrics(ctx context.Context, req *pb.UpdateMetricsRequest,
+	opts ...grpc.CallOption) (*pb.UpdateMetricsResponse, error) {
+	c.requests = append(c.requests, req)
+	if len(c.requests) <= c.fails {
+		if c.err != nil {
+			return nil, c.err
+		}
+		return nil, errors.New("unavailable")
+	}
+	return &pb.UpdateMetricsResponse{}, nil
+}
+
+func TestMakeGRPCSendMetricsFunc(t *testing.T) {
+	memstorage.MS.ObserveHistogram("GRPCLatency", 0.5)
+
+	client := &fakeMetricsClient{fails: 1}
+	send := MakeGRPCSendMetricsFunc(client, []time.Duration{time.Millisecond, time.Millisecond})
+	send()
+
+	require.Len(t, client.requests, 2)
+	var found bool
+	for _, metric := range client.requests[1].GetMetrics() {
+		if metric.GetId() == "GRPCLatency" {
+			found = true
+			assert.Equal(t, uint64(1), metric.GetHistogram().GetCount())
+		}
+	}
+	assert.True(t, found)
+
+	hist, ok := memstorage.MS.GetHistogramValue("GRPCLatency")
+	require.True(t, ok)
+	assert.Zero(t, hist.Count)
+}
+
+func TestMakeGRPCSendMetricsFunc_Rejected(t *testing.T) {
+	memstorage.MS.AddGauge("GRPCRejected", 1)
+
+	client := &fakeMetricsClient{fails: 3, err: status.Error(codes.InvalidArgument, "metric 0: empty val")}
+	send := MakeGRPCSendMetricsFunc(client, []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond})
+	send()
+
+	assert.Len(t, client.requests, 1)
+}
@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+// StatsNamespace prefixes the metrics of the agent itself, reported as
+// gauges along with the collected ones.
+const StatsNamespace = "metrics_agent_"
+
+// Stats are the metrics the agent keeps about its reports.
+type Stats struct {
+	// SendSuccesses and SendFailures count the requests to the server, a
+	// gRPC report is a single request.
+	SendSuccesses uint64
+	SendFailures  uint64
+	// Retries counts the requests repeated after a failure.
+	Retries uint64
+	// QueueDepth is the number of metrics of the report in progress that are
+	// not sent yet.
+	QueueDepth int
+	// LastReport is when every metric of a report was last sent, zero before.
+	LastReport time.Time
+	// CollectorErrors counts the metrics that could not be converted for a
+	// report.
+	CollectorErrors uint64
+}
+
+var (
+	statsMu sync.Mutex
+	stats   Stats
+	// now is replaced in tests
+	now = time.Now
+)
+
+// CurrentStats returns a copy of the stats of the agent.
+func CurrentStats() Stats {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	return stats
+}
+
+func updateStats(f func(s *Stats)) {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	f(&stats)
+}
+
+// countSend counts a request to the server, attempt is 0 for the first one.
+func countSend(attempt int, err error) {
+	updateStats(func(s *Stats) {
+		if attempt > 0 {
+			s.Retries++
+		}
+		if err != nil {
+			s.SendFailures++
+		} else {
+			s.SendSuccesses++
+		}
+	})
+}
+
+func countCollectorError() {
+	updateStats(func(s *Stats) { s.CollectorErrors++ })
+}
+
+func setQueueDepth(n int) {
+	updateStats(func(s *Stats) { s.QueueDepth = n })
+}
+
+// finishReport resets the queue and records the time of a report that sent
+// every metric.
+func finishReport(ok bool) {
+	updateStats(func(s *Stats) {
+		s.QueueDepth = 0
+		if ok {
+			s.LastReport = now()
+		}
+	})
+}
+
+// Gauges returns the stats by the names they are reported under.
+func (s Stats) Gauges() map[string]metrics.Gauge {
+	var lastReport metrics.Gauge
+	if !s.LastReport.IsZero() {
+		lastReport = metrics.Gauge(float64(s.LastReport.UnixNano()) / float64(time.Second))
+	}
+	return map[string]metrics.Gauge{
+		StatsNamespace + "sends_succeeded":               metrics.Gauge(s.SendSuccesses),
+		StatsNamespace + "sends_failed":                  metrics.Gauge(s.SendFailures),
+		StatsNamespace + "send_retries":                  metrics.Gauge(s.Retries),
+		StatsNamespace + "queue_depth":                   metrics.Gauge(s.QueueDepth),
+		StatsNamespace + "last_report_timestamp_seconds": lastReport,
+		StatsNamespace + "collector_errors":              metrics.Gauge(s.CollectorErrors),
+	}
+}
+
+// storeStats puts the stats into the storage so that the next report
+// includes them. Totals are stored as gauges since the server adds up the
+// counters it receives.
+func storeStats() {
+	for name, val := range CurrentStats().Gauges() {
+		if err := memstorage.MS.AddGauge(name, val); err != nil {
+			log.Error().Err(err).Msgf("failed to store %s", name)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	statusOK    = "ok"
+	statusStale = "stale"
+)
+
+// StaleAfter is how long the agent stays healthy without a successful report,
+// zero disables the check.
+var StaleAfter time.Duration
+
+func SetStaleAfter(d time.Duration) {
+	StaleAfter = d
+}
+
+var startedAt = time.Now()
+
+//easyjson:json
+type statusResponse struct {
+	Status     string             `json:"status"`
+	Uptime     float64            `json:"uptime_seconds"`
+	LastReport string             `json:"last_report,omitempty"`
+	Metrics    map[string]float64 `json:"metrics"`
+}
+
+// healthy reports whether the last successful report, or the start of the
+// agent before the first one, is more recent than StaleAfter.
+func healthy(s Stats) bool {
+	if StaleAfter == 0 {
+		return true
+	}
+	last := s.LastReport
+	if last.IsZero() {
+		last = startedAt
+	}
+	return now().Sub(last) < StaleAfter
+}
+
+// StatusHandler serves the health of the agent along with its own metrics, a
+// stale agent responds with 503.
+func StatusHandler(w http.ResponseWriter, req *http.Request) {
+	s := CurrentStats()
+	resp := statusResponse{
+		Status:  statusOK,
+		Uptime:  now().Sub(startedAt).Seconds(),
+		Metrics: make(map[string]float64),
+	}
+	for name, val := range s.Gauges() {
+		resp.Metrics[name] = float64(val)
+	}
+	if !s.LastReport.IsZero() {
+		resp.LastReport = s.LastReport.Format(time.RFC3339)
+	}
+
+	status := http.StatusOK
+	if !healthy(s) {
+		resp.Status = statusStale
+		status = http.StatusServiceUnavailable
+	}
+
+	data, err := resp.MarshalJSON()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to encode status")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(data); err != nil {
+		log.Error().Err(err).Msg("error writing response")
+	}
+}
+
+// ListenAndServeStatus serves GET /status on addr in a background goroutine.
+func ListenAndServeStatus(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", StatusHandler)
+	go func() {
+		if err := http.Serve(ln, mux); err != nil {
+			log.Error().Err(err).Msg("status server stopped")
+		}
+	}()
+	return nil
+}
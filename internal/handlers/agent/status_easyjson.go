@@ -0,0 +1,136 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package agent
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson727fe99aDecodeGithubComAPalonskaaMetricsServerInternalHandlersAgent(in *jlexer.Lexer, out *statusResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "status":
+			out.Status = string(in.String())
+		case "uptime_seconds":
+			out.Uptime = float64(in.Float64())
+		case "last_report":
+			out.LastReport = string(in.String())
+		case "metrics":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.Metrics = make(map[string]float64)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v1 float64
+					v1 = float64(in.Float64())
+					(out.Metrics)[key] = v1
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson727fe99aEncodeGithubComAPalonskaaMetricsServerInternalHandlersAgent(out *jwriter.Writer, in statusResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Status))
+	}
+	{
+		const prefix string = ",\"uptime_seconds\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Uptime))
+	}
+	if in.LastReport != "" {
+		const prefix string = ",\"last_report\":"
+		out.RawString(prefix)
+		out.String(string(in.LastReport))
+	}
+	{
+		const prefix string = ",\"metrics\":"
+		out.RawString(prefix)
+		if in.Metrics == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v2First := true
+			for v2Name, v2Value := range in.Metrics {
+				if v2First {
+					v2First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v2Name))
+				out.RawByte(':')
+				out.Float64(float64(v2Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v statusResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson727fe99aEncodeGithubComAPalonskaaMetricsServerInternalHandlersAgent(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v statusResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson727fe99aEncodeGithubComAPalonskaaMetricsServerInternalHandlersAgent(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *statusResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson727fe99aDecodeGithubComAPalonskaaMetricsServerInternalHandlersAgent(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *statusResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson727fe99aDecodeGithubComAPalonskaaMetricsServerInternalHandlersAgent(l, v)
+}
@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+func TestStats(t *testing.T) {
+	clock := time.Unix(1_700_000_000, 0)
+	now = func() time.Time { return clock }
+	startedAt = clock
+	stats = Stats{}
+	SetStaleAfter(time.Minute)
+	defer func() {
+		now = time.Now
+		SetStaleAfter(0)
+	}()
+
+	var requests atomic.Int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			http.Error(w, "try again", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	memstorage.MS.AddGauge("StatsTest", 1)
+	send := MakeSendMetricsFunc(resty.New(), ts.URL[7:], []time.Duration{time.Millisecond, time.Millisecond})
+	send()
+
+	s := CurrentStats()
+	assert.Equal(t, uint64(1), s.SendFailures)
+	assert.Equal(t, uint64(1), s.Retries)
+	assert.Equal(t, uint64(requests.Load()-1), s.SendSuccesses)
+	assert.Zero(t, s.QueueDepth)
+	assert.Equal(t, clock, s.LastReport)
+
+	// the stats are reported with the metrics
+	val, ok := memstorage.MS.GetGaugeValue(StatsNamespace + "sends_succeeded")
+	require.True(t, ok)
+	assert.Zero(t, val)
+	send()
+	val, _ = memstorage.MS.GetGaugeValue(StatsNamespace + "sends_succeeded")
+	assert.Equal(t, metrics.Gauge(s.SendSuccesses), val)
+
+	w := httptest.NewRecorder()
+	StatusHandler(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp statusResponse
+	require.NoError(t, resp.UnmarshalJSON(w.Body.Bytes()))
+	assert.Equal(t, statusOK, resp.Status)
+	assert.Equal(t, clock.Format(time.RFC3339), resp.LastReport)
+	assert.Equal(t, float64(1), resp.Metrics[StatsNamespace+"send_retries"])
+
+	clock = clock.Add(time.Minute)
+	w = httptest.NewRecorder()
+	StatusHandler(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"stale"`)
+}
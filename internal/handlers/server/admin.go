@@ -0,0 +1,168 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+// AdminOptions configure the admin API, it is disabled while Token is empty.
+type AdminOptions struct {
+	// Token is expected as "Authorization: Bearer <token>".
+	Token string
+	// SnapshotFile is the storage file written by POST /admin/snapshot.
+	SnapshotFile *os.File
+	// Audit receives an entry for every admin request, the global logger if
+	// nil.
+	Audit *zerolog.Logger
+}
+
+var Admin AdminOptions
+
+func SetAdminOptions(opts AdminOptions) {
+	Admin = opts
+}
+
+// adminResponse lists the series changed by an admin action by type.
+//
+//easyjson:json
+type adminResponse struct {
+	Series map[string][]string `json:"series,omitempty"`
+	Count  int                 `json:"count"`
+}
+
+func routeAdmin(r chi.Router) {
+	r.Use(withAdminAuth)
+	r.Delete("/metrics", DeleteMetricsHandler)
+	r.Post("/counters/reset", ResetCountersHandler)
+	r.Post("/snapshot", SnapshotHandler)
+	r.Post("/compact", CompactHandler)
+}
+
+// auditEvent starts the audit entry of an admin request.
+func auditEvent(req *http.Request, action string) *zerolog.Event {
+	audit := Admin.Audit
+	if audit == nil {
+		audit = &log.Logger
+	}
+	return audit.Info().
+		Str("audit", action).
+		Str("remote", req.RemoteAddr).
+		Str("method", req.Method).
+		Str("uri", req.RequestURI)
+}
+
+func withAdminAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if Admin.Token == "" {
+			http.Error(w, "admin API is disabled", http.StatusNotFound)
+			return
+		}
+
+		token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(Admin.Token)) != 1 {
+			auditEvent(req, "unauthorized").Int("status", http.StatusUnauthorized).Msg("admin request rejected")
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			http.Error(w, "invalid admin token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// seriesSelector builds the series filter of an admin request from the type,
+// name (exact series name), prefix, regex and label query parameters, see
+// ListJSONValueHandler.
+func seriesSelector(req *http.Request) (func(mType, series string) bool, string, int) {
+	query := req.URL.Query()
+	opts, message, status := parseListOptions(query)
+	if status != http.StatusOK {
+		return nil, message, status
+	}
+	name := query.Get("name")
+
+	return func(mType, series string) bool {
+		if name != "" && series != name {
+			return false
+		}
+		id, labels, err := metrics.ParseSeriesName(series)
+		if err != nil {
+			// unparsable names can only be selected by their exact name
+			return name != "" && (opts.types == nil || opts.types[mType])
+		}
+		return opts.matches(metrics.Metrics{ID: id, MType: mType, Labels: labels})
+	}, "", http.StatusOK
+}
+
+// DeleteMetricsHandler deletes the selected series with their history. At
+// least one of name, prefix, regex or label is required.
+func DeleteMetricsHandler(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	if query.Get("name") == "" && query.Get("prefix") == "" && query.Get("regex") == "" && len(query["label"]) == 0 {
+		http.Error(w, "one of name, prefix, regex or label is required", http.StatusBadRequest)
+		return
+	}
+
+	match, message, status := seriesSelector(req)
+	if status != http.StatusOK {
+		http.Error(w, message, status)
+		return
+	}
+
+	deleted := memstorage.MS.DeleteSeries(match)
+	resp := adminResponse{Series: deleted}
+	for _, series := range deleted {
+		resp.Count += len(series)
+	}
+	auditEvent(req, "delete").Int("count", resp.Count).Interface("series", deleted).Msg("metrics deleted")
+	writeJSON(w, req, http.StatusOK, resp.MarshalJSON)
+}
+
+// ResetCountersHandler sets the selected counters to 0, every counter if
+// nothing is selected.
+func ResetCountersHandler(w http.ResponseWriter, req *http.Request) {
+	match, message, status := seriesSelector(req)
+	if status != http.StatusOK {
+		http.Error(w, message, status)
+		return
+	}
+
+	reset := memstorage.MS.ResetCounters(func(series string) bool {
+		return match(metrics.CounterName, series)
+	})
+	resp := adminResponse{Series: map[string][]string{metrics.CounterName: reset}, Count: len(reset)}
+	auditEvent(req, "reset").Int("count", resp.Count).Strs("series", reset).Msg("counters reset")
+	writeJSON(w, req, http.StatusOK, resp.MarshalJSON)
+}
+
+// SnapshotHandler writes the storage file right away.
+func SnapshotHandler(w http.ResponseWriter, req *http.Request) {
+	if Admin.SnapshotFile == nil {
+		http.Error(w, "no storage file", http.StatusNotFound)
+		return
+	}
+
+	if err := memstorage.WriteMetricsStorage(Admin.SnapshotFile); err != nil {
+		requestLogger(req).Error().Err(err).Msg("error writing snapshot")
+		auditEvent(req, "snapshot").Int("status", http.StatusInternalServerError).Msg("snapshot failed")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	auditEvent(req, "snapshot").Str("file", Admin.SnapshotFile.Name()).Msg("snapshot written")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CompactHandler drops leftovers of deleted series and old counter samples.
+func CompactHandler(w http.ResponseWriter, req *http.Request) {
+	resp := adminResponse{Count: memstorage.MS.Compact()}
+	auditEvent(req, "compact").Int("count", resp.Count).Msg("storage compacted")
+	writeJSON(w, req, http.StatusOK, resp.MarshalJSON)
+}
@@ -0,0 +1,162 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package server
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson9280440fDecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(in *jlexer.Lexer, out *adminResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "series":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Series = make(map[string][]string)
+				} else {
+					out.Series = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v1 []string
+					if in.IsNull() {
+						in.Skip()
+						v1 = nil
+					} else {
+						in.Delim('[')
+						if v1 == nil {
+							if !in.IsDelim(']') {
+								v1 = make([]string, 0, 4)
+							} else {
+								v1 = []string{}
+							}
+						} else {
+							v1 = (v1)[:0]
+						}
+						for !in.IsDelim(']') {
+							var v2 string
+							v2 = string(in.String())
+							v1 = append(v1, v2)
+							in.WantComma()
+						}
+						in.Delim(']')
+					}
+					(out.Series)[key] = v1
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "count":
+			out.Count = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson9280440fEncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(out *jwriter.Writer, in adminResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if len(in.Series) != 0 {
+		const prefix string = ",\"series\":"
+		first = false
+		out.RawString(prefix[1:])
+		{
+			out.RawByte('{')
+			v3First := true
+			for v3Name, v3Value := range in.Series {
+				if v3First {
+					v3First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v3Name))
+				out.RawByte(':')
+				if v3Value == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+					out.RawString("null")
+				} else {
+					out.RawByte('[')
+					for v4, v5 := range v3Value {
+						if v4 > 0 {
+							out.RawByte(',')
+						}
+						out.String(string(v5))
+					}
+					out.RawByte(']')
+				}
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"count\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int(int(in.Count))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v adminResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson9280440fEncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v adminResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson9280440fEncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *adminResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson9280440fDecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *adminResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson9280440fDecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(l, v)
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+func TestAdminHandlers(t *testing.T) {
+	r := chi.NewRouter()
+	RouteRequests(r)
+
+	snapshot, err := os.Create(filepath.Join(t.TempDir(), "snapshot.json"))
+	require.NoError(t, err)
+	defer snapshot.Close()
+
+	var audit bytes.Buffer
+	auditLogger := zerolog.New(&audit)
+	SetAdminOptions(AdminOptions{Token: "secret", SnapshotFile: snapshot, Audit: &auditLogger})
+	defer SetAdminOptions(AdminOptions{})
+
+	memstorage.MS.AddGauge(`AdminLoad{host="a"}`, 1)
+	memstorage.MS.AddGauge(`AdminLoad{host="b"}`, 2)
+	memstorage.MS.AddCounter("AdminRequests", 5)
+
+	do := func(method, url, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		token  string
+		status int
+		want   adminResponse
+	}{
+		{name: "no token", method: http.MethodPost, url: "/admin/compact", status: http.StatusUnauthorized},
+		{name: "wrong token", method: http.MethodPost, url: "/admin/compact", token: "guess", status: http.StatusUnauthorized},
+		{name: "delete without selector", method: http.MethodDelete, url: "/admin/metrics", token: "secret", status: http.StatusBadRequest},
+		{name: "invalid regex", method: http.MethodDelete, url: "/admin/metrics?regex=(", token: "secret", status: http.StatusBadRequest},
+		{
+			name:   "delete by label",
+			method: http.MethodDelete,
+			url:    "/admin/metrics?prefix=AdminLoad&label=host%3Da",
+			token:  "secret",
+			status: http.StatusOK,
+			want:   adminResponse{Series: map[string][]string{metrics.GaugeName: {`AdminLoad{host="a"}`}}, Count: 1},
+		},
+		{
+			name:   "delete by name",
+			method: http.MethodDelete,
+			url:    "/admin/metrics?type=gauge&name=" + url.QueryEscape(`AdminLoad{host="b"}`),
+			token:  "secret",
+			status: http.StatusOK,
+			want:   adminResponse{Series: map[string][]string{metrics.GaugeName: {`AdminLoad{host="b"}`}}, Count: 1},
+		},
+		{
+			name:   "reset counters",
+			method: http.MethodPost,
+			url:    "/admin/counters/reset?regex=^AdminRequests$",
+			token:  "secret",
+			status: http.StatusOK,
+			want:   adminResponse{Series: map[string][]string{metrics.CounterName: {"AdminRequests"}}, Count: 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := do(test.method, test.url, test.token)
+			require.Equal(t, test.status, w.Code, w.Body.String())
+			if test.status != http.StatusOK {
+				return
+			}
+			var resp adminResponse
+			require.NoError(t, resp.UnmarshalJSON(w.Body.Bytes()))
+			assert.Equal(t, test.want, resp)
+		})
+	}
+
+	_, ok := memstorage.MS.GetGaugeValue(`AdminLoad{host="a"}`)
+	assert.False(t, ok)
+	requests, _ := memstorage.MS.GetCounterValue("AdminRequests")
+	assert.Equal(t, metrics.Counter(0), requests)
+
+	w := do(http.MethodPost, "/admin/compact", "secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = do(http.MethodPost, "/admin/snapshot", "secret")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	data, err := os.ReadFile(snapshot.Name())
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "AdminRequests")
+	assert.NotContains(t, string(data), "AdminLoad")
+
+	assert.Contains(t, audit.String(), `"audit":"unauthorized"`)
+	assert.Contains(t, audit.String(), `"audit":"delete"`)
+	assert.Contains(t, audit.String(), `"audit":"snapshot"`)
+
+	SetAdminOptions(AdminOptions{})
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/admin/compact", "").Code)
+}
+
+func TestAdminDeleteRuntimeSeries(t *testing.T) {
+	r := chi.NewRouter()
+	RouteRequests(r)
+
+	SetAdminOptions(AdminOptions{Token: "secret"})
+	defer SetAdminOptions(AdminOptions{})
+
+	do := func(method, url string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	memstorage.MS.Update(&runtime.MemStats{})
+	require.Equal(t, http.StatusOK, do(http.MethodDelete, "/admin/metrics?type=gauge&name=Alloc").Code)
+	assert.False(t, memstorage.MS.IsGaugeAllowed("Alloc"))
+
+	// the next update brings the runtime series back and compaction keeps it
+	memstorage.MS.Update(&runtime.MemStats{})
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/admin/compact").Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/value/gauge/Alloc").Code)
+}
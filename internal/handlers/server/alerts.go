@@ -0,0 +1,141 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/a-palonskaa/metrics-server/internal/alerting"
+)
+
+// AlertEngine evaluates the alerting rules, nil if no rules are configured.
+var AlertEngine *alerting.Engine
+
+func SetAlertEngine(engine *alerting.Engine) {
+	AlertEngine = engine
+}
+
+// AlertsHandler lists the pending, firing and recently resolved alerts. The
+// state query parameter filters by a comma separated list of states.
+func AlertsHandler(w http.ResponseWriter, req *http.Request) {
+	var states map[string]bool
+	if s := req.URL.Query().Get("state"); s != "" {
+		states = make(map[string]bool)
+		for _, state := range strings.Split(s, ",") {
+			switch state {
+			case alerting.StatePending, alerting.StateFiring, alerting.StateResolved:
+				states[state] = true
+			default:
+				http.Error(w, "unknown state: "+state, http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	list := alerting.AlertList{}
+	if AlertEngine != nil {
+		for _, alert := range AlertEngine.Alerts() {
+			if states == nil || states[alert.State] {
+				list = append(list, alert)
+			}
+		}
+	}
+
+	writeJSON(w, req, http.StatusOK, list.MarshalJSON)
+}
+
+// silenceRequest creates a silence from now on for Duration, or between
+// StartsAt (default now) and EndsAt.
+//
+//easyjson:json
+type silenceRequest struct {
+	Matchers []string   `json:"matchers"`
+	Duration string     `json:"duration,omitempty"`
+	StartsAt *time.Time `json:"startsAt,omitempty"`
+	EndsAt   *time.Time `json:"endsAt,omitempty"`
+	Comment  string     `json:"comment,omitempty"`
+}
+
+func SilencesHandler(w http.ResponseWriter, req *http.Request) {
+	list := alerting.SilenceList{}
+	if AlertEngine != nil {
+		list = AlertEngine.Silences().List(time.Now())
+	}
+	writeJSON(w, req, http.StatusOK, list.MarshalJSON)
+}
+
+func CreateSilenceHandler(w http.ResponseWriter, req *http.Request) {
+	if AlertEngine == nil {
+		http.Error(w, "alerting is disabled", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		requestLogger(req).Error().Err(err).Msg("Error Reading body")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var silenceReq silenceRequest
+	if err := silenceReq.UnmarshalJSON(body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	startsAt := time.Now()
+	if silenceReq.StartsAt != nil {
+		startsAt = *silenceReq.StartsAt
+	}
+	var endsAt time.Time
+	switch {
+	case silenceReq.EndsAt != nil:
+		endsAt = *silenceReq.EndsAt
+	case silenceReq.Duration != "":
+		duration, err := time.ParseDuration(silenceReq.Duration)
+		if err != nil {
+			http.Error(w, "invalid duration: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		endsAt = startsAt.Add(duration)
+	default:
+		http.Error(w, "either duration or endsAt is required", http.StatusBadRequest)
+		return
+	}
+
+	silence, err := AlertEngine.Silences().Add(silenceReq.Matchers, startsAt, endsAt, silenceReq.Comment)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	auditEvent(req, "silence").Str("id", silence.ID).Strs("matchers", silence.Matchers).Time("endsAt", silence.EndsAt).Msg("silence created")
+	writeJSON(w, req, http.StatusCreated, silence.MarshalJSON)
+}
+
+func DeleteSilenceHandler(w http.ResponseWriter, req *http.Request) {
+	id := chi.URLParam(req, "id")
+	if AlertEngine == nil || !AlertEngine.Silences().Delete(id) {
+		http.Error(w, "silence not found", http.StatusNotFound)
+		return
+	}
+	auditEvent(req, "unsilence").Str("id", id).Msg("silence deleted")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, req *http.Request, status int, marshal func() ([]byte, error)) {
+	resp, err := marshal()
+	if err != nil {
+		requestLogger(req).Error().Err(err).Msg("failed to marshal response")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(resp); err != nil {
+		requestLogger(req).Error().Err(err).Msg("error writing response")
+	}
+}
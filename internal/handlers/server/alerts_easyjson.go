@@ -0,0 +1,166 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package server
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+	time "time"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonBee2c381DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(in *jlexer.Lexer, out *silenceRequest) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "matchers":
+			if in.IsNull() {
+				in.Skip()
+				out.Matchers = nil
+			} else {
+				in.Delim('[')
+				if out.Matchers == nil {
+					if !in.IsDelim(']') {
+						out.Matchers = make([]string, 0, 4)
+					} else {
+						out.Matchers = []string{}
+					}
+				} else {
+					out.Matchers = (out.Matchers)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					v1 = string(in.String())
+					out.Matchers = append(out.Matchers, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "duration":
+			out.Duration = string(in.String())
+		case "startsAt":
+			if in.IsNull() {
+				in.Skip()
+				out.StartsAt = nil
+			} else {
+				if out.StartsAt == nil {
+					out.StartsAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.StartsAt).UnmarshalJSON(data))
+				}
+			}
+		case "endsAt":
+			if in.IsNull() {
+				in.Skip()
+				out.EndsAt = nil
+			} else {
+				if out.EndsAt == nil {
+					out.EndsAt = new(time.Time)
+				}
+				if data := in.Raw(); in.Ok() {
+					in.AddError((*out.EndsAt).UnmarshalJSON(data))
+				}
+			}
+		case "comment":
+			out.Comment = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonBee2c381EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(out *jwriter.Writer, in silenceRequest) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"matchers\":"
+		out.RawString(prefix[1:])
+		if in.Matchers == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.Matchers {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v3))
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.Duration != "" {
+		const prefix string = ",\"duration\":"
+		out.RawString(prefix)
+		out.String(string(in.Duration))
+	}
+	if in.StartsAt != nil {
+		const prefix string = ",\"startsAt\":"
+		out.RawString(prefix)
+		out.Raw((*in.StartsAt).MarshalJSON())
+	}
+	if in.EndsAt != nil {
+		const prefix string = ",\"endsAt\":"
+		out.RawString(prefix)
+		out.Raw((*in.EndsAt).MarshalJSON())
+	}
+	if in.Comment != "" {
+		const prefix string = ",\"comment\":"
+		out.RawString(prefix)
+		out.String(string(in.Comment))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v silenceRequest) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonBee2c381EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v silenceRequest) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonBee2c381EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *silenceRequest) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonBee2c381DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *silenceRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonBee2c381DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(l, v)
+}
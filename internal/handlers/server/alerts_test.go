@@ -0,0 +1,129 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/a-palonskaa/metrics-server/internal/alerting"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+func TestAlertsHandler(t *testing.T) {
+	r := chi.NewRouter()
+	RouteRequests(r)
+
+	storage := memstorage.NewMetricsStorage()
+	storage.AddGauge("Load", 5)
+	storage.AddGauge("Temp", 90)
+
+	var rules []alerting.Rule
+	for _, cfg := range []alerting.RuleConfig{
+		{Name: "HighLoad", Expr: "Load > 1", For: "1m"},
+		{Name: "Hot", Expr: "Temp > 80"},
+	} {
+		rule, err := alerting.ParseRule(cfg)
+		require.NoError(t, err)
+		rules = append(rules, rule)
+	}
+	engine := alerting.NewEngine(storage, rules)
+	engine.Eval(time.Now())
+
+	SetAlertEngine(engine)
+	defer SetAlertEngine(nil)
+
+	tests := []struct {
+		name   string
+		url    string
+		status int
+		rules  []string
+	}{
+		{name: "all", url: "/alerts", status: http.StatusOK, rules: []string{"HighLoad", "Hot"}},
+		{name: "firing", url: "/alerts?state=firing", status: http.StatusOK, rules: []string{"Hot"}},
+		{name: "resolved", url: "/alerts?state=resolved", status: http.StatusOK, rules: nil},
+		{name: "unknown state", url: "/alerts?state=done", status: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, test.url, nil))
+			require.Equal(t, test.status, w.Code)
+			if test.status != http.StatusOK {
+				return
+			}
+
+			var list alerting.AlertList
+			require.NoError(t, list.UnmarshalJSON(w.Body.Bytes()))
+			var names []string
+			for _, alert := range list {
+				names = append(names, alert.Rule)
+			}
+			assert.Equal(t, test.rules, names)
+		})
+	}
+}
+
+func TestSilenceHandlers(t *testing.T) {
+	r := chi.NewRouter()
+	RouteRequests(r)
+
+	SetAdminOptions(AdminOptions{Token: "secret"})
+	defer SetAdminOptions(AdminOptions{})
+
+	token := "secret"
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/alerts/silences", `{"matchers": ["rule=Hot"], "duration": "1h"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	SetAlertEngine(alerting.NewEngine(memstorage.NewMetricsStorage(), nil))
+	defer SetAlertEngine(nil)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{name: "duration", body: `{"matchers": ["rule=Hot", "host=~web.*"], "duration": "1h", "comment": "deploy"}`, status: http.StatusCreated},
+		{name: "ends at", body: `{"matchers": ["rule=Hot"], "endsAt": "2100-01-01T00:00:00Z"}`, status: http.StatusCreated},
+		{name: "no end", body: `{"matchers": ["rule=Hot"]}`, status: http.StatusBadRequest},
+		{name: "no matchers", body: `{"duration": "1h"}`, status: http.StatusBadRequest},
+		{name: "bad matcher", body: `{"matchers": ["rule"], "duration": "1h"}`, status: http.StatusBadRequest},
+		{name: "bad duration", body: `{"matchers": ["rule=Hot"], "duration": "soon"}`, status: http.StatusBadRequest},
+		{name: "bad json", body: `{`, status: http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := do(http.MethodPost, "/alerts/silences", test.body)
+			assert.Equal(t, test.status, w.Code, w.Body.String())
+		})
+	}
+
+	w = do(http.MethodGet, "/alerts/silences", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list alerting.SilenceList
+	require.NoError(t, list.UnmarshalJSON(w.Body.Bytes()))
+	require.Len(t, list, 2)
+	assert.Equal(t, "deploy", list[0].Comment)
+
+	token = "wrong"
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodPost, "/alerts/silences", tests[0].body).Code)
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodDelete, "/alerts/silences/"+list[0].ID, "").Code)
+
+	token = "secret"
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/alerts/silences/"+list[0].ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/alerts/silences/"+list[0].ID, "").Code)
+}
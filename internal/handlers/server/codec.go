@@ -0,0 +1,63 @@
+package server
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	pb "github.com/a-palonskaa/metrics-server/internal/proto"
+)
+
+const (
+	jsonContentType     = "application/json"
+	protobufContentType = "application/x-protobuf"
+)
+
+// mediaType returns the media type of the request body without its
+// parameters, empty if the Content-Type header is missing or invalid.
+func mediaType(req *http.Request) string {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return mediaType
+}
+
+// decodeMetric parses a body of /update/ or /value/ as protobuf if the
+// request is sent with the application/x-protobuf content type and as JSON
+// otherwise.
+func decodeMetric(req *http.Request, body []byte, metric *metrics.Metrics) error {
+	if mediaType(req) != protobufContentType {
+		return metric.UnmarshalJSON(body)
+	}
+
+	var pm pb.Metric
+	if err := proto.Unmarshal(body, &pm); err != nil {
+		return err
+	}
+	*metric = pm.ToMetrics()
+	return nil
+}
+
+// responseContentType selects protobuf responses for clients accepting them
+// and, if there is no Accept header, for protobuf requests.
+func responseContentType(req *http.Request) string {
+	accept := req.Header.Get("Accept")
+	if strings.Contains(accept, protobufContentType) {
+		return protobufContentType
+	}
+	if (accept == "" || accept == "*/*") && mediaType(req) == protobufContentType {
+		return protobufContentType
+	}
+	return jsonContentType
+}
+
+func encodeMetric(contentType string, metric metrics.Metrics) ([]byte, error) {
+	if contentType == protobufContentType {
+		return proto.Marshal(pb.FromMetrics(metric))
+	}
+	return metric.MarshalJSON()
+}
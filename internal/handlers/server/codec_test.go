@@ -0,0 +1,78 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/a-palonskaa/metrics-server/internal/proto"
+)
+
+func TestProtobufUpdateAndValue(t *testing.T) {
+	r := chi.NewRouter()
+	RouteRequests(r)
+
+	contentType := protobufContentType
+	post := func(url string, metric *pb.Metric, accept string) *httptest.ResponseRecorder {
+		body, err := proto.Marshal(metric)
+		require.NoError(t, err)
+		request := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		request.Header.Set("Content-Type", contentType)
+		if accept != "" {
+			request.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, request)
+		return w
+	}
+
+	delta := int64(4)
+	w := post("/update/", &pb.Metric{Id: "ProtoCounter", Type: "counter", Delta: &delta}, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, protobufContentType, w.Header().Get("Content-Type"))
+
+	var updated pb.Metric
+	require.NoError(t, proto.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Equal(t, int64(4), updated.GetDelta())
+
+	w = post("/value/", &pb.Metric{Id: "ProtoCounter", Type: "counter"}, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var value pb.Metric
+	require.NoError(t, proto.Unmarshal(w.Body.Bytes(), &value))
+	assert.Equal(t, int64(4), value.GetDelta())
+
+	w = post("/value/", &pb.Metric{Id: "ProtoCounter", Type: "counter"}, jsonContentType)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"ProtoCounter","type":"counter","delta":4}`, w.Body.String())
+
+	w = post("/update/", &pb.Metric{Id: "ProtoGauge", Type: "gauge"}, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	contentType = "Application/X-Protobuf; proto=metrics.Metric"
+	w = post("/value/", &pb.Metric{Id: "ProtoCounter", Type: "counter"}, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, protobufContentType, w.Header().Get("Content-Type"))
+	require.NoError(t, proto.Unmarshal(w.Body.Bytes(), &value))
+	assert.Equal(t, int64(4), value.GetDelta())
+
+	w = post("/update/", &pb.Metric{Id: "ProtoCounter", Type: "counter", Delta: &delta}, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	w = post("/value/", &pb.Metric{Id: "ProtoCounter", Type: "counter"}, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, proto.Unmarshal(w.Body.Bytes(), &value))
+	assert.Equal(t, int64(8), value.GetDelta())
+
+	request := httptest.NewRequest(http.MethodPost, "/update/",
+		bytes.NewBufferString(`{"id":"JSONCounter","type":"counter","delta":2}`))
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"JSONCounter","type":"counter","delta":2}`, w.Body.String())
+}
@@ -2,11 +2,14 @@ package server
 
 import (
 	"compress/gzip"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/a-palonskaa/metrics-server/internal/selfmetrics"
 )
 
 type gzipWriter struct {
@@ -18,11 +21,27 @@ func (w gzipWriter) Write(b []byte) (int, error) {
 	return w.Writer.Write(b)
 }
 
+// gzipReader counts a request body that fails to decompress once.
+type gzipReader struct {
+	*gzip.Reader
+	failed bool
+}
+
+func (r *gzipReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) && !r.failed {
+		r.failed = true
+		selfmetrics.CountDecompressionFailure()
+	}
+	return n, err
+}
+
 func WithCompression(fn http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if strings.Contains(r.Header.Get("Content-Encoding"), "gzip") {
 			gz, err := gzip.NewReader(r.Body)
 			if err != nil {
+				selfmetrics.CountDecompressionFailure()
 				http.Error(w, "failed to decompress request", http.StatusBadRequest)
 				return
 			}
@@ -31,17 +50,19 @@ func WithCompression(fn http.Handler) http.Handler {
 					log.Fatal().Err(err)
 				}
 			}()
-			r.Body = gz
+			r.Body = &gzipReader{Reader: gz}
 		}
 
-		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		// streams are flushed event by event and websockets hijack the
+		// connection, neither goes through the gzip writer
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") || isStreamRequest(r) {
 			fn.ServeHTTP(w, r)
 			return
 		}
 
 		gz, err := gzip.NewWriterLevel(w, gzip.BestSpeed)
 		if err != nil {
-			log.Error().Err(err).Msg("failed to create gzip writer")
+			requestLogger(r).Error().Err(err).Msg("failed to create gzip writer")
 			fn.ServeHTTP(w, r)
 			return
 		}
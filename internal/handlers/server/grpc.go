@@ -0,0 +1,187 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/encoding/gzip" // lets clients send gzip compressed requests
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	pb "github.com/a-palonskaa/metrics-server/internal/proto"
+)
+
+// GRPCServer implements the Metrics service on top of the same storage
+// helpers as the chi handlers.
+type GRPCServer struct {
+	pb.UnimplementedMetricsServer
+}
+
+// UpdateMetrics stores a batch of metrics and returns their stored values.
+// The whole batch is validated and admitted first, so an invalid metric leaves
+// the storage untouched. A metric the storage then fails to merge, such as a
+// histogram with other bounds than the stored one, stops the batch and the
+// error tells how many metrics before it were applied.
+func (s *GRPCServer) UpdateMetrics(ctx context.Context, req *pb.UpdateMetricsRequest) (*pb.UpdateMetricsResponse, error) {
+	agent := grpcAgentID(ctx)
+	if err := allowGRPC(agent, len(req.GetMetrics())); err != nil {
+		return nil, err
+	}
+
+	batch := make([]metrics.Metrics, 0, len(req.GetMetrics()))
+	for i, pm := range req.GetMetrics() {
+		metric := pm.ToMetrics()
+		if message, code := validateMetric(metric); code != http.StatusOK {
+			return nil, status.Errorf(grpcCode(code), "metric %d: %s", i, message)
+		}
+		if err := admitSeries(agent, metric.MType, metric.SeriesName()); err != nil {
+			return nil, status.Errorf(grpcCode(admissionStatus(err)), "metric %d: %s", i, err)
+		}
+		batch = append(batch, metric)
+	}
+
+	resp := &pb.UpdateMetricsResponse{Metrics: make([]*pb.Metric, 0, len(batch))}
+	for i := range batch {
+		metric := &batch[i]
+		if err := addMetricToStorage(metric); err != nil {
+			return nil, status.Errorf(grpcCode(admissionStatus(err)), "metric %d: %s, %d metrics before it were applied", i, err, i)
+		}
+		stored := metrics.Metrics{ID: metric.ID, MType: metric.MType, Labels: metric.Labels}
+		if message, code := getMetricValue(&stored); code != http.StatusOK {
+			return nil, status.Error(grpcCode(code), message)
+		}
+		resp.Metrics = append(resp.Metrics, pb.FromMetrics(stored))
+	}
+	return resp, nil
+}
+
+// StreamUpdates stores metrics as they arrive and reports how many were
+// accepted once the client closes the stream.
+func (s *GRPCServer) StreamUpdates(stream grpc.ClientStreamingServer[pb.Metric, pb.StreamUpdatesResponse]) error {
+	agent := grpcAgentID(stream.Context())
+	var accepted int64
+	for {
+		pm, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&pb.StreamUpdatesResponse{Accepted: accepted})
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := allowGRPC(agent, 1); err != nil {
+			return err
+		}
+		metric := pm.ToMetrics()
+		if message, code := validateMetric(metric); code != http.StatusOK {
+			return status.Errorf(grpcCode(code), "metric %d: %s", accepted, message)
+		}
+		if err := admitSeries(agent, metric.MType, metric.SeriesName()); err != nil {
+			return status.Errorf(grpcCode(admissionStatus(err)), "metric %d: %s", accepted, err)
+		}
+		if err := addMetricToStorage(&metric); err != nil {
+			return status.Errorf(grpcCode(admissionStatus(err)), "metric %d: %s", accepted, err)
+		}
+		accepted++
+	}
+}
+
+func (s *GRPCServer) GetMetric(ctx context.Context, req *pb.GetMetricRequest) (*pb.Metric, error) {
+	metric := metrics.Metrics{ID: req.GetId(), MType: req.GetType(), Labels: req.GetLabels()}
+	if message, code := getMetricValue(&metric); code != http.StatusOK {
+		return nil, status.Error(grpcCode(code), message)
+	}
+	return pb.FromMetrics(metric), nil
+}
+
+// ListMetrics takes the filters of the JSON listing, see ListJSONValueHandler.
+func (s *GRPCServer) ListMetrics(ctx context.Context, req *pb.ListMetricsRequest) (*pb.ListMetricsResponse, error) {
+	query := url.Values{}
+	if len(req.GetTypes()) != 0 {
+		query.Set("type", strings.Join(req.GetTypes(), ","))
+	}
+	query.Set("prefix", req.GetPrefix())
+	query.Set("regex", req.GetRegex())
+	query["label"] = req.GetLabels()
+	query.Set("sort", req.GetSort())
+	query.Set("order", req.GetOrder())
+	query.Set("limit", strconv.Itoa(int(req.GetLimit())))
+	query.Set("offset", strconv.Itoa(int(req.GetOffset())))
+
+	opts, message, code := parseListOptions(query)
+	if code != http.StatusOK {
+		return nil, status.Error(grpcCode(code), message)
+	}
+
+	list := listMetrics(&log.Logger, opts)
+	resp := &pb.ListMetricsResponse{Total: int32(len(list))}
+	for _, metric := range paginate(list, opts.offset, opts.limit) {
+		resp.Metrics = append(resp.Metrics, pb.FromMetrics(metric))
+	}
+	return resp, nil
+}
+
+// ListenAndServeGRPC starts the gRPC server in a background goroutine.
+func ListenAndServeGRPC(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	srv := grpc.NewServer()
+	pb.RegisterMetricsServer(srv, &GRPCServer{})
+	go func() {
+		if err := srv.Serve(ln); err != nil {
+			log.Error().Err(err).Msg("grpc server stopped")
+		}
+	}()
+	return nil
+}
+
+// grpcAgentID identifies the agent of a call by the metadata named after
+// AgentIDHeader, or by the address of the peer.
+func grpcAgentID(ctx context.Context) string {
+	if ids := metadata.ValueFromIncomingContext(ctx, strings.ToLower(AgentIDHeader)); len(ids) > 0 && ids[0] != "" {
+		return ids[0]
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return host
+		}
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// allowGRPC is WithQuota for the gRPC updates, every metric of UpdateMetrics
+// and every streamed metric takes an update.
+func allowGRPC(agent string, n int) error {
+	if err := allowUpdates(agent, n); err != nil {
+		return status.Error(grpcCode(admissionStatus(err)), err.Error())
+	}
+	return nil
+}
+
+// grpcCode maps the HTTP statuses returned by the storage helpers.
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		return codes.InvalidArgument
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	}
+	return codes.Internal
+}
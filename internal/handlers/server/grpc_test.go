@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/a-palonskaa/metrics-server/internal/proto"
+)
+
+func newGRPCClient(t *testing.T) pb.MetricsClient {
+	ln := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	pb.RegisterMetricsServer(srv, &GRPCServer{})
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return ln.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewMetricsClient(conn)
+}
+
+func TestGRPCServer(t *testing.T) {
+	client := newGRPCClient(t)
+	ctx := context.Background()
+
+	delta := int64(3)
+	value := 1.5
+	resp, err := client.UpdateMetrics(ctx, &pb.UpdateMetricsRequest{Metrics: []*pb.Metric{
+		{Id: "grpc_requests", Type: "counter", Delta: &delta, Labels: map[string]string{"host": "a"}},
+		{Id: "grpc_requests", Type: "counter", Delta: &delta, Labels: map[string]string{"host": "a"}},
+		{Id: "grpc_load", Type: "gauge", Value: &value},
+		{Id: "grpc_latency", Type: "histogram", Histogram: &pb.Histogram{
+			Bounds: []float64{1}, Counts: []uint64{1, 1}, Sum: 2.5, Count: 2,
+		}},
+	}})
+	require.NoError(t, err)
+	require.Len(t, resp.GetMetrics(), 4)
+	assert.Equal(t, int64(6), resp.GetMetrics()[1].GetDelta())
+
+	_, err = client.UpdateMetrics(ctx, &pb.UpdateMetricsRequest{Metrics: []*pb.Metric{
+		{Id: "grpc_load", Type: "gauge", Value: &value},
+		{Id: "grpc_bad", Type: "gauge"},
+	}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.UpdateMetrics(ctx, &pb.UpdateMetricsRequest{Metrics: []*pb.Metric{
+		{Id: "grpc_applied", Type: "counter", Delta: &delta},
+		{Id: "grpc_latency", Type: "histogram", Histogram: &pb.Histogram{
+			Bounds: []float64{1, 2}, Counts: []uint64{1, 0, 0}, Sum: 0.5, Count: 1,
+		}},
+	}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "histogram bounds mismatch, 1 metrics before it were applied")
+
+	stream, err := client.StreamUpdates(ctx)
+	require.NoError(t, err)
+	for range 5 {
+		require.NoError(t, stream.Send(&pb.Metric{Id: "grpc_streamed", Type: "counter", Delta: &delta}))
+	}
+	streamResp, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), streamResp.GetAccepted())
+
+	metric, err := client.GetMetric(ctx, &pb.GetMetricRequest{Id: "grpc_streamed", Type: "counter"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(15), metric.GetDelta())
+
+	_, err = client.GetMetric(ctx, &pb.GetMetricRequest{Id: "grpc_missing", Type: "gauge"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	list, err := client.ListMetrics(ctx, &pb.ListMetricsRequest{
+		Types:  []string{"counter"},
+		Prefix: "grpc_",
+		Labels: []string{"host=a"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), list.GetTotal())
+	assert.Equal(t, "grpc_requests", list.GetMetrics()[0].GetId())
+
+	_, err = client.ListMetrics(ctx, &pb.ListMetricsRequest{Sort: "size"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+const (
+	checkOK      = "ok"
+	checkFailed  = "failed"
+	checkSkipped = "skipped"
+)
+
+// PingTimeout bounds the wait for a storage locked by a stuck update.
+var PingTimeout = 2 * time.Second
+
+//easyjson:json
+type healthCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+//easyjson:json
+type healthResponse struct {
+	Status string        `json:"status"`
+	Checks []healthCheck `json:"checks,omitempty"`
+}
+
+func newCheck(name string, err error) healthCheck {
+	if err != nil {
+		return healthCheck{Name: name, Status: checkFailed, Message: err.Error()}
+	}
+	return healthCheck{Name: name, Status: checkOK}
+}
+
+// storagePing is a run of memstorage.Ping, err is set before done is closed.
+type storagePing struct {
+	done chan struct{}
+	err  error
+}
+
+var (
+	pingMu sync.Mutex
+	// inflightPing is the ping still running, nil if there is none
+	inflightPing *storagePing
+)
+
+// pingStorage runs memstorage.Ping, giving up after PingTimeout. A ping stuck
+// on a locked storage is shared by the later checks instead of starting one
+// more goroutine per request.
+func pingStorage() error {
+	pingMu.Lock()
+	p := inflightPing
+	if p == nil {
+		p = &storagePing{done: make(chan struct{})}
+		inflightPing = p
+		go func() {
+			p.err = memstorage.Ping()
+			pingMu.Lock()
+			inflightPing = nil
+			pingMu.Unlock()
+			close(p.done)
+		}()
+	}
+	pingMu.Unlock()
+
+	select {
+	case <-p.done:
+		return p.err
+	case <-time.After(PingTimeout):
+		return errors.New("storage did not answer in time")
+	}
+}
+
+// writeHealth responds 200 if no check failed and 503 otherwise.
+func writeHealth(w http.ResponseWriter, req *http.Request, checks []healthCheck) {
+	resp := healthResponse{Status: checkOK, Checks: checks}
+	status := http.StatusOK
+	for _, check := range checks {
+		if check.Status == checkFailed {
+			resp.Status = checkFailed
+			status = http.StatusServiceUnavailable
+		}
+	}
+	writeJSON(w, req, status, resp.MarshalJSON)
+}
+
+// HealthzHandler answers as long as the process serves requests.
+func HealthzHandler(w http.ResponseWriter, req *http.Request) {
+	writeHealth(w, req, nil)
+}
+
+// ReadyzHandler checks that the storage answers and that the last snapshot
+// was written. The storage is restored before the server starts listening, so
+// there is no restore to wait for. The server has no database, its check is
+// always skipped.
+func ReadyzHandler(w http.ResponseWriter, req *http.Request) {
+	writeHealth(w, req, []healthCheck{
+		newCheck("storage", pingStorage()),
+		newCheck("snapshot", memstorage.SnapshotError()),
+		{Name: "database", Status: checkSkipped, Message: "no database is configured"},
+	})
+}
+
+// PingHandler checks the connectivity of the storage backend, the in-memory
+// storage and its file.
+func PingHandler(w http.ResponseWriter, req *http.Request) {
+	writeHealth(w, req, []healthCheck{newCheck("storage", pingStorage())})
+}
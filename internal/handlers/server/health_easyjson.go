@@ -0,0 +1,202 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package server
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson53c2c5caDecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(in *jlexer.Lexer, out *healthResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "status":
+			out.Status = string(in.String())
+		case "checks":
+			if in.IsNull() {
+				in.Skip()
+				out.Checks = nil
+			} else {
+				in.Delim('[')
+				if out.Checks == nil {
+					if !in.IsDelim(']') {
+						out.Checks = make([]healthCheck, 0, 1)
+					} else {
+						out.Checks = []healthCheck{}
+					}
+				} else {
+					out.Checks = (out.Checks)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 healthCheck
+					(v1).UnmarshalEasyJSON(in)
+					out.Checks = append(out.Checks, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson53c2c5caEncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(out *jwriter.Writer, in healthResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Status))
+	}
+	if len(in.Checks) != 0 {
+		const prefix string = ",\"checks\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.Checks {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				(v3).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v healthResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson53c2c5caEncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v healthResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson53c2c5caEncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *healthResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson53c2c5caDecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *healthResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson53c2c5caDecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(l, v)
+}
+func easyjson53c2c5caDecodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(in *jlexer.Lexer, out *healthCheck) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "name":
+			out.Name = string(in.String())
+		case "status":
+			out.Status = string(in.String())
+		case "message":
+			out.Message = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson53c2c5caEncodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(out *jwriter.Writer, in healthCheck) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix)
+		out.String(string(in.Status))
+	}
+	if in.Message != "" {
+		const prefix string = ",\"message\":"
+		out.RawString(prefix)
+		out.String(string(in.Message))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v healthCheck) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson53c2c5caEncodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v healthCheck) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson53c2c5caEncodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *healthCheck) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson53c2c5caDecodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *healthCheck) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson53c2c5caDecodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(l, v)
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+func TestHealthHandlers(t *testing.T) {
+	r := chi.NewRouter()
+	RouteRequests(r)
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "storage.json"))
+	require.NoError(t, err)
+	defer file.Close()
+	require.NoError(t, memstorage.WriteMetricsStorage(file))
+
+	get := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		return w
+	}
+
+	w := get("/healthz")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+
+	w = get("/ping")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok","checks":[{"name":"storage","status":"ok"}]}`, w.Body.String())
+
+	w = get("/readyz")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok","checks":[
+		{"name":"storage","status":"ok"},
+		{"name":"snapshot","status":"ok"},
+		{"name":"database","status":"skipped","message":"no database is configured"}
+	]}`, w.Body.String())
+
+	// the storage file is gone
+	require.NoError(t, os.Remove(file.Name()))
+	w = get("/ping")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"failed"`)
+	assert.Equal(t, http.StatusServiceUnavailable, get("/readyz").Code)
+}
@@ -0,0 +1,272 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a-palonskaa/metrics-server/internal/lineprotocol"
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+// InfluxCounterFields selects the integer fields stored as counters, matched
+// against the "<measurement>_<field>" metric name. The fields are cumulative:
+// the counter grows by the difference from the previous value of the series,
+// the first value only sets the base and a decrease is a reset. Other integer
+// fields, as well as float and boolean ones, are stored as gauges.
+var InfluxCounterFields *regexp.Regexp
+
+func SetInfluxCounterFields(re *regexp.Regexp) {
+	InfluxCounterFields = re
+}
+
+// InfluxStateIdleTimeout is how long the last value of a counter field is
+// remembered without a new one. The next value of a forgotten series only
+// sets the base again.
+var InfluxStateIdleTimeout = time.Hour
+
+// influxState remembers the last value of every counter field series.
+type influxState struct {
+	mu        sync.Mutex
+	last      map[string]influxCounter
+	lastSweep time.Time
+	// now is replaced in tests
+	now func() time.Time
+}
+
+type influxCounter struct {
+	value    int64
+	lastSeen time.Time
+}
+
+var influxCumulative = newInfluxState()
+
+func newInfluxState() *influxState {
+	return &influxState{
+		last: make(map[string]influxCounter),
+		now:  time.Now,
+	}
+}
+
+// addCounter stores the increase of the cumulative value cur of series since
+// its previous value. A rejected value does not become the base of the next
+// increase.
+func (s *influxState) addCounter(storage *memstorage.MetricsStorage, series string, cur int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(storage, now)
+
+	var delta int64
+	if prev, ok := s.last[series]; ok {
+		delta = cur
+		if cur >= prev.value {
+			delta -= prev.value
+		}
+	}
+	if err := storage.AddCounter(series, metrics.Counter(delta)); err != nil {
+		return err
+	}
+	s.last[series] = influxCounter{value: cur, lastSeen: now}
+	return nil
+}
+
+// sweep forgets the series idle for InfluxStateIdleTimeout and the ones the
+// storage no longer holds. It runs at most once per InfluxStateIdleTimeout and
+// is called with mu held.
+func (s *influxState) sweep(storage *memstorage.MetricsStorage, now time.Time) {
+	if now.Sub(s.lastSweep) < InfluxStateIdleTimeout {
+		return
+	}
+	for series, c := range s.last {
+		if now.Sub(c.lastSeen) >= InfluxStateIdleTimeout || !storage.IsCounterAllowed(series) {
+			delete(s.last, series)
+		}
+	}
+	s.lastSweep = now
+}
+
+// InfluxTimestampTolerance is how far the timestamp of a line may be from the
+// time the server receives it. Points are stored at the time they are
+// received, so lines with timestamps further away are rejected rather than
+// stored at the wrong time.
+var InfluxTimestampTolerance = time.Minute
+
+//easyjson:json
+type lineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+//easyjson:json
+type writeResponse struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Errors  []lineError `json:"errors"`
+}
+
+// InfluxWriteHandler accepts InfluxDB line protocol writes. Valid lines are
+// stored even if some others fail, in which case the failed lines are listed
+// in a 400 response, or a 429 one if a line is above the series or rate limits
+// and a 413 one if a line has more fields than the burst of the agent.
+// Every field of a line takes an update from the quota of the agent.
+//
+// Points are stored at the time they are received, the storage keeps no
+// timestamps of its own. A line may carry a timestamp only if it is within
+// InfluxTimestampTolerance of that time, backfilled lines are rejected.
+func InfluxWriteHandler(w http.ResponseWriter, req *http.Request) {
+	precision, err := lineprotocol.Precision(req.URL.Query().Get("precision"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		requestLogger(req).Error().Err(err).Msg("Error Reading body")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	agent := agentID(req)
+	received := time.Now()
+	var errs []lineError
+	respStatus := http.StatusBadRequest
+	total := 0
+	for i, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		total++
+
+		point, err := lineprotocol.Parse(line, precision)
+		if err == nil {
+			err = checkTimestamp(point.Time, received)
+		}
+		if err == nil {
+			err = addPointToStorage(agent, point)
+		}
+		if err != nil {
+			errs = append(errs, lineError{Line: i + 1, Error: err.Error()})
+			switch admissionStatus(err) {
+			case http.StatusTooManyRequests:
+				respStatus = http.StatusTooManyRequests
+			case http.StatusRequestEntityTooLarge:
+				if respStatus != http.StatusTooManyRequests {
+					respStatus = http.StatusRequestEntityTooLarge
+				}
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	resp, err := writeResponse{
+		Code:    "invalid",
+		Message: fmt.Sprintf("partial write: %d of %d lines failed", len(errs), total),
+		Errors:  errs,
+	}.MarshalJSON()
+	if err != nil {
+		requestLogger(req).Error().Err(err).Msg("failed to marshal write response")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if respStatus == http.StatusTooManyRequests {
+		setRetryAfter(w)
+	}
+	w.WriteHeader(respStatus)
+	if _, err := w.Write(resp); err != nil {
+		requestLogger(req).Error().Err(err).Msg("error writing response")
+	}
+}
+
+// checkTimestamp rejects a point timestamp that is not the receive time
+// within InfluxTimestampTolerance, a zero one is the receive time.
+func checkTimestamp(ts time.Time, received time.Time) error {
+	if ts.IsZero() {
+		return nil
+	}
+	if skew := received.Sub(ts).Abs(); skew > InfluxTimestampTolerance {
+		return fmt.Errorf("timestamp %s is %s away from the server time, only points within %s are accepted",
+			ts.UTC().Format(time.RFC3339), skew.Round(time.Second), InfluxTimestampTolerance)
+	}
+	return nil
+}
+
+// addPointToStorage stores every field of the point as a separate metric
+// named "<measurement>_<field>" labelled with the point tags. Nothing is
+// stored if any field can not be converted or admitted.
+func addPointToStorage(agent string, point lineprotocol.Point) error {
+	type update struct {
+		series  string
+		counter bool
+		gauge   metrics.Gauge
+		value   int64
+	}
+
+	updates := make([]update, 0, len(point.Fields))
+	for field, val := range point.Fields {
+		name := point.Measurement + "_" + field
+		u := update{series: metrics.SeriesName(name, point.Tags)}
+
+		switch val.Kind {
+		case lineprotocol.Float:
+			u.gauge = metrics.Gauge(val.Float)
+		case lineprotocol.Integer:
+			u.gauge, u.value = metrics.Gauge(val.Int), val.Int
+			u.counter = InfluxCounterFields != nil && InfluxCounterFields.MatchString(name)
+		case lineprotocol.Unsigned:
+			if val.Uint > math.MaxInt64 {
+				return fmt.Errorf("field %q overflows int64", field)
+			}
+			u.gauge, u.value = metrics.Gauge(val.Uint), int64(val.Uint)
+			u.counter = InfluxCounterFields != nil && InfluxCounterFields.MatchString(name)
+		case lineprotocol.Boolean:
+			if val.Bool {
+				u.gauge = 1
+			}
+		case lineprotocol.String:
+			return fmt.Errorf("string field %q is not supported", field)
+		}
+		updates = append(updates, u)
+	}
+
+	if err := allowUpdates(agent, len(updates)); err != nil {
+		return err
+	}
+	for _, u := range updates {
+		mType := metrics.GaugeName
+		if u.counter {
+			mType = metrics.CounterName
+		}
+		if err := admitSeries(agent, mType, u.series); err != nil {
+			return err
+		}
+	}
+
+	for _, u := range updates {
+		var err error
+		if u.counter {
+			err = influxCumulative.addCounter(memstorage.MS, u.series, u.value)
+		} else {
+			err = memstorage.MS.AddGauge(u.series, u.gauge)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
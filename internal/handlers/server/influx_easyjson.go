@@ -0,0 +1,204 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package server
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson5c6d23b4DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(in *jlexer.Lexer, out *writeResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "code":
+			out.Code = string(in.String())
+		case "message":
+			out.Message = string(in.String())
+		case "errors":
+			if in.IsNull() {
+				in.Skip()
+				out.Errors = nil
+			} else {
+				in.Delim('[')
+				if out.Errors == nil {
+					if !in.IsDelim(']') {
+						out.Errors = make([]lineError, 0, 2)
+					} else {
+						out.Errors = []lineError{}
+					}
+				} else {
+					out.Errors = (out.Errors)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 lineError
+					(v1).UnmarshalEasyJSON(in)
+					out.Errors = append(out.Errors, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson5c6d23b4EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(out *jwriter.Writer, in writeResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"code\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Code))
+	}
+	{
+		const prefix string = ",\"message\":"
+		out.RawString(prefix)
+		out.String(string(in.Message))
+	}
+	{
+		const prefix string = ",\"errors\":"
+		out.RawString(prefix)
+		if in.Errors == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.Errors {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				(v3).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v writeResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson5c6d23b4EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v writeResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson5c6d23b4EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *writeResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson5c6d23b4DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *writeResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson5c6d23b4DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(l, v)
+}
+func easyjson5c6d23b4DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(in *jlexer.Lexer, out *lineError) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "line":
+			out.Line = int(in.Int())
+		case "error":
+			out.Error = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson5c6d23b4EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(out *jwriter.Writer, in lineError) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"line\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Line))
+	}
+	{
+		const prefix string = ",\"error\":"
+		out.RawString(prefix)
+		out.String(string(in.Error))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v lineError) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson5c6d23b4EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v lineError) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson5c6d23b4EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *lineError) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson5c6d23b4DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *lineError) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson5c6d23b4DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(l, v)
+}
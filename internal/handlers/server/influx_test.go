@@ -0,0 +1,138 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+func TestInfluxWriteHandler(t *testing.T) {
+	SetInfluxCounterFields(regexp.MustCompile(`_requests$`))
+	defer SetInfluxCounterFields(nil)
+
+	r := chi.NewRouter()
+	r.Use(WithCompression)
+	r.Use(WithLogging)
+
+	RouteRequests(r)
+
+	gzipped := func(s string) *bytes.Buffer {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, err := gz.Write([]byte(s))
+		require.NoError(t, err)
+		require.NoError(t, gz.Close())
+		return &buf
+	}
+
+	tests := []struct {
+		name     string
+		url      string
+		body     string
+		gzip     bool
+		code     int
+		wantBody string
+	}{
+		{
+			name: "full-write",
+			url:  "/write?precision=s",
+			body: fmt.Sprintf("influx_http,host=a requests=5i,latency=0.25 %d\n# comment\n\ninflux_http,host=a requests=8i\n", time.Now().Unix()),
+			code: http.StatusNoContent,
+		},
+		{
+			name:     "backfill",
+			url:      "/write?precision=s",
+			body:     "influx_http,host=a requests=100i 1700000000",
+			code:     http.StatusBadRequest,
+			wantBody: "timestamp 2023-11-14T22:13:20Z is",
+		},
+		{
+			name: "gzip-write",
+			url:  "/api/v2/write",
+			body: "influx_mem,host=a used=1024i",
+			gzip: true,
+			code: http.StatusNoContent,
+		},
+		{
+			name:     "partial-write",
+			url:      "/write",
+			body:     "influx_disk free=10\ninflux_disk free=\ninflux_disk msg=\"x\"",
+			code:     http.StatusBadRequest,
+			wantBody: `"message":"partial write: 2 of 3 lines failed"`,
+		},
+		{
+			name: "unknown-precision",
+			url:  "/write?precision=d",
+			body: "influx_disk free=10",
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request *http.Request
+			if tt.gzip {
+				request = httptest.NewRequest(http.MethodPost, tt.url, gzipped(tt.body))
+				request.Header.Set("Content-Encoding", "gzip")
+			} else {
+				request = httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, request)
+
+			assert.Equal(t, tt.code, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+		})
+	}
+
+	requests, ok := memstorage.MS.GetCounterValue(`influx_http_requests{host="a"}`)
+	require.True(t, ok)
+	assert.Equal(t, metrics.Counter(3), requests)
+
+	latency, ok := memstorage.MS.GetGaugeValue(`influx_http_latency{host="a"}`)
+	require.True(t, ok)
+	assert.Equal(t, metrics.Gauge(0.25), latency)
+
+	used, ok := memstorage.MS.GetGaugeValue(`influx_mem_used{host="a"}`)
+	require.True(t, ok)
+	assert.Equal(t, metrics.Gauge(1024), used)
+
+	free, ok := memstorage.MS.GetGaugeValue("influx_disk_free")
+	require.True(t, ok)
+	assert.Equal(t, metrics.Gauge(10), free)
+}
+
+func TestInfluxState_AddCounter(t *testing.T) {
+	const series = `influx_net_bytes{host="a"}`
+	storage := memstorage.NewMetricsStorage()
+	state := newInfluxState()
+
+	for _, tt := range []struct {
+		val  int64
+		want metrics.Counter
+	}{
+		{val: 100, want: 0}, // only the base
+		{val: 130, want: 30},
+		{val: 10, want: 40}, // reset by a decrease
+	} {
+		require.NoError(t, state.addCounter(storage, series, tt.val))
+
+		val, ok := storage.GetCounterValue(series)
+		require.True(t, ok)
+		assert.Equal(t, tt.want, val)
+	}
+}
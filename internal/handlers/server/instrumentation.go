@@ -0,0 +1,32 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/a-palonskaa/metrics-server/internal/selfmetrics"
+)
+
+// unmatchedRoute labels the requests that matched no route, they are left
+// with the pattern of the root mount or none if a middleware answered.
+const unmatchedRoute = "unmatched"
+
+// WithInstrumentation counts the requests and observes their latency by the
+// route pattern they matched, see selfmetrics.ObserveRequest.
+func WithInstrumentation(fn http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		responseData := &responseData{}
+		fn.ServeHTTP(&loggingResponseWriter{ResponseWriter: w, responseData: responseData}, req)
+
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(req.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" && pattern != "/*" {
+				route = pattern
+			}
+		}
+		selfmetrics.ObserveRequest(route, req.Method, responseData.statusCode(), time.Since(start))
+	})
+}
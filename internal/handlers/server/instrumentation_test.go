@@ -0,0 +1,52 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithInstrumentation(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(WithInstrumentation)
+	r.Use(WithCompression)
+	RouteRequests(r)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/update/gauge/instrumented/1", nil),
+		httptest.NewRequest(http.MethodPost, "/update/gauge/instrumented/2", nil),
+		httptest.NewRequest(http.MethodGet, "/value/gauge/not_instrumented", nil),
+		httptest.NewRequest(http.MethodGet, "/no/such/route", nil),
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/update/", strings.NewReader("not gzip"))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+
+	for _, line := range []string{
+		"# HELP metrics_server_http_requests_total HTTP requests served by route, method and status.",
+		"# TYPE metrics_server_http_requests_total counter",
+		`metrics_server_http_requests_total{method="POST",route="/update/{mType}/{name}/{value}",status="200"} 2`,
+		`metrics_server_http_requests_total{method="GET",route="/value/{mType}/{name}",status="404"} 1`,
+		`metrics_server_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`metrics_server_http_request_duration_seconds_count{method="POST",route="/update/{mType}/{name}/{value}",status="200"} 2`,
+		"# TYPE metrics_server_goroutines gauge",
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+	assert.Regexp(t, `\nmetrics_server_decompression_failures_total [1-9]`, body)
+}
@@ -0,0 +1,233 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	"github.com/a-palonskaa/metrics-server/internal/selfmetrics"
+)
+
+type listOptions struct {
+	types    map[string]bool
+	prefix   string
+	re       *regexp.Regexp
+	matchers []metrics.LabelMatcher
+	sortBy   string
+	desc     bool
+	limit    int
+	offset   int
+}
+
+// ListJSONValueHandler serves GET /value/ for clients accepting JSON. The
+// listing is filtered by the query parameters:
+//
+//	type=gauge,counter  metric types to include
+//	prefix=Heap         metric name prefix
+//	regex=^Heap.*$      metric name regular expression
+//	label=host=a        label matcher (=, !=, =~, !~), may be repeated
+//	sort=name|type|value, order=asc|desc
+//	limit=N, offset=N   pagination, the total is sent in X-Total-Count
+func ListJSONValueHandler(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	opts, message, status := parseListOptions(req.URL.Query())
+	if status != http.StatusOK {
+		http.Error(w, message, status)
+		return
+	}
+
+	list := listMetrics(requestLogger(req), opts)
+	total := len(list)
+	list = paginate(list, opts.offset, opts.limit)
+
+	resp, err := metrics.MetricsList(list).MarshalJSON()
+	if err != nil {
+		requestLogger(req).Error().Err(err).Msg("failed to marshal listing")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp); err != nil {
+		requestLogger(req).Error().Err(err).Msg("error writing response")
+	}
+}
+
+func parseListOptions(query url.Values) (listOptions, string, int) {
+	opts := listOptions{
+		prefix: query.Get("prefix"),
+		sortBy: "name",
+	}
+
+	if types := query.Get("type"); types != "" {
+		opts.types = make(map[string]bool)
+		for _, mType := range strings.Split(types, ",") {
+			if !memstorage.IsTypeAllowed(mType) {
+				return opts, "not allowed type: " + mType, http.StatusBadRequest
+			}
+			opts.types[mType] = true
+		}
+	}
+
+	if expr := query.Get("regex"); expr != "" {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return opts, "Incorrect regex: " + err.Error(), http.StatusBadRequest
+		}
+		opts.re = re
+	}
+
+	for _, s := range query["label"] {
+		matcher, err := metrics.ParseLabelMatcher(s)
+		if err != nil {
+			return opts, err.Error(), http.StatusBadRequest
+		}
+		opts.matchers = append(opts.matchers, matcher)
+	}
+
+	if sortBy := query.Get("sort"); sortBy != "" {
+		if sortBy != "name" && sortBy != "type" && sortBy != "value" {
+			return opts, "Incorrect sort field: " + sortBy, http.StatusBadRequest
+		}
+		opts.sortBy = sortBy
+	}
+
+	switch query.Get("order") {
+	case "", "asc":
+	case "desc":
+		opts.desc = true
+	default:
+		return opts, "Incorrect order: " + query.Get("order"), http.StatusBadRequest
+	}
+
+	var err error
+	if opts.limit, err = parseNonNegative(query.Get("limit")); err != nil {
+		return opts, "Incorrect limit", http.StatusBadRequest
+	}
+	if opts.offset, err = parseNonNegative(query.Get("offset")); err != nil {
+		return opts, "Incorrect offset", http.StatusBadRequest
+	}
+	return opts, "", http.StatusOK
+}
+
+func parseNonNegative(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("must be a non-negative number: %s", s)
+	}
+	return n, nil
+}
+
+// listMetrics returns the stored metrics, derived counter gauges and metrics
+// of the server itself matching opts, sorted but not paginated. The metrics
+// failing to convert are logged to logger.
+func listMetrics(logger *zerolog.Logger, opts listOptions) []metrics.Metrics {
+	memstorage.MS.Update(&runtime.MemStats{})
+
+	list := []metrics.Metrics{}
+	add := func(series string, mType string, val fmt.Stringer, stale bool) {
+		metric, err := metrics.FromValue(series, mType, val)
+		if err != nil {
+			logger.Error().Err(err).Msgf("failed to list %s", series)
+			return
+		}
+		metric.Stale = stale
+		if opts.matches(metric) {
+			list = append(list, metric)
+		}
+	}
+
+	memstorage.MS.Iterate(func(series string, mType string, val fmt.Stringer) {
+		add(series, mType, val, memstorage.MS.IsStale(mType, series))
+	})
+	for series, val := range memstorage.MS.DerivedGauges() {
+		add(series, metrics.GaugeName, val, false)
+	}
+	selfmetrics.Iterate(func(series string, mType string, val fmt.Stringer) {
+		add(series, mType, val, false)
+	})
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if opts.desc {
+			return opts.less(list[j], list[i])
+		}
+		return opts.less(list[i], list[j])
+	})
+	return list
+}
+
+func (opts listOptions) matches(metric metrics.Metrics) bool {
+	if opts.types != nil && !opts.types[metric.MType] {
+		return false
+	}
+	if !strings.HasPrefix(metric.ID, opts.prefix) {
+		return false
+	}
+	if opts.re != nil && !opts.re.MatchString(metric.ID) {
+		return false
+	}
+	for _, matcher := range opts.matchers {
+		if !matcher.Matches(metric.Labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (opts listOptions) less(a, b metrics.Metrics) bool {
+	switch opts.sortBy {
+	case "type":
+		if a.MType != b.MType {
+			return a.MType < b.MType
+		}
+	case "value":
+		if va, vb := sortValue(a), sortValue(b); va != vb {
+			return va < vb
+		}
+	}
+	return a.SeriesName() < b.SeriesName()
+}
+
+// sortValue is the number a metric is ordered by: the value of gauges and
+// counters, the number of observations of histograms and summaries and the
+// cardinality of sets.
+func sortValue(metric metrics.Metrics) float64 {
+	switch {
+	case metric.Value != nil:
+		return *metric.Value
+	case metric.Delta != nil:
+		return float64(*metric.Delta)
+	case metric.Histogram != nil:
+		return float64(metric.Histogram.Count)
+	case metric.Summary != nil:
+		return float64(metric.Summary.Count)
+	case metric.Set != nil:
+		return float64(metric.Set.Cardinality())
+	}
+	return 0
+}
+
+func paginate(list []metrics.Metrics, offset, limit int) []metrics.Metrics {
+	if offset >= len(list) {
+		return []metrics.Metrics{}
+	}
+	list = list[offset:]
+	if limit > 0 && limit < len(list) {
+		list = list[:limit]
+	}
+	return list
+}
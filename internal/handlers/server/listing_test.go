@@ -0,0 +1,174 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+func TestListJSONValueHandler(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(WithCompression)
+	r.Use(WithLogging)
+
+	RouteRequests(r)
+
+	updates := []string{
+		`{"id":"listing_load","type":"gauge","value":0.5,"labels":{"host":"a","region":"eu"}}`,
+		`{"id":"listing_load","type":"gauge","value":1.5,"labels":{"host":"b","region":"us"}}`,
+		`{"id":"listing_load","type":"gauge","value":2.5,"labels":{"host":"c","region":"eu"}}`,
+		`{"id":"listing_hits","type":"counter","delta":7}`,
+	}
+	for _, body := range updates {
+		request := httptest.NewRequest(http.MethodPost, "/update/", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, request)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		code    int
+		wantIDs []string
+		total   string
+	}{
+		{
+			name: "prefix",
+			url:  "/value/?prefix=listing_&type=gauge,counter",
+			code: http.StatusOK,
+			wantIDs: []string{
+				"listing_hits",
+				"listing_hits:increase_1m", "listing_hits:increase_5m",
+				"listing_hits:rate_1m", "listing_hits:rate_5m",
+				"listing_load", "listing_load", "listing_load",
+			},
+			total: "8",
+		},
+		{
+			name:    "counters-only",
+			url:     "/value/?prefix=listing_&type=counter",
+			code:    http.StatusOK,
+			wantIDs: []string{"listing_hits"},
+			total:   "1",
+		},
+		{
+			name:    "label-matchers",
+			url:     "/value/?prefix=listing_&label=region=eu&label=host!=a",
+			code:    http.StatusOK,
+			wantIDs: []string{"listing_load"},
+			total:   "1",
+		},
+		{
+			name:    "regex-sorted-by-value-desc",
+			url:     "/value/?regex=^listing_l.*$&sort=value&order=desc&limit=2",
+			code:    http.StatusOK,
+			wantIDs: []string{"listing_load", "listing_load"},
+			total:   "3",
+		},
+		{
+			name:    "offset-past-end",
+			url:     "/value/?prefix=listing_&offset=10",
+			code:    http.StatusOK,
+			wantIDs: []string{},
+			total:   "8",
+		},
+		{name: "incorrect-type", url: "/value/?type=meter", code: http.StatusBadRequest},
+		{name: "incorrect-regex", url: "/value/?regex=(", code: http.StatusBadRequest},
+		{name: "incorrect-matcher", url: "/value/?label=host", code: http.StatusBadRequest},
+		{name: "incorrect-sort", url: "/value/?sort=size", code: http.StatusBadRequest},
+		{name: "incorrect-limit", url: "/value/?limit=-1", code: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			request.Header.Set("Accept", "application/json")
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, request)
+
+			res := w.Result()
+			defer func() {
+				if err := res.Body.Close(); err != nil {
+					log.Printf("failed to lcose response body: %s", err)
+				}
+			}()
+
+			assert.Equal(t, tt.code, res.StatusCode)
+			if tt.code != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tt.total, res.Header.Get("X-Total-Count"))
+
+			var list metrics.MetricsList
+			require.NoError(t, list.UnmarshalJSON(w.Body.Bytes()))
+			ids := []string{}
+			for _, m := range list {
+				ids = append(ids, m.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestListJSONValueHandler_Stale(t *testing.T) {
+	r := chi.NewRouter()
+	RouteRequests(r)
+
+	rules, evictAfter := memstorage.TTLRules, memstorage.EvictAfter
+	defer memstorage.SetTTLRules(rules, evictAfter)
+	rule, err := memstorage.ParseTTLRule("gauge:^stale_old$=1ns")
+	require.NoError(t, err)
+	memstorage.SetTTLRules([]memstorage.TTLRule{rule}, time.Hour)
+
+	memstorage.MS.AddGauge("stale_old", 1)
+	memstorage.MS.AddGauge("stale_new", 1)
+	time.Sleep(time.Millisecond)
+
+	request := httptest.NewRequest(http.MethodGet, "/value/?prefix=stale_", nil)
+	request.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var list metrics.MetricsList
+	require.NoError(t, list.UnmarshalJSON(w.Body.Bytes()))
+	stale := map[string]bool{}
+	for _, m := range list {
+		stale[m.ID] = m.Stale
+	}
+	assert.Equal(t, map[string]bool{"stale_new": false, "stale_old": true}, stale)
+	assert.Contains(t, w.Body.String(), `"stale":true`)
+}
+
+func TestListJSONValueHandler_SelfMetrics(t *testing.T) {
+	r := chi.NewRouter()
+	RouteRequests(r)
+
+	request := httptest.NewRequest(http.MethodGet, "/value/?prefix=metrics_server_&type=gauge", nil)
+	request.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, request)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var list metrics.MetricsList
+	require.NoError(t, list.UnmarshalJSON(w.Body.Bytes()))
+	ids := map[string]bool{}
+	for _, m := range list {
+		ids[m.ID] = true
+	}
+	assert.True(t, ids["metrics_server_goroutines"], "listed: %v", ids)
+	assert.True(t, ids["metrics_server_memory_alloc_bytes"], "listed: %v", ids)
+}
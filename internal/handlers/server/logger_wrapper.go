@@ -1,47 +1,120 @@
 package server
 
 import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
 	"net/http"
+	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
+// RequestIDHeader carries the ID of a request, taken from the client if it
+// sent one and generated otherwise. It is returned in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen bounds the IDs accepted from clients, longer ones are
+// replaced.
+const maxRequestIDLen = 128
+
 type responseData struct {
 	status int
 	size   int
 }
 
+// statusCode returns the status sent, net/http sends 200 for handlers that
+// write no header.
+func (d *responseData) statusCode() int {
+	if d.status == 0 {
+		return http.StatusOK
+	}
+	return d.status
+}
+
 type loggingResponseWriter struct {
 	http.ResponseWriter
 	responseData *responseData
 }
 
 func (r *loggingResponseWriter) Write(b []byte) (int, error) {
+	if r.responseData.status == 0 {
+		r.responseData.status = http.StatusOK
+	}
 	size, err := r.ResponseWriter.Write(b)
-	r.responseData.size = size
+	r.responseData.size += size
 	return size, err
 }
 
+// WriteHeader records the first status only, the later ones are ignored by
+// net/http as well.
 func (r *loggingResponseWriter) WriteHeader(statusCode int) {
 	r.ResponseWriter.WriteHeader(statusCode)
-	r.responseData.status = statusCode
+	if r.responseData.status == 0 {
+		r.responseData.status = statusCode
+	}
 }
 
+// Flush and Hijack let streaming handlers use the wrapped writer.
+func (r *loggingResponseWriter) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *loggingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	r.responseData.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Error().Err(err).Msg("failed to generate request id")
+	}
+	return hex.EncodeToString(b)
+}
+
+// requestLogger returns the logger of a request carrying its ID, the global
+// logger outside of WithLogging.
+func requestLogger(req *http.Request) *zerolog.Logger {
+	if l := zerolog.Ctx(req.Context()); l.GetLevel() != zerolog.Disabled {
+		return l
+	}
+	return &log.Logger
+}
+
+// WithLogging writes an access log entry per request. The request ID is
+// attached to the logger of the request, see requestLogger.
 func WithLogging(fn http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		responseData := &responseData{
-			status: 0,
-			size:   0,
-		}
+		start := time.Now()
 
-		responseWriter := loggingResponseWriter{
-			ResponseWriter: w,
-			responseData:   responseData,
+		id := req.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > maxRequestIDLen {
+			id = newRequestID()
 		}
+		w.Header().Set(RequestIDHeader, id)
+		logger := log.With().Str("request_id", id).Logger()
+		req = req.WithContext(logger.WithContext(req.Context()))
 
-		fn.ServeHTTP(&responseWriter, req)
+		responseData := &responseData{}
+		fn.ServeHTTP(&loggingResponseWriter{ResponseWriter: w, responseData: responseData}, req)
 
-		log.Info().Str("uri", req.RequestURI).Str("method", req.Method).Msg("request")
-		log.Info().Int("status", responseData.status).Int("size", responseData.size).Msg("response")
+		logger.Info().
+			Str("method", req.Method).
+			Str("uri", req.RequestURI).
+			Int("status", responseData.statusCode()).
+			Int("bytes", responseData.size).
+			Dur("duration", time.Since(start)).
+			Str("remote", req.RemoteAddr).
+			Msg("request")
 	})
 }
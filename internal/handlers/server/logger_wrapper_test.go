@@ -0,0 +1,89 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLogging(t *testing.T) {
+	var buf bytes.Buffer
+	defer func(logger zerolog.Logger) { log.Logger = logger }(log.Logger)
+	log.Logger = zerolog.New(&buf)
+
+	handler := WithLogging(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requestLogger(req).Info().Msg("handled")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello "))
+		_, _ = w.Write([]byte("world"))
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{name: "propagated", id: "abc-123"},
+		{name: "generated"},
+		{name: "too long", id: strings.Repeat("x", maxRequestIDLen+1)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodPost, "/update/?x=1", nil)
+			req.RemoteAddr = "10.0.0.1:5000"
+			if test.id != "" {
+				req.Header.Set(RequestIDHeader, test.id)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			id := w.Header().Get(RequestIDHeader)
+			if test.name == "propagated" {
+				assert.Equal(t, test.id, id)
+			} else {
+				assert.Regexp(t, "^[0-9a-f]{32}$", id)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			require.Len(t, lines, 2)
+
+			var handled map[string]any
+			require.NoError(t, json.Unmarshal([]byte(lines[0]), &handled))
+			assert.Equal(t, id, handled["request_id"])
+
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+			assert.Equal(t, "request", entry["message"])
+			assert.Equal(t, id, entry["request_id"])
+			assert.Equal(t, http.MethodPost, entry["method"])
+			assert.Equal(t, "/update/?x=1", entry["uri"])
+			assert.EqualValues(t, http.StatusCreated, entry["status"])
+			assert.EqualValues(t, len("hello world"), entry["bytes"])
+			assert.Equal(t, "10.0.0.1:5000", entry["remote"])
+			assert.Contains(t, entry, "duration")
+		})
+	}
+}
+
+func TestWithLogging_ImplicitStatus(t *testing.T) {
+	var buf bytes.Buffer
+	defer func(logger zerolog.Logger) { log.Logger = logger }(log.Logger)
+	log.Logger = zerolog.New(&buf)
+
+	handler := WithLogging(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ping", nil))
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.EqualValues(t, http.StatusOK, entry["status"])
+}
@@ -0,0 +1,414 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+// OTLPMetricsHandler accepts OTLP/HTTP metric export requests encoded as
+// protobuf or JSON. Data points that can not be stored are counted in the
+// partial success of the response, the rest of the request is still applied.
+func OTLPMetricsHandler(w http.ResponseWriter, req *http.Request) {
+	contentType := mediaType(req)
+	if contentType != protobufContentType && contentType != jsonContentType {
+		http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		requestLogger(req).Error().Err(err).Msg("Error Reading body")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var exportReq colmetricspb.ExportMetricsServiceRequest
+	if contentType == protobufContentType {
+		err = proto.Unmarshal(body, &exportReq)
+	} else {
+		err = protojson.Unmarshal(body, &exportReq)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var exportResp colmetricspb.ExportMetricsServiceResponse
+	if rejected, errMsg := otlpCumulative.apply(memstorage.MS, agentID(req), &exportReq); rejected > 0 {
+		exportResp.PartialSuccess = &colmetricspb.ExportMetricsPartialSuccess{
+			RejectedDataPoints: rejected,
+			ErrorMessage:       errMsg,
+		}
+	}
+
+	var resp []byte
+	if contentType == protobufContentType {
+		resp, err = proto.Marshal(&exportResp)
+	} else {
+		resp, err = protojson.Marshal(&exportResp)
+	}
+	if err != nil {
+		requestLogger(req).Error().Err(err).Msg("failed to marshal export response")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp); err != nil {
+		requestLogger(req).Error().Err(err).Msg("error writing response")
+	}
+}
+
+// OTLPStateIdleTimeout is how long the last cumulative point of a series is
+// remembered without a new one. The next point of a forgotten series is
+// handled as the first point of a series is.
+var OTLPStateIdleTimeout = time.Hour
+
+// otlpState remembers the last cumulative point of every series so that
+// cumulative sums and histograms are stored as increments, the same way delta
+// points are. The first point of a series only sets the base unless the series
+// started after the state was created: an older series may already be counted
+// in the restored storage.
+type otlpState struct {
+	mu         sync.Mutex
+	sums       map[string]cumulativeSum
+	histograms map[string]cumulativeHistogram
+	started    time.Time
+	lastSweep  time.Time
+	// now is replaced in tests
+	now func() time.Time
+}
+
+type cumulativeSum struct {
+	start    uint64
+	value    float64
+	lastSeen time.Time
+}
+
+type cumulativeHistogram struct {
+	start    uint64
+	value    metrics.Histogram
+	lastSeen time.Time
+}
+
+var otlpCumulative = newOTLPState()
+
+func newOTLPState() *otlpState {
+	return &otlpState{
+		sums:       make(map[string]cumulativeSum),
+		histograms: make(map[string]cumulativeHistogram),
+		started:    time.Now(),
+		now:        time.Now,
+	}
+}
+
+// startedAfter reports whether a series with the given start time began after
+// the state was created, so its first point holds nothing stored before.
+func (s *otlpState) startedAfter(start uint64) bool {
+	return start > uint64(s.started.UnixNano())
+}
+
+// sweep forgets the series idle for OTLPStateIdleTimeout and the ones the
+// storage no longer holds, because they expired or were deleted. It runs at
+// most once per OTLPStateIdleTimeout and is called with mu held.
+func (s *otlpState) sweep(storage *memstorage.MetricsStorage, now time.Time) {
+	if now.Sub(s.lastSweep) < OTLPStateIdleTimeout {
+		return
+	}
+	for series, sum := range s.sums {
+		if now.Sub(sum.lastSeen) >= OTLPStateIdleTimeout || !storage.IsCounterAllowed(series) {
+			delete(s.sums, series)
+		}
+	}
+	for series, hist := range s.histograms {
+		if now.Sub(hist.lastSeen) >= OTLPStateIdleTimeout || !storage.IsHistogramAllowed(series) {
+			delete(s.histograms, series)
+		}
+	}
+	s.lastSweep = now
+}
+
+// apply stores every data point of the request sent by agent and returns the
+// number of rejected points together with the first rejection reason. Every
+// data point takes an update from the quota of the agent.
+func (s *otlpState) apply(storage *memstorage.MetricsStorage, agent string,
+	req *colmetricspb.ExportMetricsServiceRequest) (int64, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(storage, s.now())
+
+	var rejected int64
+	var firstErr error
+	reject := func(n int, err error) {
+		rejected += int64(n)
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	for _, rm := range req.GetResourceMetrics() {
+		resourceLabels := make(map[string]string)
+		addAttributes(resourceLabels, rm.GetResource().GetAttributes())
+
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, m := range sm.GetMetrics() {
+				if m.GetName() == "" {
+					reject(countDataPoints(m), fmt.Errorf("metric without a name"))
+					continue
+				}
+				if err := s.applyMetric(storage, agent, m, resourceLabels, reject); err != nil {
+					reject(countDataPoints(m), err)
+				}
+			}
+		}
+	}
+
+	if firstErr != nil {
+		return rejected, firstErr.Error()
+	}
+	return rejected, ""
+}
+
+func (s *otlpState) applyMetric(storage *memstorage.MetricsStorage, agent string, m *metricspb.Metric,
+	resourceLabels map[string]string, reject func(int, error)) error {
+	switch data := m.GetData().(type) {
+	case *metricspb.Metric_Gauge:
+		for _, dp := range data.Gauge.GetDataPoints() {
+			val, err := numberValue(dp)
+			if err != nil {
+				reject(1, err)
+				continue
+			}
+			series := otlpSeriesName(m.GetName(), resourceLabels, dp.GetAttributes())
+			if err := admitUpdate(agent, metrics.GaugeName, series); err != nil {
+				reject(1, fmt.Errorf("metric %q: %w", m.GetName(), err))
+				continue
+			}
+			if err := storage.AddGauge(series, metrics.Gauge(val)); err != nil {
+				reject(1, fmt.Errorf("metric %q: %w", m.GetName(), err))
+			}
+		}
+	case *metricspb.Metric_Sum:
+		for _, dp := range data.Sum.GetDataPoints() {
+			if err := s.applySum(storage, agent, m.GetName(), data.Sum, dp, resourceLabels); err != nil {
+				reject(1, err)
+			}
+		}
+	case *metricspb.Metric_Histogram:
+		for _, dp := range data.Histogram.GetDataPoints() {
+			if err := s.applyHistogram(storage, agent, m.GetName(), data.Histogram, dp, resourceLabels); err != nil {
+				reject(1, err)
+			}
+		}
+	default:
+		return fmt.Errorf("metric %q: unsupported data type %T", m.GetName(), data)
+	}
+	return nil
+}
+
+// applySum stores monotonic sums as counters and the rest as gauges.
+// Cumulative counters are reset when the start time changes or the value
+// decreases.
+func (s *otlpState) applySum(storage *memstorage.MetricsStorage, agent string, name string, sum *metricspb.Sum,
+	dp *metricspb.NumberDataPoint, resourceLabels map[string]string) error {
+	val, err := numberValue(dp)
+	if err != nil {
+		return err
+	}
+	series := otlpSeriesName(name, resourceLabels, dp.GetAttributes())
+	cumulative := sum.GetAggregationTemporality() == metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE
+
+	mType := metrics.CounterName
+	if !sum.GetIsMonotonic() {
+		mType = metrics.GaugeName
+	}
+	if err := admitUpdate(agent, mType, series); err != nil {
+		return fmt.Errorf("metric %q: %w", name, err)
+	}
+
+	if !sum.GetIsMonotonic() {
+		if cumulative {
+			err = storage.AddGauge(series, metrics.Gauge(val))
+		} else {
+			err = storage.AdjustGauge(series, metrics.Gauge(val))
+		}
+		if err != nil {
+			return fmt.Errorf("metric %q: %w", name, err)
+		}
+		return nil
+	}
+
+	if val < 0 {
+		return fmt.Errorf("metric %q: negative monotonic sum %v", name, val)
+	}
+	if !cumulative {
+		if err := storage.AddCounter(series, metrics.Counter(math.Round(val))); err != nil {
+			return fmt.Errorf("metric %q: %w", name, err)
+		}
+		return nil
+	}
+
+	delta := math.Round(val)
+	prev, ok := s.sums[series]
+	switch {
+	case !ok && !s.startedAfter(dp.GetStartTimeUnixNano()):
+		delta = 0
+	case ok && prev.start == dp.GetStartTimeUnixNano() && val >= prev.value:
+		delta -= math.Round(prev.value)
+	}
+	// a rejected point does not become the base of the next delta
+	if err := storage.AddCounter(series, metrics.Counter(delta)); err != nil {
+		return fmt.Errorf("metric %q: %w", name, err)
+	}
+	s.sums[series] = cumulativeSum{start: dp.GetStartTimeUnixNano(), value: val, lastSeen: s.now()}
+	return nil
+}
+
+func (s *otlpState) applyHistogram(storage *memstorage.MetricsStorage, agent string, name string, hist *metricspb.Histogram,
+	dp *metricspb.HistogramDataPoint, resourceLabels map[string]string) error {
+	val := metrics.Histogram{
+		Bounds: dp.GetExplicitBounds(),
+		Counts: dp.GetBucketCounts(),
+		Sum:    dp.GetSum(),
+		Count:  dp.GetCount(),
+	}
+	if len(val.Bounds) == 0 && len(val.Counts) == 0 {
+		val.Counts = []uint64{val.Count}
+	}
+	if err := val.Validate(); err != nil {
+		return fmt.Errorf("metric %q: %w", name, err)
+	}
+
+	series := otlpSeriesName(name, resourceLabels, dp.GetAttributes())
+	if err := admitUpdate(agent, metrics.HistogramName, series); err != nil {
+		return fmt.Errorf("metric %q: %w", name, err)
+	}
+	if hist.GetAggregationTemporality() != metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE {
+		if err := storage.AddHistogram(series, val); err != nil {
+			return fmt.Errorf("metric %q: %w", name, err)
+		}
+		return nil
+	}
+
+	delta := val.Clone()
+	prev, ok := s.histograms[series]
+	switch {
+	case !ok && !s.startedAfter(dp.GetStartTimeUnixNano()):
+		delta = metrics.NewHistogram(val.Bounds)
+	case ok && prev.start == dp.GetStartTimeUnixNano():
+		if d, ok := histogramIncrease(val, prev.value); ok {
+			delta = d
+		}
+	}
+	// a rejected point does not become the base of the next delta
+	if err := storage.AddHistogram(series, delta); err != nil {
+		return fmt.Errorf("metric %q: %w", name, err)
+	}
+	s.histograms[series] = cumulativeHistogram{start: dp.GetStartTimeUnixNano(), value: val, lastSeen: s.now()}
+	return nil
+}
+
+// histogramIncrease returns cur - prev, or false if cur can not follow prev
+// because the bounds changed or a bucket decreased.
+func histogramIncrease(cur, prev metrics.Histogram) (metrics.Histogram, bool) {
+	if !cur.SameBounds(prev) || cur.Count < prev.Count {
+		return metrics.Histogram{}, false
+	}
+	d := metrics.NewHistogram(cur.Bounds)
+	for i := range cur.Counts {
+		if cur.Counts[i] < prev.Counts[i] {
+			return metrics.Histogram{}, false
+		}
+		d.Counts[i] = cur.Counts[i] - prev.Counts[i]
+	}
+	d.Sum = cur.Sum - prev.Sum
+	d.Count = cur.Count - prev.Count
+	return d, true
+}
+
+func numberValue(dp *metricspb.NumberDataPoint) (float64, error) {
+	switch v := dp.GetValue().(type) {
+	case *metricspb.NumberDataPoint_AsDouble:
+		if math.IsNaN(v.AsDouble) || math.IsInf(v.AsDouble, 0) {
+			return 0, fmt.Errorf("non-finite value %v", v.AsDouble)
+		}
+		return v.AsDouble, nil
+	case *metricspb.NumberDataPoint_AsInt:
+		return float64(v.AsInt), nil
+	}
+	return 0, fmt.Errorf("data point without a value")
+}
+
+func countDataPoints(m *metricspb.Metric) int {
+	switch data := m.GetData().(type) {
+	case *metricspb.Metric_Gauge:
+		return len(data.Gauge.GetDataPoints())
+	case *metricspb.Metric_Sum:
+		return len(data.Sum.GetDataPoints())
+	case *metricspb.Metric_Histogram:
+		return len(data.Histogram.GetDataPoints())
+	case *metricspb.Metric_ExponentialHistogram:
+		return len(data.ExponentialHistogram.GetDataPoints())
+	case *metricspb.Metric_Summary:
+		return len(data.Summary.GetDataPoints())
+	}
+	return 0
+}
+
+// otlpSeriesName labels the metric with resource and data point attributes,
+// the latter take precedence.
+func otlpSeriesName(name string, resourceLabels map[string]string, attrs []*commonpb.KeyValue) string {
+	labels := make(map[string]string, len(resourceLabels)+len(attrs))
+	for k, v := range resourceLabels {
+		labels[k] = v
+	}
+	addAttributes(labels, attrs)
+	return metrics.SeriesName(name, labels)
+}
+
+// addAttributes converts scalar attributes to labels. Attribute keys such as
+// "service.name" become "service_name".
+func addAttributes(labels map[string]string, attrs []*commonpb.KeyValue) {
+	for _, kv := range attrs {
+		var val string
+		switch v := kv.GetValue().GetValue().(type) {
+		case *commonpb.AnyValue_StringValue:
+			val = v.StringValue
+		case *commonpb.AnyValue_BoolValue:
+			val = strconv.FormatBool(v.BoolValue)
+		case *commonpb.AnyValue_IntValue:
+			val = strconv.FormatInt(v.IntValue, 10)
+		case *commonpb.AnyValue_DoubleValue:
+			val = strconv.FormatFloat(v.DoubleValue, 'f', -1, 64)
+		default:
+			continue
+		}
+		labels[labelName(kv.GetKey())] = val
+	}
+}
+
+func labelName(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, key)
+}
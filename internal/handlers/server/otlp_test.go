@@ -0,0 +1,229 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+func otlpRequest(ms ...*metricspb.Metric) *colmetricspb.ExportMetricsServiceRequest {
+	return &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{{
+				Key:   "service.name",
+				Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "api"}},
+			}}},
+			ScopeMetrics: []*metricspb.ScopeMetrics{{Metrics: ms}},
+		}},
+	}
+}
+
+func cumulativeSumMetric(name string, start uint64, val int64) *metricspb.Metric {
+	return &metricspb.Metric{
+		Name: name,
+		Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+			IsMonotonic:            true,
+			AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			DataPoints: []*metricspb.NumberDataPoint{{
+				StartTimeUnixNano: start,
+				Value:             &metricspb.NumberDataPoint_AsInt{AsInt: val},
+			}},
+		}},
+	}
+}
+
+func TestOTLPMetricsHandler(t *testing.T) {
+	r := chi.NewRouter()
+	RouteRequests(r)
+
+	post := func(contentType string, body []byte) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/v1/metrics", bytes.NewReader(body))
+		request.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, request)
+		return w
+	}
+
+	t.Run("protobuf", func(t *testing.T) {
+		body, err := proto.Marshal(otlpRequest(
+			&metricspb.Metric{
+				Name: "otlp_temperature",
+				Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{
+					DataPoints: []*metricspb.NumberDataPoint{{
+						Attributes: []*commonpb.KeyValue{{
+							Key:   "room",
+							Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: "a"}},
+						}},
+						Value: &metricspb.NumberDataPoint_AsDouble{AsDouble: 21.5},
+					}},
+				}},
+			},
+			&metricspb.Metric{
+				Name: "otlp_latency",
+				Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+					AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+					DataPoints: []*metricspb.HistogramDataPoint{{
+						ExplicitBounds: []float64{0.1, 1},
+						BucketCounts:   []uint64{1, 2, 0},
+						Count:          3,
+						Sum:            proto.Float64(1.2),
+					}},
+				}},
+			},
+			&metricspb.Metric{
+				Name: "otlp_summary",
+				Data: &metricspb.Metric_Summary{Summary: &metricspb.Summary{
+					DataPoints: []*metricspb.SummaryDataPoint{{Count: 1}},
+				}},
+			},
+		))
+		require.NoError(t, err)
+
+		w := post("application/x-protobuf", body)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp colmetricspb.ExportMetricsServiceResponse
+		require.NoError(t, proto.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, int64(1), resp.GetPartialSuccess().GetRejectedDataPoints())
+
+		gauge, ok := memstorage.MS.GetGaugeValue(`otlp_temperature{room="a",service_name="api"}`)
+		require.True(t, ok)
+		assert.Equal(t, metrics.Gauge(21.5), gauge)
+
+		hist, ok := memstorage.MS.GetHistogramValue(`otlp_latency{service_name="api"}`)
+		require.True(t, ok)
+		assert.Equal(t, []uint64{1, 2, 0}, hist.Counts)
+	})
+
+	t.Run("cumulative-json", func(t *testing.T) {
+		series := `otlp_requests{service_name="api"}`
+		for _, tt := range []struct {
+			start uint64
+			val   int64
+			want  metrics.Counter
+		}{
+			{start: 1, val: 10, want: 0}, // started before the server, only the base
+			{start: 1, val: 15, want: 5},
+			{start: 1, val: 3, want: 8},  // reset by a decrease
+			{start: 2, val: 4, want: 12}, // reset by a new start time
+		} {
+			body := fmt.Sprintf(`{"resourceMetrics":[{`+
+				`"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"api"}}]},`+
+				`"scopeMetrics":[{"metrics":[{"name":"otlp_requests","sum":{"isMonotonic":true,`+
+				`"aggregationTemporality":2,"dataPoints":[{"startTimeUnixNano":"%d","asInt":"%d"}]}}]}]}]}`,
+				tt.start, tt.val)
+
+			w := post("application/json", []byte(body))
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			val, ok := memstorage.MS.GetCounterValue(series)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, val)
+		}
+	})
+
+	t.Run("unsupported-content-type", func(t *testing.T) {
+		w := post("text/plain", []byte("x"))
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+}
+
+func TestOTLPState_CumulativeHistogram(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	state := newOTLPState()
+	start := uint64(state.started.UnixNano()) + 1
+
+	for _, counts := range [][]uint64{{1, 1}, {3, 2}} {
+		var count uint64
+		for _, c := range counts {
+			count += c
+		}
+		m := &metricspb.Metric{
+			Name: "otlp_size",
+			Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+				AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+				DataPoints: []*metricspb.HistogramDataPoint{{
+					StartTimeUnixNano: start,
+					ExplicitBounds:    []float64{10},
+					BucketCounts:      counts,
+					Count:             count,
+				}},
+			}},
+		}
+		rejected, _ := state.apply(storage, "test", otlpRequest(m))
+		require.Zero(t, rejected)
+	}
+
+	hist, ok := storage.GetHistogramValue(`otlp_size{service_name="api"}`)
+	require.True(t, ok)
+	assert.Equal(t, []uint64{3, 2}, hist.Counts)
+	assert.Equal(t, uint64(5), hist.Count)
+}
+
+func TestOTLPState_Sweep(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	state := newOTLPState()
+	start := time.Now()
+	state.now = func() time.Time { return start }
+	seriesStart := uint64(state.started.UnixNano()) + 1
+
+	rejected, _ := state.apply(storage, "test", otlpRequest(
+		cumulativeSumMetric("otlp_idle", seriesStart, 5),
+		cumulativeSumMetric("otlp_deleted", seriesStart, 5),
+		cumulativeSumMetric("otlp_active", seriesStart, 5),
+	))
+	require.Zero(t, rejected)
+	require.Len(t, state.sums, 3)
+
+	storage.DeleteSeries(func(mType, series string) bool { return series == `otlp_deleted{service_name="api"}` })
+	state.now = func() time.Time { return start.Add(OTLPStateIdleTimeout / 2) }
+	rejected, _ = state.apply(storage, "test", otlpRequest(cumulativeSumMetric("otlp_active", seriesStart, 7)))
+	require.Zero(t, rejected)
+
+	state.now = func() time.Time { return start.Add(OTLPStateIdleTimeout) }
+	rejected, _ = state.apply(storage, "test", otlpRequest())
+	require.Zero(t, rejected)
+
+	assert.Len(t, state.sums, 1)
+	assert.Contains(t, state.sums, `otlp_active{service_name="api"}`)
+	active, ok := storage.GetCounterValue(`otlp_active{service_name="api"}`)
+	require.True(t, ok)
+	assert.Equal(t, metrics.Counter(7), active)
+}
+
+func TestOTLPState_AfterRestore(t *testing.T) {
+	const series = `otlp_restored{service_name="api"}`
+	storage := memstorage.NewMetricsStorage()
+	require.NoError(t, storage.AddCounter(series, 100))
+	state := newOTLPState()
+
+	for _, tt := range []struct {
+		val  int64
+		want metrics.Counter
+	}{
+		{val: 100, want: 100}, // counted before the restart
+		{val: 104, want: 104},
+	} {
+		rejected, _ := state.apply(storage, "test", otlpRequest(cumulativeSumMetric("otlp_restored", 1, tt.val)))
+		require.Zero(t, rejected)
+
+		val, ok := storage.GetCounterValue(series)
+		require.True(t, ok)
+		assert.Equal(t, tt.want, val)
+	}
+}
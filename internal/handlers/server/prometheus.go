@@ -0,0 +1,252 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	"github.com/a-palonskaa/metrics-server/internal/selfmetrics"
+)
+
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// prometheusTypes maps the stored types to the exposed ones, sets are exposed
+// as gauges of their cardinality.
+var prometheusTypes = map[string]string{
+	metrics.GaugeName:     "gauge",
+	metrics.CounterName:   "counter",
+	metrics.HistogramName: "histogram",
+	metrics.SummaryName:   "summary",
+	metrics.SetName:       "gauge",
+}
+
+type promEntry struct {
+	name   string
+	labels map[string]string
+	mType  string
+	series string
+	val    fmt.Stringer
+}
+
+// PrometheusHandler serves the stored metrics in the Prometheus text format,
+// along with the metrics of the server itself. Names and label names are
+// sanitized, HELP lines carry the description of the schema.
+func PrometheusHandler(w http.ResponseWriter, req *http.Request) {
+	memstorage.MS.Update(&runtime.MemStats{})
+
+	var entries []promEntry
+	add := func(series string, mType string, val fmt.Stringer) {
+		name, labels, err := metrics.ParseSeriesName(series)
+		if err != nil {
+			requestLogger(req).Error().Err(err).Msgf("failed to expose %s", series)
+			return
+		}
+		entries = append(entries, promEntry{
+			name: promName(name, true), labels: labels, mType: mType, series: series, val: val,
+		})
+	}
+	memstorage.MS.Iterate(add)
+	selfmetrics.Iterate(add)
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].name != entries[j].name {
+			return entries[i].name < entries[j].name
+		}
+		if entries[i].mType != entries[j].mType {
+			return entries[i].mType < entries[j].mType
+		}
+		return entries[i].series < entries[j].series
+	})
+
+	// a sample name belongs to the first family writing it and a series is
+	// written once, so that names colliding across types or after
+	// sanitization do not produce an invalid exposition
+	owners := make(map[string]promFamily)
+	written := make(map[string]bool)
+	var sb strings.Builder
+	var prev promFamily
+	for _, e := range entries {
+		family := promFamily{name: e.name, mType: e.mType}
+		if owner, ok := promOwner(owners, e); ok {
+			requestLogger(req).Warn().Msgf("%s %s is not exposed, its name is taken by %s %s",
+				e.mType, e.series, owner.mType, owner.name)
+			continue
+		}
+		key := promSeriesKey(e.name, e.labels)
+		if written[key] {
+			requestLogger(req).Warn().Msgf("%s %s is not exposed, its sanitized name is taken", e.mType, e.series)
+			continue
+		}
+		written[key] = true
+		for _, name := range promSampleNames(e) {
+			owners[name] = family
+		}
+
+		if family != prev {
+			if help := promHelp(e); help != "" {
+				fmt.Fprintf(&sb, "# HELP %s %s\n", e.name, escapeHelp(help))
+			}
+			fmt.Fprintf(&sb, "# TYPE %s %s\n", e.name, prometheusTypes[e.mType])
+			prev = family
+		}
+		writePromEntry(&sb, e)
+	}
+
+	w.Header().Set("Content-Type", prometheusContentType)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(sb.String())); err != nil {
+		requestLogger(req).Error().Err(err).Msg("error writing response")
+	}
+}
+
+type promFamily struct {
+	name  string
+	mType string
+}
+
+// promSampleNames returns the names of the samples written for an entry.
+func promSampleNames(e promEntry) []string {
+	switch e.mType {
+	case metrics.HistogramName:
+		return []string{e.name, e.name + "_bucket", e.name + "_sum", e.name + "_count"}
+	case metrics.SummaryName:
+		return []string{e.name, e.name + "_sum", e.name + "_count"}
+	}
+	return []string{e.name}
+}
+
+// promOwner returns the family owning one of the sample names of an entry.
+func promOwner(owners map[string]promFamily, e promEntry) (promFamily, bool) {
+	for _, name := range promSampleNames(e) {
+		if owner, ok := owners[name]; ok && owner != (promFamily{name: e.name, mType: e.mType}) {
+			return owner, true
+		}
+	}
+	return promFamily{}, false
+}
+
+// promSeriesKey identifies a series by its sanitized name and labels.
+func promSeriesKey(name string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k, v := range labels {
+		keys = append(keys, promName(k, false)+`="`+escapeLabelValue(v)+`"`)
+	}
+	sort.Strings(keys)
+	return name + "{" + strings.Join(keys, ",") + "}"
+}
+
+// promHelp returns the description of the schema, or the one of a metric of
+// the server itself.
+func promHelp(e promEntry) string {
+	if m, ok := describe(e.series); ok && m.Description != "" {
+		return m.Description
+	}
+	return selfmetrics.Help(e.name)
+}
+
+func writePromEntry(sb *strings.Builder, e promEntry) {
+	switch v := e.val.(type) {
+	case metrics.Gauge:
+		writePromSample(sb, e.name, e.labels, "", "", float64(v))
+	case metrics.Counter:
+		writePromSample(sb, e.name, e.labels, "", "", float64(v))
+	case metrics.Set:
+		writePromSample(sb, e.name, e.labels, "", "", float64(v.Cardinality()))
+	case metrics.Histogram:
+		var cumulative uint64
+		for i, bound := range v.Bounds {
+			cumulative += v.Counts[i]
+			writePromSample(sb, e.name+"_bucket", e.labels, "le", formatPromFloat(bound), float64(cumulative))
+		}
+		writePromSample(sb, e.name+"_bucket", e.labels, "le", "+Inf", float64(v.Count))
+		writePromSample(sb, e.name+"_sum", e.labels, "", "", v.Sum)
+		writePromSample(sb, e.name+"_count", e.labels, "", "", float64(v.Count))
+	case metrics.Summary:
+		for _, q := range metrics.DefaultQuantiles {
+			if val, err := v.Quantile(q); err == nil {
+				writePromSample(sb, e.name, e.labels, "quantile", formatPromFloat(q), val)
+			}
+		}
+		writePromSample(sb, e.name+"_sum", e.labels, "", "", v.Sum)
+		writePromSample(sb, e.name+"_count", e.labels, "", "", float64(v.Count))
+	}
+}
+
+// writePromSample writes a sample line, extra is an additional label such as
+// le or quantile written after the series labels.
+func writePromSample(sb *strings.Builder, name string, labels map[string]string, extra, extraVal string, val float64) {
+	sb.WriteString(name)
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if extra != "" {
+		keys = append(keys, extra)
+	}
+
+	if len(keys) > 0 {
+		sb.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			v := labels[k]
+			if k == extra {
+				v = extraVal
+			}
+			sb.WriteString(promName(k, false))
+			sb.WriteString(`="`)
+			sb.WriteString(escapeLabelValue(v))
+			sb.WriteByte('"')
+		}
+		sb.WriteByte('}')
+	}
+	sb.WriteByte(' ')
+	sb.WriteString(formatPromFloat(val))
+	sb.WriteByte('\n')
+}
+
+// promName replaces the characters not allowed in metric names (colons
+// included) or label names (colons excluded) with underscores.
+func promName(name string, colons bool) string {
+	var sb strings.Builder
+	for i, r := range name {
+		valid := r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' ||
+			i > 0 && r >= '0' && r <= '9' || colons && r == ':'
+		if !valid {
+			r = '_'
+		}
+		sb.WriteRune(r)
+	}
+	if sb.Len() == 0 {
+		return "_"
+	}
+	return sb.String()
+}
+
+func escapeLabelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func formatPromFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
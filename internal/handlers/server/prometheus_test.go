@@ -0,0 +1,120 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	"github.com/a-palonskaa/metrics-server/internal/schema"
+)
+
+func TestPrometheusHandler(t *testing.T) {
+	r := chi.NewRouter()
+	RouteRequests(r)
+
+	registry, err := schema.Parse([]byte(`{"mode": "warn", "metrics": [
+		{"name": "prom_load", "type": "gauge", "description": "Load \\ average\nper host"}
+	]}`))
+	require.NoError(t, err)
+	SetSchema(registry)
+	defer SetSchema(nil)
+
+	memstorage.MS.AddGauge(`prom_load{host="b"}`, 2)
+	memstorage.MS.AddGauge(`prom_load{host="a\"x"}`, 1.5)
+	memstorage.MS.AddCounter("prom.requests", 7)
+	hist := metrics.NewHistogram([]float64{0.1, 1})
+	hist.Observe(0.05)
+	hist.Observe(0.5)
+	hist.Observe(5)
+	require.NoError(t, memstorage.MS.AddHistogram(`prom_latency{path="/"}`, hist))
+	// colliding names are exposed once
+	require.NoError(t, memstorage.MS.AddGauge("prom_requests", 1))
+	require.NoError(t, memstorage.MS.AddGauge(`prom_latency_sum{path="/"}`, 1))
+	require.NoError(t, memstorage.MS.AddGauge(`prom.temp{zone="a"}`, 20))
+	require.NoError(t, memstorage.MS.AddGauge(`prom_temp{zone="a"}`, 30))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, prometheusContentType, w.Header().Get("Content-Type"))
+
+	var lines []string
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		if strings.Contains(line, "prom_") {
+			lines = append(lines, line)
+		}
+	}
+	assert.Equal(t, []string{
+		"# TYPE prom_latency histogram",
+		`prom_latency_bucket{path="/",le="0.1"} 1`,
+		`prom_latency_bucket{path="/",le="1"} 2`,
+		`prom_latency_bucket{path="/",le="+Inf"} 3`,
+		`prom_latency_sum{path="/"} 5.55`,
+		`prom_latency_count{path="/"} 3`,
+		`# HELP prom_load Load \\ average\nper host`,
+		"# TYPE prom_load gauge",
+		`prom_load{host="a\"x"} 1.5`,
+		`prom_load{host="b"} 2`,
+		"# TYPE prom_requests counter",
+		"prom_requests 7",
+		"# TYPE prom_temp gauge",
+		`prom_temp{zone="a"} 20`,
+	}, lines)
+}
+
+func TestSchemaRejection(t *testing.T) {
+	r := chi.NewRouter()
+	RouteRequests(r)
+
+	registry, err := schema.Parse([]byte(`{"mode": "reject", "metrics": [
+		{"name": "schema_load", "type": "gauge", "unit": "percent", "labels": ["host"]}
+	]}`))
+	require.NoError(t, err)
+	memstorage.SetAdmission(registry.Check)
+	SetSchema(registry)
+	defer func() {
+		memstorage.SetAdmission(nil)
+		SetSchema(nil)
+	}()
+
+	tests := []struct {
+		name   string
+		url    string
+		body   string
+		status int
+	}{
+		{name: "declared", url: "/update/gauge/schema_load/1", status: http.StatusOK},
+		{name: "undeclared", url: "/update/gauge/schema_typo/1", status: http.StatusBadRequest},
+		{name: "wrong type", url: "/update/counter/schema_load/1", status: http.StatusBadRequest},
+		{name: "json declared", url: "/update/", body: `{"id":"schema_load","type":"gauge","value":1,"labels":{"host":"a"}}`, status: http.StatusOK},
+		{name: "json undeclared label", url: "/update/", body: `{"id":"schema_load","type":"gauge","value":1,"labels":{"dc":"eu"}}`, status: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, test.url, strings.NewReader(test.body))
+			if test.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, test.status, w.Code)
+		})
+	}
+	assert.NotContains(t, memstorage.MS.Series(metrics.GaugeName), "schema_typo")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/schema", nil))
+	assert.JSONEq(t, `[{"name":"schema_load","type":"gauge","unit":"percent","labels":["host"]}]`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/value/", nil))
+	assert.Contains(t, w.Body.String(), `<span class="unit">percent</span>`)
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	"github.com/a-palonskaa/metrics-server/internal/query"
+)
+
+var QueryEngine = query.NewEngine(memstorage.MS)
+
+//easyjson:json
+type querySeries struct {
+	Metric map[string]string `json:"metric"`
+	Value  *historyPoint     `json:"value,omitempty"`
+	Values []historyPoint    `json:"values,omitempty"`
+}
+
+//easyjson:json
+type queryResult struct {
+	ResultType query.ValueType `json:"resultType"`
+	Result     []querySeries   `json:"result"`
+}
+
+// QueryHandler evaluates an expression at a single time:
+//
+//	query=sum by (host) (rate(requests[5m]))
+//	time=1700000000 or RFC 3339, now if empty
+//
+// Vectors are returned sorted by labels, samples that are not finite numbers
+// (e.g. divisions by zero) are left out.
+func QueryHandler(w http.ResponseWriter, req *http.Request) {
+	expr, err := query.Parse(req.FormValue("query"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	t := time.Now()
+	if s := req.FormValue("time"); s != "" {
+		if t, err = parseQueryTime(s); err != nil {
+			http.Error(w, "invalid time: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	val, err := QueryEngine.Instant(expr, t)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	res := queryResult{ResultType: val.Type(), Result: []querySeries{}}
+	switch v := val.(type) {
+	case query.Scalar:
+		if finite(float64(v)) {
+			res.Result = append(res.Result, querySeries{
+				Metric: map[string]string{},
+				Value:  &historyPoint{T: t.UnixMilli(), V: float64(v)},
+			})
+		}
+	case query.Vector:
+		for _, s := range v {
+			if finite(s.Value) {
+				res.Result = append(res.Result, querySeries{
+					Metric: s.Labels,
+					Value:  &historyPoint{T: t.UnixMilli(), V: s.Value},
+				})
+			}
+		}
+		sort.Slice(res.Result, func(i, j int) bool {
+			return metrics.SeriesName("", res.Result[i].Metric) < metrics.SeriesName("", res.Result[j].Metric)
+		})
+	}
+	writeJSON(w, req, http.StatusOK, res.MarshalJSON)
+}
+
+// QueryRangeHandler evaluates an expression at every step of a range:
+//
+//	query=HeapAlloc / 1e6
+//	start, end  unix seconds or RFC 3339
+//	step=15s    duration or seconds
+func QueryRangeHandler(w http.ResponseWriter, req *http.Request) {
+	expr, err := query.Parse(req.FormValue("query"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	start, err := parseQueryTime(req.FormValue("start"))
+	if err != nil {
+		http.Error(w, "invalid start: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	end, err := parseQueryTime(req.FormValue("end"))
+	if err != nil {
+		http.Error(w, "invalid end: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	step, err := parseQueryStep(req.FormValue("step"))
+	if err != nil {
+		http.Error(w, "invalid step: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	matrix, err := QueryEngine.Range(expr, start, end, step)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	res := queryResult{ResultType: query.TypeMatrix, Result: make([]querySeries, len(matrix))}
+	for i, s := range matrix {
+		points := make([]historyPoint, len(s.Points))
+		for j, p := range s.Points {
+			points[j] = historyPoint{T: p.T.UnixMilli(), V: p.V}
+		}
+		res.Result[i] = querySeries{Metric: s.Labels, Values: points}
+	}
+	writeJSON(w, req, http.StatusOK, res.MarshalJSON)
+}
+
+func parseQueryTime(s string) (time.Time, error) {
+	if sec, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.UnixMilli(int64(sec * 1000)), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func parseQueryStep(s string) (time.Duration, error) {
+	if sec, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(sec * float64(time.Second)), nil
+	}
+	return time.ParseDuration(s)
+}
+
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
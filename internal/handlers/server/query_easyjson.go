@@ -0,0 +1,273 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package server
+
+import (
+	json "encoding/json"
+	query "github.com/a-palonskaa/metrics-server/internal/query"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson90b16446DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(in *jlexer.Lexer, out *querySeries) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "metric":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.Metric = make(map[string]string)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v1 string
+					v1 = string(in.String())
+					(out.Metric)[key] = v1
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "value":
+			if in.IsNull() {
+				in.Skip()
+				out.Value = nil
+			} else {
+				if out.Value == nil {
+					out.Value = new(historyPoint)
+				}
+				(*out.Value).UnmarshalEasyJSON(in)
+			}
+		case "values":
+			if in.IsNull() {
+				in.Skip()
+				out.Values = nil
+			} else {
+				in.Delim('[')
+				if out.Values == nil {
+					if !in.IsDelim(']') {
+						out.Values = make([]historyPoint, 0, 4)
+					} else {
+						out.Values = []historyPoint{}
+					}
+				} else {
+					out.Values = (out.Values)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v2 historyPoint
+					(v2).UnmarshalEasyJSON(in)
+					out.Values = append(out.Values, v2)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson90b16446EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(out *jwriter.Writer, in querySeries) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"metric\":"
+		out.RawString(prefix[1:])
+		if in.Metric == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v3First := true
+			for v3Name, v3Value := range in.Metric {
+				if v3First {
+					v3First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v3Name))
+				out.RawByte(':')
+				out.String(string(v3Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	if in.Value != nil {
+		const prefix string = ",\"value\":"
+		out.RawString(prefix)
+		(*in.Value).MarshalEasyJSON(out)
+	}
+	if len(in.Values) != 0 {
+		const prefix string = ",\"values\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v4, v5 := range in.Values {
+				if v4 > 0 {
+					out.RawByte(',')
+				}
+				(v5).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v querySeries) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson90b16446EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v querySeries) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson90b16446EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *querySeries) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson90b16446DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *querySeries) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson90b16446DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(l, v)
+}
+func easyjson90b16446DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(in *jlexer.Lexer, out *queryResult) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "resultType":
+			out.ResultType = query.ValueType(in.String())
+		case "result":
+			if in.IsNull() {
+				in.Skip()
+				out.Result = nil
+			} else {
+				in.Delim('[')
+				if out.Result == nil {
+					if !in.IsDelim(']') {
+						out.Result = make([]querySeries, 0, 1)
+					} else {
+						out.Result = []querySeries{}
+					}
+				} else {
+					out.Result = (out.Result)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v6 querySeries
+					(v6).UnmarshalEasyJSON(in)
+					out.Result = append(out.Result, v6)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson90b16446EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(out *jwriter.Writer, in queryResult) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"resultType\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ResultType))
+	}
+	{
+		const prefix string = ",\"result\":"
+		out.RawString(prefix)
+		if in.Result == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v7, v8 := range in.Result {
+				if v7 > 0 {
+					out.RawByte(',')
+				}
+				(v8).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v queryResult) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson90b16446EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v queryResult) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson90b16446EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *queryResult) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson90b16446DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *queryResult) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson90b16446DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(l, v)
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+func TestQueryHandlers(t *testing.T) {
+	r := chi.NewRouter()
+	RouteRequests(r)
+
+	memstorage.MS.AddGauge(`QueryLoad{host="a"}`, 2)
+	memstorage.MS.AddGauge(`QueryLoad{host="b"}`, 4)
+	now := strconv.FormatInt(time.Now().Unix()+1, 10)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		params   url.Values
+		status   int
+		resType  string
+		contains []string
+	}{
+		{
+			name:     "instant vector",
+			method:   http.MethodGet,
+			path:     "/api/query",
+			params:   url.Values{"query": {"QueryLoad * 10"}},
+			status:   http.StatusOK,
+			resType:  "vector",
+			contains: []string{`{"metric":{"host":"a"},"value":{"t":`, `"v":20}`, `"v":40}`},
+		},
+		{
+			name:     "aggregation",
+			method:   http.MethodPost,
+			path:     "/api/query",
+			params:   url.Values{"query": {"sum(QueryLoad)"}, "time": {now}},
+			status:   http.StatusOK,
+			resType:  "vector",
+			contains: []string{`"v":6}`},
+		},
+		{
+			name:     "scalar",
+			method:   http.MethodGet,
+			path:     "/api/query",
+			params:   url.Values{"query": {"1 / 4"}},
+			status:   http.StatusOK,
+			resType:  "scalar",
+			contains: []string{`"v":0.25}`},
+		},
+		{
+			name:     "division by zero",
+			method:   http.MethodGet,
+			path:     "/api/query",
+			params:   url.Values{"query": {"1 / 0"}},
+			status:   http.StatusOK,
+			resType:  "scalar",
+			contains: []string{`"result":[]`},
+		},
+		{
+			name:     "range",
+			method:   http.MethodGet,
+			path:     "/api/query_range",
+			params:   url.Values{"query": {`QueryLoad{host="b"}`}, "start": {now}, "end": {now}, "step": {"15s"}},
+			status:   http.StatusOK,
+			resType:  "matrix",
+			contains: []string{`"__name__":"QueryLoad"`, `"host":"b"`, `"values":[{"t":`},
+		},
+		{
+			name:   "parse error",
+			method: http.MethodGet,
+			path:   "/api/query",
+			params: url.Values{"query": {"sum("}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "invalid time",
+			method: http.MethodGet,
+			path:   "/api/query",
+			params: url.Values{"query": {"1"}, "time": {"yesterday"}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "missing step",
+			method: http.MethodGet,
+			path:   "/api/query_range",
+			params: url.Values{"query": {"1"}, "start": {now}, "end": {now}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "too many points",
+			method: http.MethodGet,
+			path:   "/api/query_range",
+			params: url.Values{"query": {"1"}, "start": {"0"}, "end": {now}, "step": {"1s"}},
+			status: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var req *http.Request
+			if test.method == http.MethodPost {
+				req = httptest.NewRequest(test.method, test.path, strings.NewReader(test.params.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				req = httptest.NewRequest(test.method, test.path+"?"+test.params.Encode(), nil)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, test.status, w.Code, w.Body.String())
+			if test.status != http.StatusOK {
+				return
+			}
+			assert.Contains(t, w.Body.String(), `"resultType":"`+test.resType+`"`)
+			for _, s := range test.contains {
+				assert.Contains(t, w.Body.String(), s)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	"github.com/a-palonskaa/metrics-server/internal/quota"
+	"github.com/a-palonskaa/metrics-server/internal/selfmetrics"
+)
+
+// AgentIDHeader identifies the agent sending an update, the address of the
+// client is used without it.
+const AgentIDHeader = "X-Agent-ID"
+
+// Quota limits the updates of every agent, nil if no limit is configured.
+var Quota *quota.Limiter
+
+func SetQuota(limiter *quota.Limiter) {
+	Quota = limiter
+}
+
+func agentID(req *http.Request) string {
+	if id := req.Header.Get(AgentIDHeader); id != "" {
+		return id
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// WithQuota rejects the requests of an agent above its update rate, every
+// request takes one update. It is used for the endpoints storing a single
+// metric, the others take an update per metric.
+func WithQuota(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := allowUpdates(agentID(req), 1); err != nil {
+			setRetryAfter(w)
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// allowUpdates takes n updates sent together by an agent from its quota, the
+// rejection is counted.
+func allowUpdates(agent string, n int) error {
+	if Quota == nil {
+		return nil
+	}
+	if err := Quota.AllowN(agent, n); err != nil {
+		if errors.Is(err, quota.ErrBatchTooLarge) {
+			selfmetrics.CountRejected(quota.RejectedBatch)
+		} else {
+			selfmetrics.CountRejected(quota.RejectedRate)
+		}
+		return err
+	}
+	return nil
+}
+
+// setRetryAfter tells an agent above its rate when to send again.
+func setRetryAfter(w http.ResponseWriter) {
+	if Quota != nil {
+		retry := math.Ceil(Quota.RetryAfter().Seconds())
+		w.Header().Set("Retry-After", strconv.Itoa(int(retry)))
+	}
+}
+
+// admitSeries checks that an update of a series by an agent is accepted by
+// the storage and within the quota of the agent, rejections are counted.
+func admitSeries(agent string, mType string, series string) error {
+	if err := memstorage.MS.Admit(mType, series); err != nil {
+		if errors.Is(err, memstorage.ErrSeriesLimit) {
+			selfmetrics.CountRejected(memstorage.RejectedSeriesLimit)
+		} else {
+			selfmetrics.CountRejected(memstorage.RejectedAdmission)
+		}
+		return err
+	}
+	return admitAgentSeries(agent, mType, series)
+}
+
+// admitAgentSeries checks that a series is within the quota of an agent, the
+// rejection is counted.
+func admitAgentSeries(agent string, mType string, series string) error {
+	if Quota == nil {
+		return nil
+	}
+	if err := Quota.AdmitSeries(agent, mType, series); err != nil {
+		selfmetrics.CountRejected(quota.RejectedSeries)
+		return err
+	}
+	return nil
+}
+
+// admitUpdate takes an update of a series from the quota of an agent, for the
+// endpoints that leave the admission by the storage to the storage.
+func admitUpdate(agent string, mType string, series string) error {
+	if Quota == nil {
+		return nil
+	}
+	return Quota.Admit(agent, mType, series)
+}
+
+// admissionStatus returns the status of an update rejected by admitSeries or
+// allowUpdates. A batch above the burst is not retried, it never fits.
+func admissionStatus(err error) int {
+	if errors.Is(err, memstorage.ErrSeriesLimit) || errors.Is(err, quota.ErrSeriesLimit) ||
+		errors.Is(err, quota.ErrRateLimited) {
+		return http.StatusTooManyRequests
+	}
+	if errors.Is(err, quota.ErrBatchTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	"github.com/a-palonskaa/metrics-server/internal/quota"
+)
+
+func TestQuota(t *testing.T) {
+	r := chi.NewRouter()
+	RouteRequests(r)
+
+	SetQuota(quota.NewLimiter(quota.Limits{MaxSeries: 2, Rate: 0.001, Burst: 4}))
+	defer SetQuota(nil)
+
+	tests := []struct {
+		name   string
+		agent  string
+		url    string
+		body   string
+		status int
+		reason string
+	}{
+		{name: "first series", agent: "a", url: "/update/gauge/quota_a/1", status: http.StatusOK},
+		{name: "second series", agent: "a", url: "/update/gauge/quota_b/1", status: http.StatusOK},
+		{name: "agent series limit", agent: "a", url: "/update/gauge/quota_c/1", status: http.StatusTooManyRequests, reason: "series limit of the agent"},
+		{name: "known series", agent: "a", url: "/update/gauge/quota_a/2", status: http.StatusOK},
+		{name: "agent rate limit", agent: "a", url: "/update/gauge/quota_a/3", status: http.StatusTooManyRequests, reason: "update rate limit"},
+		{name: "other agent", agent: "b", url: "/update/", body: `{"id":"quota_c","type":"gauge","value":1}`, status: http.StatusOK},
+		{name: "influx", agent: "b", url: "/write", body: "quota d=1\nquota e=1", status: http.StatusTooManyRequests, reason: "series limit of the agent"},
+		{name: "otlp", agent: "b", url: "/v1/metrics", status: http.StatusOK, reason: "series limit of the agent",
+			body: `{"resourceMetrics":[{"scopeMetrics":[{"metrics":[{"name":"quota_otlp","gauge":{"dataPoints":[{"asDouble":1}]}}]}]}]}`},
+		{name: "influx fields above burst", agent: "c", url: "/write", body: "quota f=1,g=1,h=1,i=1,j=1", status: http.StatusRequestEntityTooLarge, reason: "at most 4 are allowed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, test.url, strings.NewReader(test.body))
+			req.Header.Set(AgentIDHeader, test.agent)
+			if strings.HasPrefix(test.body, "{") {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, test.status, w.Code)
+			assert.Contains(t, w.Body.String(), test.reason)
+		})
+	}
+
+	val, _ := memstorage.MS.GetGaugeValue("quota_a")
+	assert.EqualValues(t, 2, val)
+	assert.NotContains(t, memstorage.MS.Series("gauge"), "quota_e")
+	assert.NotContains(t, memstorage.MS.Series("gauge"), "quota_otlp")
+	assert.NotContains(t, memstorage.MS.Series("gauge"), "quota_f")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, w.Body.String(), `metrics_server_rejected_updates_total{reason="agent_rate_limit"}`)
+	assert.Contains(t, w.Body.String(), `metrics_server_rejected_updates_total{reason="agent_series_limit"}`)
+	assert.Contains(t, w.Body.String(), `metrics_server_rejected_updates_total{reason="agent_batch_limit"}`)
+}
+
+func TestMaxSeries(t *testing.T) {
+	r := chi.NewRouter()
+	RouteRequests(r)
+
+	memstorage.SetMaxSeries(memstorage.MS.SeriesCount())
+	defer memstorage.SetMaxSeries(0)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/update/counter/max_series_new/1", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "series limit exceeded")
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"net/http"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	"github.com/a-palonskaa/metrics-server/internal/schema"
+)
+
+// Schema declares the known metrics, nil if no schema is configured.
+var Schema *schema.Registry
+
+func SetSchema(registry *schema.Registry) {
+	Schema = registry
+}
+
+// describe returns the declaration of the metric of a series.
+func describe(series string) (schema.Metric, bool) {
+	if Schema == nil {
+		return schema.Metric{}, false
+	}
+	name, _, err := metrics.ParseSeriesName(series)
+	if err != nil {
+		return schema.Metric{}, false
+	}
+	return Schema.Lookup(name)
+}
+
+// SchemaHandler lists the declared metrics, including the ones registered
+// automatically.
+func SchemaHandler(w http.ResponseWriter, req *http.Request) {
+	list := schema.MetricList{}
+	if Schema != nil {
+		list = Schema.Metrics()
+	}
+	writeJSON(w, req, http.StatusOK, list.MarshalJSON)
+}
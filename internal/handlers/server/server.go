@@ -3,14 +3,16 @@ package server
 import (
 	"bytes"
 	"fmt"
-	"html/template"
 	"io"
+	"math"
 	"net/http"
+	"net/url"
 	"runtime"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/rs/zerolog/log"
 
 	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
 	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
@@ -24,8 +26,29 @@ func RouteRequests(r chi.Router) {
 			r.Post("/value/", PostJSONValueHandler)
 			r.Get("/value/", AllValueHandler)
 			r.Get("/value/{mType}/{name}", GetHandler)
-			r.Post("/update/", PostJSONUpdateHandler)
-			r.Post("/update/{mType}/{name}/{value}", PostHandler)
+			r.With(WithQuota).Post("/update/", PostJSONUpdateHandler)
+			r.With(WithQuota).Post("/update/{mType}/{name}/{value}", PostHandler)
+			r.Post("/write", InfluxWriteHandler)
+			r.Post("/api/v2/write", InfluxWriteHandler)
+			r.Post("/v1/metrics", OTLPMetricsHandler)
+			r.Get("/stream", SSEHandler)
+			r.Get("/ws", WebSocketHandler)
+			r.Get("/api/history", HistoryHandler)
+			r.Get("/api/query", QueryHandler)
+			r.Post("/api/query", QueryHandler)
+			r.Get("/api/query_range", QueryRangeHandler)
+			r.Post("/api/query_range", QueryRangeHandler)
+			r.Get("/alerts", AlertsHandler)
+			r.Get("/alerts/silences", SilencesHandler)
+			r.With(withAdminAuth).Post("/alerts/silences", CreateSilenceHandler)
+			r.With(withAdminAuth).Delete("/alerts/silences/{id}", DeleteSilenceHandler)
+			r.Route("/admin", routeAdmin)
+			r.Get("/metrics", PrometheusHandler)
+			r.Get("/api/schema", SchemaHandler)
+			r.Get("/healthz", HealthzHandler)
+			r.Get("/readyz", ReadyzHandler)
+			r.Get("/ping", PingHandler)
+			r.Handle("/ui/static/*", UIStaticHandler())
 		})
 	})
 }
@@ -40,6 +63,11 @@ func PostHandler(w http.ResponseWriter, req *http.Request) {
 		http.Error(w, message, status)
 	}
 
+	if err := admitSeries(agentID(req), mType, name); err != nil {
+		http.Error(w, err.Error(), admissionStatus(err))
+		return
+	}
+
 	if message, err := addValueToStorage(mType, name, val); err != http.StatusOK {
 		http.Error(w, message, err)
 		return
@@ -48,7 +76,8 @@ func PostHandler(w http.ResponseWriter, req *http.Request) {
 }
 
 func PostJSONValueHandler(w http.ResponseWriter, req *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
+	contentType := responseContentType(req)
+	w.Header().Set("Content-Type", contentType)
 
 	if req.ContentLength == 0 {
 		w.WriteHeader(http.StatusBadRequest)
@@ -59,49 +88,60 @@ func PostJSONValueHandler(w http.ResponseWriter, req *http.Request) {
 	var buf bytes.Buffer
 	_, err := buf.ReadFrom(req.Body)
 	if err != nil {
-		log.Error().Err(err)
+		requestLogger(req).Error().Err(err).Msg("Error Reading body")
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	if err = metric.UnmarshalJSON(buf.Bytes()); err != nil {
-		log.Error().Err(err)
+	if err = decodeMetric(req, buf.Bytes(), &metric); err != nil {
+		requestLogger(req).Error().Err(err).Msg("failed to decode metric")
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
 	memstorage.MS.Update(&runtime.MemStats{})
 	if message, status := getMetricValue(&metric); status != http.StatusOK {
-		log.Error().Msg(message + req.RequestURI)
+		requestLogger(req).Error().Msg(message + req.RequestURI)
 		w.WriteHeader(status)
 		return
 	}
 
-	resp, err := metric.MarshalJSON()
+	var derived fmt.Stringer
+	if message, status := getDerivedValue(&derived, metric.MType, metric.SeriesName(), req.URL.Query()); status != http.StatusOK {
+		requestLogger(req).Error().Msg(message + req.RequestURI)
+		w.WriteHeader(status)
+		return
+	}
+	if gVal, ok := derived.(metrics.Gauge); ok {
+		fVal := float64(gVal)
+		metric.Value = &fVal
+	}
+
+	resp, err := encodeMetric(contentType, metric)
 	if err != nil {
-		log.Error().Err(err)
+		requestLogger(req).Error().Err(err).Msg("failed to encode metric")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 	if _, err := w.Write(resp); err != nil {
-		log.Error().Err(err).Msg("error writing response")
+		requestLogger(req).Error().Err(err).Msg("error writing response")
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
 
 func PostJSONUpdateHandler(w http.ResponseWriter, req *http.Request) {
-	contentType := req.Header.Get("Content-Type")
-	if contentType != "application/json" {
-		log.Error().Msg("JSON format is required")
+	if contentType := mediaType(req); contentType != jsonContentType && contentType != protobufContentType {
+		requestLogger(req).Error().Msg("JSON or protobuf format is required")
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	contentType := responseContentType(req)
+	w.Header().Set("Content-Type", contentType)
 	if req.ContentLength == 0 {
-		log.Error().Msg("Empty body")
+		requestLogger(req).Error().Msg("Empty body")
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
@@ -109,32 +149,43 @@ func PostJSONUpdateHandler(w http.ResponseWriter, req *http.Request) {
 	var metric metrics.Metrics
 	body, err := io.ReadAll(req.Body)
 	if err != nil {
-		log.Error().Err(err).Msg("Error Reading body")
+		requestLogger(req).Error().Err(err).Msg("Error Reading body")
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	if err = metric.UnmarshalJSON(body); err != nil {
-		log.Error().Err(err)
+	if err = decodeMetric(req, body, &metric); err != nil {
+		requestLogger(req).Error().Err(err).Msg("failed to decode metric")
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	if ok := addMetricToStorage(&metric); !ok {
-		log.Error().Msgf("unknown type: %s", metric.MType)
-		w.WriteHeader(http.StatusBadRequest)
+	if message, status := validateMetric(metric); status != http.StatusOK {
+		requestLogger(req).Error().Msg(message)
+		w.WriteHeader(status)
+		return
+	}
+
+	if err := admitSeries(agentID(req), metric.MType, metric.SeriesName()); err != nil {
+		http.Error(w, err.Error(), admissionStatus(err))
 		return
 	}
 
-	resp, err := metric.MarshalJSON()
+	if err := addMetricToStorage(&metric); err != nil {
+		requestLogger(req).Error().Err(err).Msg("failed to store metric")
+		http.Error(w, err.Error(), admissionStatus(err))
+		return
+	}
+
+	resp, err := encodeMetric(contentType, metric)
 	if err != nil {
-		log.Error().Err(err)
+		requestLogger(req).Error().Err(err).Msg("failed to encode metric")
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
 	if _, err := w.Write(resp); err != nil {
-		log.Error().Err(err).Msg("error writing response")
+		requestLogger(req).Error().Err(err).Msg("error writing response")
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 	w.WriteHeader(http.StatusOK)
@@ -151,47 +202,34 @@ func GetHandler(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if q := req.URL.Query().Get("quantile"); q != "" {
+		quantile, message, status := getQuantile(val, q)
+		if status != http.StatusOK {
+			http.Error(w, message, status)
+			return
+		}
+		val = quantile
+	}
+
+	if message, status := getDerivedValue(&val, mType, name, req.URL.Query()); status != http.StatusOK {
+		http.Error(w, message, status)
+		return
+	}
+
 	w.Header().Set("Content-Type", "text/plain")
 	if _, err := w.Write([]byte(val.String())); err != nil {
-		log.Error().Msgf("error writing value: %s", err)
+		requestLogger(req).Error().Msgf("error writing value: %s", err)
 	}
 }
 
 func AllValueHandler(w http.ResponseWriter, req *http.Request) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.WriteHeader(http.StatusOK)
-
-	memstorage.MS.Update(&runtime.MemStats{})
-	const tpl = `
-	<html>
-	<body>
-	    <h1>MetricsStorage</h1>
-	    <h2>Gauge MetricsStorage</h2>
-	    <table border='1' cellpadding='5' cellspacing='0'>
-	        <tr><th>Name</th><th>Value</th></tr>
-	        {{range $name := .AllowedGaugeNames}}
-	        <tr><td>{{ $name }}</td><td>{{index $.GaugeMetrics $name}}</td></tr>
-	        {{end}}
-	    </table>
-	    <h2>Counter MetricsStorage</h2>
-	    <table border='1' cellpadding='5' cellspacing='0'>
-	        <tr><th>Name</th><th>Value</th></tr>
-	        {{range $name := .AllowedCounterNames}}
-	        <tr><td>{{ $name }}</td><td>{{index $.CounterMetrics $name}}</td></tr>
-	        {{end}}
-	    </table>
-	</body>
-	</html>`
-
-	t, err := template.New("metrics").Parse(tpl)
-	if err != nil {
-		log.Fatal().Err(err)
+	if strings.Contains(req.Header.Get("Accept"), "application/json") {
+		ListJSONValueHandler(w, req)
+		return
 	}
 
-	err = t.Execute(w, memstorage.MS)
-	if err != nil {
-		log.Fatal().Err(err)
-	}
+	memstorage.MS.Update(&runtime.MemStats{})
+	renderDashboard(w, req)
 }
 
 func RootGetHandler(w http.ResponseWriter, r *http.Request) {
@@ -219,34 +257,127 @@ func validateParametrs(mType string, name string, val string) (string, int) {
 	return "", http.StatusOK
 }
 
+// validateMetric checks that a metric received in a request body carries the
+// value of its type.
+func validateMetric(metric metrics.Metrics) (string, int) {
+	if !memstorage.IsTypeAllowed(metric.MType) {
+		return "not allowed type", http.StatusBadRequest
+	}
+
+	if metric.ID == "" {
+		return "empty name", http.StatusNotFound
+	}
+
+	var err error
+	switch metric.MType {
+	case metrics.GaugeName:
+		if metric.Value == nil {
+			return "empty val", http.StatusBadRequest
+		}
+	case metrics.CounterName:
+		if metric.Delta == nil {
+			return "empty val", http.StatusBadRequest
+		}
+	case metrics.HistogramName:
+		if metric.Histogram == nil {
+			return "empty val", http.StatusBadRequest
+		}
+		err = metric.Histogram.Validate()
+	case metrics.SummaryName:
+		if metric.Summary == nil {
+			return "empty val", http.StatusBadRequest
+		}
+		err = metric.Summary.Validate()
+	case metrics.SetName:
+		if metric.Set == nil && len(metric.Members) == 0 {
+			return "empty val", http.StatusBadRequest
+		}
+		if metric.Set != nil {
+			err = metric.Set.Validate()
+		}
+	}
+	if err != nil {
+		return err.Error(), http.StatusBadRequest
+	}
+	return "", http.StatusOK
+}
+
 func addValueToStorage(mType string, name string, val string) (string, int) {
+	var err error
 	switch mType {
 	case metrics.GaugeName:
-		gaugeValue, err := strconv.ParseFloat(val, 64)
-		if err != nil {
+		gaugeValue, parseErr := strconv.ParseFloat(val, 64)
+		if parseErr != nil {
 			return "Incorrect gauge value", http.StatusBadRequest
 		}
-		memstorage.MS.AddGauge(name, metrics.Gauge(gaugeValue))
+		err = memstorage.MS.AddGauge(name, metrics.Gauge(gaugeValue))
 	case metrics.CounterName:
-		counterValue, err := strconv.Atoi(val)
-		if err != nil {
+		counterValue, parseErr := strconv.Atoi(val)
+		if parseErr != nil {
 			return "Incorrect couner value", http.StatusBadRequest
 		}
-		memstorage.MS.AddCounter(name, metrics.Counter(counterValue))
+		err = memstorage.MS.AddCounter(name, metrics.Counter(counterValue))
+	case metrics.HistogramName:
+		observation, parseErr := strconv.ParseFloat(val, 64)
+		if parseErr != nil || math.IsNaN(observation) || math.IsInf(observation, 0) {
+			return "Incorrect histogram value", http.StatusBadRequest
+		}
+		err = memstorage.MS.ObserveHistogram(name, observation)
+	case metrics.SummaryName:
+		observation, parseErr := strconv.ParseFloat(val, 64)
+		if parseErr != nil || math.IsNaN(observation) || math.IsInf(observation, 0) {
+			return "Incorrect summary value", http.StatusBadRequest
+		}
+		err = memstorage.MS.ObserveSummary(name, observation)
+	case metrics.SetName:
+		err = memstorage.MS.AddSetMembers(name, val)
+	}
+	if err != nil {
+		return err.Error(), admissionStatus(err)
 	}
 	return "", http.StatusOK
 }
 
-func addMetricToStorage(metric *metrics.Metrics) bool {
+// addMetricToStorage stores a validated metric, the error is the one the
+// storage rejected it with.
+func addMetricToStorage(metric *metrics.Metrics) error {
+	name := metric.SeriesName()
 	switch metric.MType {
-	case "gauge":
-		memstorage.MS.AddGauge(metric.ID, metrics.Gauge(*metric.Value))
-	case "counter":
-		memstorage.MS.AddCounter(metric.ID, metrics.Counter(*metric.Delta))
+	case metrics.GaugeName:
+		return memstorage.MS.AddGauge(name, metrics.Gauge(*metric.Value))
+	case metrics.CounterName:
+		return memstorage.MS.AddCounter(name, metrics.Counter(*metric.Delta))
+	case metrics.HistogramName:
+		if metric.Histogram == nil {
+			return fmt.Errorf("empty histogram %s", name)
+		}
+		if err := memstorage.MS.AddHistogram(name, *metric.Histogram); err != nil {
+			return fmt.Errorf("failed to merge histogram %s: %w", name, err)
+		}
+	case metrics.SummaryName:
+		if metric.Summary == nil {
+			return fmt.Errorf("empty summary %s", name)
+		}
+		if err := memstorage.MS.AddSummary(name, *metric.Summary); err != nil {
+			return fmt.Errorf("failed to merge summary %s: %w", name, err)
+		}
+	case metrics.SetName:
+		if metric.Set == nil && len(metric.Members) == 0 {
+			return fmt.Errorf("empty set %s", name)
+		}
+		if metric.Set != nil {
+			if err := memstorage.MS.AddSet(name, *metric.Set); err != nil {
+				return fmt.Errorf("failed to merge set %s: %w", name, err)
+			}
+		}
+		if err := memstorage.MS.AddSetMembers(name, metric.Members...); err != nil {
+			return err
+		}
+		metric.Members = nil
 	default:
-		return false
+		return fmt.Errorf("unknown type: %s", metric.MType)
 	}
-	return true
+	return nil
 }
 
 func updateValueInStorage(val *fmt.Stringer, mType string, name string) (string, int) {
@@ -264,6 +395,26 @@ func updateValueInStorage(val *fmt.Stringer, mType string, name string) (string,
 
 		memstorage.MS.Update(&runtime.MemStats{})
 		*val, _ = memstorage.MS.GetCounterValue(name)
+	case metrics.HistogramName:
+		if !memstorage.MS.IsHistogramAllowed(name) {
+			return "Incorrect histogram value", http.StatusNotFound
+		}
+
+		memstorage.MS.Update(&runtime.MemStats{})
+		*val, _ = memstorage.MS.GetHistogramValue(name)
+	case metrics.SummaryName:
+		if !memstorage.MS.IsSummaryAllowed(name) {
+			return "Incorrect summary value", http.StatusNotFound
+		}
+
+		memstorage.MS.Update(&runtime.MemStats{})
+		*val, _ = memstorage.MS.GetSummaryValue(name)
+	case metrics.SetName:
+		if !memstorage.MS.IsSetAllowed(name) {
+			return "Incorrect set value", http.StatusNotFound
+		}
+
+		*val, _ = memstorage.MS.GetSetValue(name)
 	default:
 		return "not allowed type", http.StatusBadRequest
 	}
@@ -271,23 +422,92 @@ func updateValueInStorage(val *fmt.Stringer, mType string, name string) (string,
 }
 
 func getMetricValue(metric *metrics.Metrics) (string, int) {
+	name := metric.SeriesName()
 	switch metric.MType {
 	case "gauge":
-		val, ok := memstorage.MS.GetGaugeValue(metric.ID)
+		val, ok := memstorage.MS.GetGaugeValue(name)
 		if !ok {
-			return "gauge name is not allowed:" + metric.ID, http.StatusNotFound
+			return "gauge name is not allowed:" + name, http.StatusNotFound
 		}
 		gVal := float64(val)
 		metric.Value = &gVal
 	case "counter":
-		val, ok := memstorage.MS.GetCounterValue(metric.ID)
+		val, ok := memstorage.MS.GetCounterValue(name)
 		if !ok {
-			return "counter name is not allowed:" + metric.ID, http.StatusNotFound
+			return "counter name is not allowed:" + name, http.StatusNotFound
 		}
 		cVal := int64(val)
 		metric.Delta = &cVal
+	case metrics.HistogramName:
+		val, ok := memstorage.MS.GetHistogramValue(name)
+		if !ok {
+			return "histogram name is not allowed:" + name, http.StatusNotFound
+		}
+		metric.Histogram = &val
+	case metrics.SummaryName:
+		val, ok := memstorage.MS.GetSummaryValue(name)
+		if !ok {
+			return "summary name is not allowed:" + name, http.StatusNotFound
+		}
+		metric.Summary = &val
+	case metrics.SetName:
+		val, ok := memstorage.MS.GetSetValue(name)
+		if !ok {
+			return "set name is not allowed:" + name, http.StatusNotFound
+		}
+		metric.Set = &val
 	default:
 		return "unknown type:" + metric.MType, http.StatusBadRequest
 	}
 	return "", http.StatusOK
 }
+
+// getDerivedValue replaces val with the counter rate or increase if the
+// "rate" or "increase" query parameter holds a window such as "1m".
+func getDerivedValue(val *fmt.Stringer, mType string, name string, query url.Values) (string, int) {
+	for _, fn := range []string{"rate", "increase"} {
+		param := query.Get(fn)
+		if param == "" {
+			continue
+		}
+		if mType != metrics.CounterName {
+			return fn + " is supported for counters only", http.StatusBadRequest
+		}
+
+		window, err := time.ParseDuration(param)
+		if err != nil || window <= 0 {
+			return "Incorrect window", http.StatusBadRequest
+		}
+
+		var derived metrics.Gauge
+		var ok bool
+		if fn == "rate" {
+			derived, ok = memstorage.MS.CounterRate(name, window)
+		} else {
+			derived, ok = memstorage.MS.CounterIncrease(name, window)
+		}
+		if !ok {
+			return "counter name is not allowed:" + name, http.StatusNotFound
+		}
+		*val = derived
+	}
+	return "", http.StatusOK
+}
+
+func getQuantile(val fmt.Stringer, q string) (metrics.Gauge, string, int) {
+	summary, ok := val.(metrics.Summary)
+	if !ok {
+		return 0, "quantiles are supported for summaries only", http.StatusBadRequest
+	}
+
+	quantile, err := strconv.ParseFloat(q, 64)
+	if err != nil {
+		return 0, "Incorrect quantile", http.StatusBadRequest
+	}
+
+	res, err := summary.Quantile(quantile)
+	if err != nil {
+		return 0, err.Error(), http.StatusBadRequest
+	}
+	return metrics.Gauge(res), "", http.StatusOK
+}
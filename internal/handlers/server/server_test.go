@@ -1,9 +1,11 @@
 package server
 
 import (
+	"io"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
@@ -79,6 +81,86 @@ func TestPostHandler(t *testing.T) {
 			},
 			code: http.StatusOK,
 		},
+		{
+			name: "no-val-histogram#1",
+			request: request{
+				method: http.MethodPost,
+				url:    "/update/histogram/name/fff",
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "nan-histogram",
+			request: request{
+				method: http.MethodPost,
+				url:    "/update/histogram/latency/NaN",
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "inf-histogram",
+			request: request{
+				method: http.MethodPost,
+				url:    "/update/histogram/latency/+Inf",
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "minus-inf-histogram",
+			request: request{
+				method: http.MethodPost,
+				url:    "/update/histogram/latency/-Inf",
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "working-case-histogram#1",
+			request: request{
+				method: http.MethodPost,
+				url:    "/update/histogram/latency/0.3",
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "nan-summary",
+			request: request{
+				method: http.MethodPost,
+				url:    "/update/summary/latency/NaN",
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "inf-summary",
+			request: request{
+				method: http.MethodPost,
+				url:    "/update/summary/latency/+Inf",
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "minus-inf-summary",
+			request: request{
+				method: http.MethodPost,
+				url:    "/update/summary/latency/-Inf",
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "working-case-summary#1",
+			request: request{
+				method: http.MethodPost,
+				url:    "/update/summary/latency/0.3",
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "working-case-set#1",
+			request: request{
+				method: http.MethodPost,
+				url:    "/update/set/users/alice",
+			},
+			code: http.StatusOK,
+		},
 		{
 			name: "no-name-counter#1",
 			request: request{
@@ -205,6 +287,78 @@ func TestGeneralCaseHandler(t *testing.T) {
 			},
 			code: http.StatusOK,
 		},
+		{
+			name: "working-case-histogram#1",
+			request: request{
+				method: http.MethodGet,
+				url:    "/value/histogram/GCPauseNs",
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "non-existing-name-histogram#1",
+			request: request{
+				method: http.MethodGet,
+				url:    "/value/histogram/name3",
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "working-case-summary#1",
+			request: request{
+				method: http.MethodGet,
+				url:    "/value/summary/GCPauseQuantiles",
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "incorrect-quantile-summary#1",
+			request: request{
+				method: http.MethodGet,
+				url:    "/value/summary/GCPauseQuantiles?quantile=2",
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "quantile-on-gauge#1",
+			request: request{
+				method: http.MethodGet,
+				url:    "/value/gauge/Frees?quantile=0.5",
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "non-existing-name-set#1",
+			request: request{
+				method: http.MethodGet,
+				url:    "/value/set/name4",
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name: "rate-counter#1",
+			request: request{
+				method: http.MethodGet,
+				url:    "/value/counter/PollCount?rate=1m",
+			},
+			code: http.StatusOK,
+		},
+		{
+			name: "incorrect-window-counter#1",
+			request: request{
+				method: http.MethodGet,
+				url:    "/value/counter/PollCount?increase=abc",
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "rate-on-gauge#1",
+			request: request{
+				method: http.MethodGet,
+				url:    "/value/gauge/Frees?rate=1m",
+			},
+			code: http.StatusBadRequest,
+		},
 		{
 			name: "working-incorr-name#1",
 			request: request{
@@ -286,3 +440,38 @@ func TestAllValueHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestSetJSONUpdateHandler(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(WithCompression)
+	r.Use(WithLogging)
+
+	RouteRequests(r)
+
+	bodies := []string{
+		`{"id":"visitors","type":"set","members":["10.0.0.1","10.0.0.2"]}`,
+		`{"id":"visitors","type":"set","members":["10.0.0.2","10.0.0.3"]}`,
+	}
+	for _, body := range bodies {
+		request := httptest.NewRequest(http.MethodPost, "/update/", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, request)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/value/set/visitors", nil))
+
+	res := w.Result()
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			log.Printf("failed to lcose response body: %s", err)
+		}
+	}()
+
+	data, err := io.ReadAll(res.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "3", string(data))
+}
@@ -0,0 +1,217 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/a-palonskaa/metrics-server/internal/stream"
+)
+
+var (
+	StreamHub = stream.NewHub()
+
+	// StreamHeartbeat is the interval of SSE comments and WebSocket pings
+	// sent to keep idle subscriptions alive.
+	StreamHeartbeat = 15 * time.Second
+
+	// StreamBuffer is the number of updates queued per subscriber before
+	// its overflow policy applies.
+	StreamBuffer = 256
+)
+
+const streamWriteTimeout = 10 * time.Second
+
+//easyjson:json
+type streamNotice struct {
+	Dropped uint64 `json:"dropped"`
+}
+
+var upgrader = websocket.Upgrader{}
+
+// isStreamRequest reports whether the request asks for an SSE or WebSocket
+// stream.
+func isStreamRequest(r *http.Request) bool {
+	return websocket.IsWebSocketUpgrade(r) || strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// subscribe registers a subscriber for the query parameters:
+//
+//	pattern=Heap*,GC*    metric name patterns (path.Match), may be repeated
+//	overflow=drop        drop updates for a slow client and report the count,
+//	                     or "disconnect" to close its stream instead
+func subscribe(query url.Values) (*stream.Subscriber, error) {
+	var patterns []string
+	for _, p := range query["pattern"] {
+		for _, s := range strings.Split(p, ",") {
+			if s != "" {
+				patterns = append(patterns, s)
+			}
+		}
+	}
+
+	overflow := query.Get("overflow")
+	if overflow == "" {
+		overflow = stream.OverflowDrop
+	}
+	return StreamHub.Subscribe(patterns, StreamBuffer, overflow)
+}
+
+// SSEHandler streams updates as "update" events carrying the JSON metric.
+// Dropped updates are reported with a "dropped" event.
+func SSEHandler(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	sub, err := subscribe(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer StreamHub.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(StreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+		select {
+		case <-req.Context().Done():
+			return
+		case <-sub.Done():
+			if _, err := fmt.Fprint(w, "event: error\ndata: slow consumer\n\n"); err == nil {
+				flusher.Flush()
+			}
+			return
+		case <-heartbeat.C:
+			err = writeSSEDropped(w, sub)
+			if err == nil {
+				_, err = fmt.Fprint(w, ": heartbeat\n\n")
+			}
+		case metric := <-sub.Events():
+			err = writeSSEDropped(w, sub)
+			if err == nil {
+				err = writeSSE(w, "update", metric.MarshalJSON)
+			}
+		}
+		if err != nil {
+			requestLogger(req).Error().Err(err).Msg("failed to write event stream")
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+func writeSSEDropped(w http.ResponseWriter, sub *stream.Subscriber) error {
+	if n := sub.TakeDropped(); n > 0 {
+		return writeSSE(w, "dropped", streamNotice{Dropped: n}.MarshalJSON)
+	}
+	return nil
+}
+
+func writeSSE(w http.ResponseWriter, event string, marshal func() ([]byte, error)) error {
+	data, err := marshal()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
+
+// WebSocketHandler streams updates as JSON text messages. Dropped updates are
+// reported with a {"dropped":N} message, heartbeats are ping frames.
+func WebSocketHandler(w http.ResponseWriter, req *http.Request) {
+	sub, err := subscribe(req.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer StreamHub.Unsubscribe(sub)
+
+	conn, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		requestLogger(req).Error().Err(err).Msg("websocket upgrade failed")
+		return
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			requestLogger(req).Error().Err(err).Msg("failed to close websocket")
+		}
+	}()
+
+	// the reader handles pongs and close frames, the client sends nothing else
+	closed := make(chan struct{})
+	conn.SetReadLimit(512)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * StreamHeartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * StreamHeartbeat))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(StreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+		select {
+		case <-closed:
+			return
+		case <-sub.Done():
+			msg := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "slow consumer")
+			_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(streamWriteTimeout))
+			return
+		case <-heartbeat.C:
+			err = writeWSDropped(conn, sub)
+			if err == nil {
+				err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout))
+			}
+		case metric := <-sub.Events():
+			err = writeWSDropped(conn, sub)
+			if err == nil {
+				err = writeWS(conn, metric.MarshalJSON)
+			}
+		}
+		if err != nil {
+			requestLogger(req).Error().Err(err).Msg("failed to write websocket")
+			return
+		}
+	}
+}
+
+func writeWSDropped(conn *websocket.Conn, sub *stream.Subscriber) error {
+	if n := sub.TakeDropped(); n > 0 {
+		return writeWS(conn, streamNotice{Dropped: n}.MarshalJSON)
+	}
+	return nil
+}
+
+func writeWS(conn *websocket.Conn, marshal func() ([]byte, error)) error {
+	data, err := marshal()
+	if err != nil {
+		return err
+	}
+	if err := conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+		return err
+	}
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
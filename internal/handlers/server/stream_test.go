@@ -0,0 +1,108 @@
+package server
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+func newStreamServer(t *testing.T) *httptest.Server {
+	r := chi.NewRouter()
+	r.Use(WithCompression)
+	r.Use(WithLogging)
+	RouteRequests(r)
+
+	ts := httptest.NewServer(r)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// waitSubscribed waits until the handler has subscribed, so that updates
+// made afterwards are delivered.
+func waitSubscribed(t *testing.T) {
+	require.Eventually(t, func() bool {
+		return StreamHub.Len() > 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestSSEHandler(t *testing.T) {
+	ts := newStreamServer(t)
+
+	req, err := http.NewRequest(http.MethodGet, ts.URL+"/stream?pattern=SSE*", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	waitSubscribed(t)
+	memstorage.MS.AddGauge("Other", 1)
+	memstorage.MS.AddGauge("SSEGauge", 2.5)
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 2 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	assert.Equal(t, "event: update", lines[0])
+	assert.Equal(t, `data: {"id":"SSEGauge","type":"gauge","value":2.5}`, lines[1])
+}
+
+func TestWebSocketHandler(t *testing.T) {
+	ts := newStreamServer(t)
+
+	header := http.Header{"Accept-Encoding": []string{"gzip"}}
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+ts.URL[4:]+"/ws?pattern=WSCounter", header)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	waitSubscribed(t)
+	memstorage.MS.AddCounter("WSCounter", 3)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	var metric metrics.Metrics
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.NoError(t, metric.UnmarshalJSON(data))
+	assert.Equal(t, "WSCounter", metric.ID)
+	assert.Equal(t, int64(3), *metric.Delta)
+}
+
+func TestIsStreamRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    bool
+	}{
+		{name: "sse", headers: map[string]string{"Accept": "text/event-stream"}, want: true},
+		{name: "websocket", headers: map[string]string{"Connection": "Upgrade", "Upgrade": "websocket"}, want: true},
+		{name: "h2c upgrade", headers: map[string]string{"Connection": "Upgrade", "Upgrade": "h2c"}, want: false},
+		{name: "plain", headers: map[string]string{"Accept": "application/json"}, want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/value/", nil)
+			for k, v := range test.headers {
+				req.Header.Set(k, v)
+			}
+			assert.Equal(t, test.want, isStreamRequest(req))
+		})
+	}
+}
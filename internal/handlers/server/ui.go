@@ -0,0 +1,170 @@
+package server
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strings"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	"github.com/a-palonskaa/metrics-server/internal/selfmetrics"
+)
+
+//go:embed ui
+var uiFS embed.FS
+
+// dashboard is parsed once, a broken template fails at startup instead of on
+// every request.
+var dashboard = template.Must(template.ParseFS(uiFS, "ui/index.html"))
+
+// UIStaticHandler serves the scripts and styles of the dashboard under
+// /ui/static/.
+func UIStaticHandler() http.Handler {
+	static, err := fs.Sub(uiFS, "ui/static")
+	if err != nil {
+		panic(err)
+	}
+	return http.StripPrefix("/ui/static/", http.FileServer(http.FS(static)))
+}
+
+type dashboardRow struct {
+	Series string
+	Type   string
+	Value  string
+	Stale  bool
+
+	// Description and Unit come from the schema
+	Description string
+	Unit        string
+}
+
+// renderDashboard renders the page into a buffer first, so that a failed
+// template execution results in a 500 rather than a truncated page.
+func renderDashboard(w http.ResponseWriter, req *http.Request) {
+	var rows []dashboardRow
+	memstorage.MS.Iterate(func(series string, mType string, val fmt.Stringer) {
+		row := dashboardRow{Series: series, Type: mType, Value: val.String(), Stale: memstorage.MS.IsStale(mType, series)}
+		if m, ok := describe(series); ok {
+			row.Description, row.Unit = m.Description, m.Unit
+		}
+		rows = append(rows, row)
+	})
+	for series, val := range memstorage.MS.DerivedGauges() {
+		rows = append(rows, dashboardRow{Series: series, Type: metrics.GaugeName, Value: val.String()})
+	}
+	selfmetrics.Iterate(func(series string, mType string, val fmt.Stringer) {
+		row := dashboardRow{Series: series, Type: mType, Value: val.String()}
+		if name, _, err := metrics.ParseSeriesName(series); err == nil {
+			row.Description = selfmetrics.Help(name)
+		}
+		rows = append(rows, row)
+	})
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Series != rows[j].Series {
+			return rows[i].Series < rows[j].Series
+		}
+		return rows[i].Type < rows[j].Type
+	})
+
+	var buf bytes.Buffer
+	if err := dashboard.Execute(&buf, struct{ Rows []dashboardRow }{rows}); err != nil {
+		requestLogger(req).Error().Err(err).Msg("failed to render dashboard")
+		http.Error(w, "failed to render dashboard", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		requestLogger(req).Error().Err(err).Msg("error writing response")
+	}
+}
+
+//easyjson:json
+type historyPoint struct {
+	T int64   `json:"t"` // unix milliseconds
+	V float64 `json:"v"`
+}
+
+//easyjson:json
+type historySeries struct {
+	ID     string            `json:"id"`
+	MType  string            `json:"type"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Points []historyPoint    `json:"points"`
+}
+
+//easyjson:json
+type historyList []historySeries
+
+// HistoryHandler serves the chart points of gauges, counters, histograms and
+// summaries (observation counts). The query parameters select the series:
+//
+//	type=gauge,counter  metric types, all chartable types if empty
+//	name=HeapAlloc      series name including labels, may be repeated; all
+//	                    series of the types if empty
+func HistoryHandler(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+
+	types := []string{metrics.GaugeName, metrics.CounterName, metrics.HistogramName, metrics.SummaryName}
+	if t := query.Get("type"); t != "" {
+		types = strings.Split(t, ",")
+		for _, mType := range types {
+			if !memstorage.IsTypeAllowed(mType) {
+				http.Error(w, "not allowed type: "+mType, http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	list := historyList{}
+	for _, mType := range types {
+		histories := make(map[string][]memstorage.Sample)
+		if names := query["name"]; len(names) > 0 {
+			for _, name := range names {
+				if points, ok := memstorage.MS.History(mType, name); ok {
+					histories[name] = points
+				}
+			}
+		} else {
+			histories = memstorage.MS.Histories(mType)
+		}
+
+		for series, samples := range histories {
+			id, labels, err := metrics.ParseSeriesName(series)
+			if err != nil {
+				continue
+			}
+			points := make([]historyPoint, len(samples))
+			for i, s := range samples {
+				points[i] = historyPoint{T: s.Time.UnixMilli(), V: s.Value}
+			}
+			list = append(list, historySeries{ID: id, MType: mType, Labels: labels, Points: points})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := metrics.SeriesName(list[i].ID, list[i].Labels), metrics.SeriesName(list[j].ID, list[j].Labels)
+		if a != b {
+			return a < b
+		}
+		return list[i].MType < list[j].MType
+	})
+
+	resp, err := list.MarshalJSON()
+	if err != nil {
+		requestLogger(req).Error().Err(err).Msg("failed to marshal history")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(resp); err != nil {
+		requestLogger(req).Error().Err(err).Msg("error writing response")
+	}
+}
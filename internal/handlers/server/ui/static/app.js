@@ -0,0 +1,172 @@
+"use strict";
+
+// The table is rendered by the server; refreshes fetch the same page and swap
+// the rows, so values are always formatted by the server. Charts are drawn
+// from /api/history.
+(function () {
+    const SVG_NS = "http://www.w3.org/2000/svg";
+
+    const search = document.getElementById("search");
+    const refresh = document.getElementById("refresh");
+    const status = document.getElementById("status");
+    const tbody = document.querySelector("#metrics tbody");
+    const chart = document.getElementById("chart");
+
+    let histories = new Map();
+    let selected = null;
+    let timer = null;
+
+    const key = (type, series) => type + "\u0000" + series;
+
+    function seriesName(s) {
+        const labels = Object.keys(s.labels || {}).sort()
+            .map((k) => k + "=" + JSON.stringify(s.labels[k]));
+        return labels.length ? s.id + "{" + labels.join(",") + "}" : s.id;
+    }
+
+    function setStatus(text, isError) {
+        status.textContent = text;
+        status.classList.toggle("error", Boolean(isError));
+    }
+
+    function matcher() {
+        const q = search.value.trim();
+        if (q.length > 1 && q.startsWith("/") && q.endsWith("/")) {
+            try {
+                const re = new RegExp(q.slice(1, -1));
+                return (name) => re.test(name);
+            } catch (e) {
+                setStatus("invalid regex: " + e.message, true);
+                return () => true;
+            }
+        }
+        const lower = q.toLowerCase();
+        return (name) => name.toLowerCase().includes(lower);
+    }
+
+    function applyFilter() {
+        const match = matcher();
+        for (const row of tbody.querySelectorAll("tr[data-series]")) {
+            row.hidden = !match(row.dataset.series);
+        }
+    }
+
+    function polyline(points, width, height) {
+        const line = document.createElementNS(SVG_NS, "polyline");
+        if (points.length === 0) {
+            return line;
+        }
+        const t0 = points[0].t;
+        const t1 = points[points.length - 1].t;
+        let lo = Math.min(...points.map((p) => p.v));
+        let hi = Math.max(...points.map((p) => p.v));
+        if (lo === hi) {
+            lo -= 1;
+            hi += 1;
+        }
+        const x = (t) => (t1 === t0 ? width : ((t - t0) / (t1 - t0)) * width);
+        const y = (v) => height - ((v - lo) / (hi - lo)) * height;
+        line.setAttribute("points", points.map((p) => x(p.t).toFixed(1) + "," + y(p.v).toFixed(1)).join(" "));
+        return line;
+    }
+
+    function drawSparklines() {
+        for (const row of tbody.querySelectorAll("tr[data-series]")) {
+            const cell = row.querySelector("td.spark");
+            const points = histories.get(key(row.dataset.type, row.dataset.series));
+            cell.replaceChildren();
+            if (!points || points.length < 2) {
+                continue;
+            }
+            const svg = document.createElementNS(SVG_NS, "svg");
+            svg.setAttribute("viewBox", "0 0 120 24");
+            svg.setAttribute("width", "120");
+            svg.setAttribute("height", "24");
+            svg.setAttribute("preserveAspectRatio", "none");
+            svg.appendChild(polyline(points, 120, 24));
+            cell.appendChild(svg);
+        }
+    }
+
+    function drawChart() {
+        if (!selected) {
+            chart.hidden = true;
+            return;
+        }
+        const points = histories.get(key(selected.type, selected.series)) || [];
+        document.getElementById("chart-title").textContent = selected.series + " (" + selected.type + ")";
+        document.getElementById("chart-svg").replaceChildren(polyline(points, 800, 240));
+
+        const time = (p) => (p ? new Date(p.t).toLocaleTimeString() : "");
+        document.getElementById("chart-from").textContent = time(points[0]);
+        document.getElementById("chart-to").textContent = time(points[points.length - 1]);
+        document.getElementById("chart-range").textContent = points.length
+            ? "min " + Math.min(...points.map((p) => p.v)) + " / max " + Math.max(...points.map((p) => p.v))
+            : "no history";
+        chart.hidden = false;
+    }
+
+    async function fetchRows() {
+        const resp = await fetch("/value/", { headers: { Accept: "text/html" } });
+        if (!resp.ok) {
+            throw new Error("GET /value/: " + resp.status);
+        }
+        const doc = new DOMParser().parseFromString(await resp.text(), "text/html");
+        const rows = doc.querySelector("#metrics tbody");
+        if (rows) {
+            tbody.replaceChildren(...rows.children);
+        }
+    }
+
+    async function fetchHistories() {
+        const resp = await fetch("/api/history");
+        if (!resp.ok) {
+            throw new Error("GET /api/history: " + resp.status);
+        }
+        const list = await resp.json();
+        histories = new Map(list.map((s) => [key(s.type, seriesName(s)), s.points]));
+    }
+
+    async function update() {
+        try {
+            await Promise.all([fetchRows(), fetchHistories()]);
+            applyFilter();
+            drawSparklines();
+            drawChart();
+            setStatus("updated " + new Date().toLocaleTimeString());
+        } catch (e) {
+            setStatus("update failed: " + e.message, true);
+        }
+    }
+
+    function schedule() {
+        clearInterval(timer);
+        const seconds = Number(refresh.value);
+        if (seconds > 0) {
+            timer = setInterval(() => {
+                if (!document.hidden) {
+                    update();
+                }
+            }, seconds * 1000);
+        }
+    }
+
+    search.addEventListener("input", applyFilter);
+    refresh.addEventListener("change", schedule);
+    tbody.addEventListener("click", (e) => {
+        const row = e.target.closest("tr[data-series]");
+        if (row) {
+            selected = { series: row.dataset.series, type: row.dataset.type };
+            drawChart();
+        }
+    });
+    document.getElementById("chart-close").addEventListener("click", () => {
+        selected = null;
+        drawChart();
+    });
+
+    fetchHistories()
+        .then(drawSparklines)
+        .catch((e) => setStatus("history unavailable: " + e.message, true));
+    schedule();
+})();
@@ -0,0 +1,309 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package server
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson8810f1f6DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(in *jlexer.Lexer, out *historySeries) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = string(in.String())
+		case "type":
+			out.MType = string(in.String())
+		case "labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Labels = make(map[string]string)
+				} else {
+					out.Labels = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v1 string
+					v1 = string(in.String())
+					(out.Labels)[key] = v1
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "points":
+			if in.IsNull() {
+				in.Skip()
+				out.Points = nil
+			} else {
+				in.Delim('[')
+				if out.Points == nil {
+					if !in.IsDelim(']') {
+						out.Points = make([]historyPoint, 0, 4)
+					} else {
+						out.Points = []historyPoint{}
+					}
+				} else {
+					out.Points = (out.Points)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v2 historyPoint
+					(v2).UnmarshalEasyJSON(in)
+					out.Points = append(out.Points, v2)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson8810f1f6EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(out *jwriter.Writer, in historySeries) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ID))
+	}
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix)
+		out.String(string(in.MType))
+	}
+	if len(in.Labels) != 0 {
+		const prefix string = ",\"labels\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v3First := true
+			for v3Name, v3Value := range in.Labels {
+				if v3First {
+					v3First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v3Name))
+				out.RawByte(':')
+				out.String(string(v3Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"points\":"
+		out.RawString(prefix)
+		if in.Points == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v4, v5 := range in.Points {
+				if v4 > 0 {
+					out.RawByte(',')
+				}
+				(v5).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v historySeries) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson8810f1f6EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v historySeries) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson8810f1f6EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *historySeries) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson8810f1f6DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *historySeries) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson8810f1f6DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer(l, v)
+}
+func easyjson8810f1f6DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(in *jlexer.Lexer, out *historyPoint) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "t":
+			out.T = int64(in.Int64())
+		case "v":
+			out.V = float64(in.Float64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson8810f1f6EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(out *jwriter.Writer, in historyPoint) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"t\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.T))
+	}
+	{
+		const prefix string = ",\"v\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.V))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v historyPoint) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson8810f1f6EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v historyPoint) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson8810f1f6EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *historyPoint) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson8810f1f6DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *historyPoint) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson8810f1f6DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer1(l, v)
+}
+func easyjson8810f1f6DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer2(in *jlexer.Lexer, out *historyList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(historyList, 0, 1)
+			} else {
+				*out = historyList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v6 historySeries
+			(v6).UnmarshalEasyJSON(in)
+			*out = append(*out, v6)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson8810f1f6EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer2(out *jwriter.Writer, in historyList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v7, v8 := range in {
+			if v7 > 0 {
+				out.RawByte(',')
+			}
+			(v8).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v historyList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson8810f1f6EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v historyList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson8810f1f6EncodeGithubComAPalonskaaMetricsServerInternalHandlersServer2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *historyList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson8810f1f6DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *historyList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson8810f1f6DecodeGithubComAPalonskaaMetricsServerInternalHandlersServer2(l, v)
+}
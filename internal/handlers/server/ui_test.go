@@ -0,0 +1,53 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+func TestDashboard(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(WithLogging)
+	RouteRequests(r)
+
+	memstorage.MS.AddGauge(`UIGauge{host="<b>"}`, 1.5)
+	memstorage.MS.AddGauge(`UIGauge{host="<b>"}`, 2.5)
+
+	get := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		return w
+	}
+
+	t.Run("page", func(t *testing.T) {
+		w := get("/value/")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Body.String(), `<td class="name">UIGauge{host=&#34;&lt;b&gt;&#34;}</td>`)
+		assert.Contains(t, w.Body.String(), `/ui/static/app.js`)
+		assert.Contains(t, w.Body.String(), `<td class="name">metrics_server_goroutines<div class="help">Number of goroutines of the server.</div></td>`)
+	})
+
+	t.Run("static", func(t *testing.T) {
+		w := get("/ui/static/app.js")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "/api/history")
+	})
+
+	t.Run("history", func(t *testing.T) {
+		w := get(`/api/history?type=gauge&name=UIGauge%7Bhost%3D%22%3Cb%3E%22%7D`)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Regexp(t, `^\[\{"id":"UIGauge","type":"gauge","labels":\{"host":"\\u003cb\\u003e"\},"points":\[\{"t":\d+,"v":2.5\}\]\}\]$`,
+			w.Body.String())
+
+		w = get("/api/history?type=unknown")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
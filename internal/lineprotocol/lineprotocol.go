@@ -0,0 +1,194 @@
+package lineprotocol
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type FieldKind int
+
+const (
+	Float FieldKind = iota
+	Integer
+	Unsigned
+	Boolean
+	String
+)
+
+type FieldValue struct {
+	Kind  FieldKind
+	Float float64
+	Int   int64
+	Uint  uint64
+	Bool  bool
+	Str   string
+}
+
+// Point is a single line of the InfluxDB line protocol:
+//
+//	measurement[,tag=value...] field=value[,field=value...] [timestamp]
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]FieldValue
+	Time        time.Time // zero if the line has no timestamp
+}
+
+// Precision returns the duration of one timestamp unit. Both InfluxDB v1
+// (n, u, ms, s, m, h) and v2 (ns, us, ms, s) names are accepted, an empty
+// precision means nanoseconds.
+func Precision(s string) (time.Duration, error) {
+	switch s {
+	case "", "n", "ns":
+		return time.Nanosecond, nil
+	case "u", "us":
+		return time.Microsecond, nil
+	case "ms":
+		return time.Millisecond, nil
+	case "s":
+		return time.Second, nil
+	case "m":
+		return time.Minute, nil
+	case "h":
+		return time.Hour, nil
+	}
+	return 0, fmt.Errorf("unknown precision %q", s)
+}
+
+// Parse parses a single line. precision is the unit of the timestamp.
+func Parse(line string, precision time.Duration) (Point, error) {
+	sections, err := splitUnescaped(line, ' ', true)
+	if err != nil {
+		return Point{}, err
+	}
+	if len(sections) < 2 || len(sections) > 3 {
+		return Point{}, fmt.Errorf("expected measurement, fields and optional timestamp, got %d sections", len(sections))
+	}
+
+	p := Point{}
+	if err := p.parseKey(sections[0]); err != nil {
+		return Point{}, err
+	}
+	if err := p.parseFields(sections[1]); err != nil {
+		return Point{}, err
+	}
+
+	if len(sections) == 3 {
+		ts, err := strconv.ParseInt(sections[2], 10, 64)
+		if err != nil {
+			return Point{}, fmt.Errorf("invalid timestamp %q", sections[2])
+		}
+		p.Time = time.Unix(0, 0).Add(time.Duration(ts) * precision)
+	}
+	return p, nil
+}
+
+func (p *Point) parseKey(key string) error {
+	parts, err := splitUnescaped(key, ',', false)
+	if err != nil {
+		return err
+	}
+
+	p.Measurement = unescape(parts[0])
+	if p.Measurement == "" {
+		return fmt.Errorf("missing measurement")
+	}
+
+	for _, tag := range parts[1:] {
+		kv, err := splitUnescaped(tag, '=', false)
+		if err != nil {
+			return err
+		}
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return fmt.Errorf("invalid tag %q", tag)
+		}
+		if p.Tags == nil {
+			p.Tags = make(map[string]string)
+		}
+		p.Tags[unescape(kv[0])] = unescape(kv[1])
+	}
+	return nil
+}
+
+func (p *Point) parseFields(fields string) error {
+	parts, err := splitUnescaped(fields, ',', true)
+	if err != nil {
+		return err
+	}
+
+	p.Fields = make(map[string]FieldValue, len(parts))
+	for _, field := range parts {
+		kv, err := splitUnescaped(field, '=', true)
+		if err != nil {
+			return err
+		}
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return fmt.Errorf("invalid field %q", field)
+		}
+
+		val, err := parseFieldValue(kv[1])
+		if err != nil {
+			return fmt.Errorf("invalid value of field %q: %w", kv[0], err)
+		}
+		p.Fields[unescape(kv[0])] = val
+	}
+	return nil
+}
+
+func parseFieldValue(s string) (FieldValue, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		if len(s) < 2 || !strings.HasSuffix(s, `"`) {
+			return FieldValue{}, fmt.Errorf("unterminated string %s", s)
+		}
+		str := strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(s[1 : len(s)-1])
+		return FieldValue{Kind: String, Str: str}, nil
+	case strings.HasSuffix(s, "i"):
+		i, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+		return FieldValue{Kind: Integer, Int: i}, err
+	case strings.HasSuffix(s, "u"):
+		u, err := strconv.ParseUint(s[:len(s)-1], 10, 64)
+		return FieldValue{Kind: Unsigned, Uint: u}, err
+	}
+
+	switch s {
+	case "t", "T", "true", "True", "TRUE":
+		return FieldValue{Kind: Boolean, Bool: true}, nil
+	case "f", "F", "false", "False", "FALSE":
+		return FieldValue{Kind: Boolean, Bool: false}, nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	return FieldValue{Kind: Float, Float: f}, err
+}
+
+// splitUnescaped splits s on sep characters that are neither escaped with a
+// backslash nor, if quotes is set, inside a double quoted string.
+func splitUnescaped(s string, sep byte, quotes bool) ([]string, error) {
+	var parts []string
+	start := 0
+	inQuotes := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\':
+			i++
+		case quotes && s[i] == '"':
+			inQuotes = !inQuotes
+		case s[i] == sep && !inQuotes:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated string in %q", s)
+	}
+	return append(parts, s[start:]), nil
+}
+
+var unescaper = strings.NewReplacer(`\,`, ",", `\ `, " ", `\=`, "=", `\\`, `\`)
+
+func unescape(s string) string {
+	return unescaper.Replace(s)
+}
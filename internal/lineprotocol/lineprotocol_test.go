@@ -0,0 +1,92 @@
+package lineprotocol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		precision time.Duration
+		want      Point
+		wantErr   bool
+	}{
+		{
+			name: "minimal",
+			line: "cpu value=0.5",
+			want: Point{Measurement: "cpu", Fields: map[string]FieldValue{"value": {Kind: Float, Float: 0.5}}},
+		},
+		{
+			name:      "tags-fields-timestamp",
+			line:      "cpu,host=web-1,region=eu usage=12.5,cores=8i,ok=t,up=3u 1700000000",
+			precision: time.Second,
+			want: Point{
+				Measurement: "cpu",
+				Tags:        map[string]string{"host": "web-1", "region": "eu"},
+				Fields: map[string]FieldValue{
+					"usage": {Kind: Float, Float: 12.5},
+					"cores": {Kind: Integer, Int: 8},
+					"ok":    {Kind: Boolean, Bool: true},
+					"up":    {Kind: Unsigned, Uint: 3},
+				},
+				Time: time.Unix(1700000000, 0),
+			},
+		},
+		{
+			name: "escapes-and-strings",
+			line: `disk\ io,path=/var\,log msg="a \"b\", c=d",bytes=1i`,
+			want: Point{
+				Measurement: "disk io",
+				Tags:        map[string]string{"path": "/var,log"},
+				Fields: map[string]FieldValue{
+					"msg":   {Kind: String, Str: `a "b", c=d`},
+					"bytes": {Kind: Integer, Int: 1},
+				},
+			},
+		},
+		{name: "no-fields", line: "cpu", wantErr: true},
+		{name: "empty-measurement", line: ",host=a value=1", wantErr: true},
+		{name: "invalid-tag", line: "cpu,host value=1", wantErr: true},
+		{name: "invalid-field", line: "cpu value=abc", wantErr: true},
+		{name: "invalid-integer", line: "cpu value=1.5i", wantErr: true},
+		{name: "unterminated-string", line: `cpu msg="abc`, wantErr: true},
+		{name: "invalid-timestamp", line: "cpu value=1 abc", wantErr: true},
+		{name: "extra-section", line: "cpu value=1 1 1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			precision := tt.precision
+			if precision == 0 {
+				precision = time.Nanosecond
+			}
+			got, err := Parse(tt.line, precision)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want.Measurement, got.Measurement)
+			assert.Equal(t, tt.want.Tags, got.Tags)
+			assert.Equal(t, tt.want.Fields, got.Fields)
+			assert.True(t, tt.want.Time.Equal(got.Time), "time %v != %v", got.Time, tt.want.Time)
+		})
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	for s, want := range map[string]time.Duration{
+		"": time.Nanosecond, "ns": time.Nanosecond, "u": time.Microsecond, "ms": time.Millisecond, "s": time.Second, "h": time.Hour,
+	} {
+		got, err := Precision(s)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, s)
+	}
+	_, err := Precision("d")
+	assert.Error(t, err)
+}
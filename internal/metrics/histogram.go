@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const HistogramName = "histogram"
+
+// DefaultBuckets are used when a histogram is created from a single
+// observation and no boundaries were supplied by the client.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+//easyjson:json
+type Histogram struct {
+	Bounds []float64 `json:"bounds"`
+	Counts []uint64  `json:"counts"` // len(Bounds)+1, the last bucket is +Inf
+	Sum    float64   `json:"sum"`
+	Count  uint64    `json:"count"`
+}
+
+func NewHistogram(bounds []float64) Histogram {
+	b := make([]float64, len(bounds))
+	copy(b, bounds)
+	return Histogram{
+		Bounds: b,
+		Counts: make([]uint64, len(b)+1),
+	}
+}
+
+// ExponentialBuckets returns count boundaries starting at start, each one
+// factor times the previous.
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	bounds := make([]float64, count)
+	for i := range bounds {
+		bounds[i] = start
+		start *= factor
+	}
+	return bounds
+}
+
+func (h *Histogram) Observe(val float64) {
+	i := sort.SearchFloat64s(h.Bounds, val)
+	h.Counts[i]++
+	h.Sum += val
+	h.Count++
+}
+
+func (h Histogram) Validate() error {
+	if len(h.Counts) != len(h.Bounds)+1 {
+		return fmt.Errorf("histogram must have %d counts for %d bounds, got %d",
+			len(h.Bounds)+1, len(h.Bounds), len(h.Counts))
+	}
+	for i, b := range h.Bounds {
+		if math.IsNaN(b) || math.IsInf(b, 0) {
+			return fmt.Errorf("histogram bounds must be finite")
+		}
+		if i > 0 && !(h.Bounds[i-1] < b) {
+			return fmt.Errorf("histogram bounds must be strictly increasing")
+		}
+	}
+	if math.IsNaN(h.Sum) || math.IsInf(h.Sum, 0) {
+		return fmt.Errorf("histogram sum must be finite, got %v", h.Sum)
+	}
+
+	var total uint64
+	for _, c := range h.Counts {
+		total += c
+	}
+	if total != h.Count {
+		return fmt.Errorf("histogram count %d does not match bucket total %d", h.Count, total)
+	}
+	return nil
+}
+
+func (h Histogram) SameBounds(other Histogram) bool {
+	if len(h.Bounds) != len(other.Bounds) {
+		return false
+	}
+	for i := range h.Bounds {
+		if h.Bounds[i] != other.Bounds[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Merge adds bucket counts, sum and count of other into h. Both histograms
+// must share the same boundaries.
+func (h *Histogram) Merge(other Histogram) error {
+	if !h.SameBounds(other) {
+		return fmt.Errorf("histogram bounds mismatch")
+	}
+	for i := range other.Counts {
+		h.Counts[i] += other.Counts[i]
+	}
+	h.Sum += other.Sum
+	h.Count += other.Count
+	return nil
+}
+
+func (h Histogram) Clone() Histogram {
+	c := NewHistogram(h.Bounds)
+	copy(c.Counts, h.Counts)
+	c.Sum = h.Sum
+	c.Count = h.Count
+	return c
+}
+
+func (h *Histogram) Reset() {
+	for i := range h.Counts {
+		h.Counts[i] = 0
+	}
+	h.Sum = 0
+	h.Count = 0
+}
+
+// String renders cumulative bucket counts in the form
+// "count=N sum=S le_0.5=a le_1=b le_+Inf=N".
+func (h Histogram) String() string {
+	var sb strings.Builder
+	sb.WriteString("count=")
+	sb.WriteString(strconv.FormatUint(h.Count, 10))
+	sb.WriteString(" sum=")
+	sb.WriteString(strconv.FormatFloat(h.Sum, 'f', -1, 64))
+
+	var cumulative uint64
+	for i, c := range h.Counts {
+		cumulative += c
+		bound := math.Inf(1)
+		if i < len(h.Bounds) {
+			bound = h.Bounds[i]
+		}
+		sb.WriteString(" le_")
+		sb.WriteString(strconv.FormatFloat(bound, 'f', -1, 64))
+		sb.WriteByte('=')
+		sb.WriteString(strconv.FormatUint(cumulative, 10))
+	}
+	return sb.String()
+}
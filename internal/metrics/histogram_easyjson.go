@@ -0,0 +1,170 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package metrics
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson205948d0DecodeGithubComAPalonskaaMetricsServerInternalMetrics(in *jlexer.Lexer, out *Histogram) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "bounds":
+			if in.IsNull() {
+				in.Skip()
+				out.Bounds = nil
+			} else {
+				in.Delim('[')
+				if out.Bounds == nil {
+					if !in.IsDelim(']') {
+						out.Bounds = make([]float64, 0, 8)
+					} else {
+						out.Bounds = []float64{}
+					}
+				} else {
+					out.Bounds = (out.Bounds)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 float64
+					v1 = float64(in.Float64())
+					out.Bounds = append(out.Bounds, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "counts":
+			if in.IsNull() {
+				in.Skip()
+				out.Counts = nil
+			} else {
+				in.Delim('[')
+				if out.Counts == nil {
+					if !in.IsDelim(']') {
+						out.Counts = make([]uint64, 0, 8)
+					} else {
+						out.Counts = []uint64{}
+					}
+				} else {
+					out.Counts = (out.Counts)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v2 uint64
+					v2 = uint64(in.Uint64())
+					out.Counts = append(out.Counts, v2)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "sum":
+			out.Sum = float64(in.Float64())
+		case "count":
+			out.Count = uint64(in.Uint64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson205948d0EncodeGithubComAPalonskaaMetricsServerInternalMetrics(out *jwriter.Writer, in Histogram) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"bounds\":"
+		out.RawString(prefix[1:])
+		if in.Bounds == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v3, v4 := range in.Bounds {
+				if v3 > 0 {
+					out.RawByte(',')
+				}
+				out.Float64(float64(v4))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"counts\":"
+		out.RawString(prefix)
+		if in.Counts == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v5, v6 := range in.Counts {
+				if v5 > 0 {
+					out.RawByte(',')
+				}
+				out.Uint64(uint64(v6))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"sum\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Sum))
+	}
+	{
+		const prefix string = ",\"count\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Count))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Histogram) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson205948d0EncodeGithubComAPalonskaaMetricsServerInternalMetrics(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Histogram) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson205948d0EncodeGithubComAPalonskaaMetricsServerInternalMetrics(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Histogram) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson205948d0DecodeGithubComAPalonskaaMetricsServerInternalMetrics(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Histogram) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson205948d0DecodeGithubComAPalonskaaMetricsServerInternalMetrics(l, v)
+}
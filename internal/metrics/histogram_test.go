@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram_Validate(t *testing.T) {
+	h := NewHistogram([]float64{1, 2})
+	h.Observe(1.5)
+	assert.NoError(t, h.Validate())
+
+	for _, sum := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		invalid := h.Clone()
+		invalid.Sum = sum
+		assert.Error(t, invalid.Validate(), sum)
+	}
+	assert.Error(t, Histogram{Bounds: []float64{1, math.Inf(1)}, Counts: make([]uint64, 3)}.Validate())
+	assert.Error(t, Histogram{Bounds: []float64{math.NaN()}, Counts: make([]uint64, 2)}.Validate())
+}
@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SeriesName joins a metric name and its labels into the key a series is
+// stored under, e.g. `HeapAlloc{host="a",region="eu"}`. Labels are sorted by
+// name so that the same set always produces the same key.
+func SeriesName(name string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return name
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(name)
+	sb.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(strconv.Quote(labels[k]))
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// ParseSeriesName splits a key produced by SeriesName back into the metric
+// name and its labels. Keys without labels are returned as is.
+func ParseSeriesName(series string) (string, map[string]string, error) {
+	open := strings.IndexByte(series, '{')
+	if open < 0 {
+		return series, nil, nil
+	}
+	if !strings.HasSuffix(series, "}") {
+		return "", nil, fmt.Errorf("unterminated label set in %q", series)
+	}
+
+	name := series[:open]
+	rest := series[open+1 : len(series)-1]
+	labels := make(map[string]string)
+	for rest != "" {
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return "", nil, fmt.Errorf("invalid label in %q", series)
+		}
+		key := rest[:eq]
+
+		quoted, err := strconv.QuotedPrefix(rest[eq+1:])
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid label value in %q: %w", series, err)
+		}
+		value, _ := strconv.Unquote(quoted)
+		labels[key] = value
+
+		rest = rest[eq+1+len(quoted):]
+		if rest != "" {
+			if rest[0] != ',' {
+				return "", nil, fmt.Errorf("expected ',' between labels in %q", series)
+			}
+			rest = rest[1:]
+		}
+	}
+	return name, labels, nil
+}
+
+const (
+	MatchEqual     = "="
+	MatchNotEqual  = "!="
+	MatchRegexp    = "=~"
+	MatchNotRegexp = "!~"
+)
+
+// LabelMatcher selects series by the value of one label. A missing label has
+// the empty value.
+type LabelMatcher struct {
+	Name  string
+	Op    string
+	Value string
+
+	re *regexp.Regexp
+}
+
+func NewLabelMatcher(name, op, value string) (LabelMatcher, error) {
+	m := LabelMatcher{Name: name, Op: op, Value: value}
+	switch op {
+	case MatchEqual, MatchNotEqual:
+	case MatchRegexp, MatchNotRegexp:
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return LabelMatcher{}, err
+		}
+		m.re = re
+	default:
+		return LabelMatcher{}, fmt.Errorf("unknown match operator %q", op)
+	}
+	return m, nil
+}
+
+// ParseLabelMatcher parses matchers of the form `name=value`, `name!=value`,
+// `name=~regexp` and `name!~regexp`.
+func ParseLabelMatcher(s string) (LabelMatcher, error) {
+	for _, op := range []string{MatchNotEqual, MatchRegexp, MatchNotRegexp, MatchEqual} {
+		if i := strings.Index(s, op); i > 0 {
+			return NewLabelMatcher(s[:i], op, s[i+len(op):])
+		}
+	}
+	return LabelMatcher{}, fmt.Errorf("invalid label matcher %q", s)
+}
+
+func (m LabelMatcher) Matches(labels map[string]string) bool {
+	value := labels[m.Name]
+	switch m.Op {
+	case MatchEqual:
+		return value == m.Value
+	case MatchNotEqual:
+		return value != m.Value
+	case MatchRegexp:
+		return m.re.MatchString(value)
+	case MatchNotRegexp:
+		return !m.re.MatchString(value)
+	}
+	return false
+}
+
+func (m LabelMatcher) String() string {
+	return m.Name + m.Op + strconv.Quote(m.Value)
+}
@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeriesName(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		labels map[string]string
+		want   string
+	}{
+		{name: "no-labels", id: "HeapAlloc", want: "HeapAlloc"},
+		{
+			name:   "sorted-labels",
+			id:     "HeapAlloc",
+			labels: map[string]string{"region": "eu", "host": "a"},
+			want:   `HeapAlloc{host="a",region="eu"}`,
+		},
+		{
+			name:   "escaped-value",
+			id:     "requests",
+			labels: map[string]string{"path": `/a,"b"}`},
+			want:   `requests{path="/a,\"b\"}"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SeriesName(tt.id, tt.labels)
+			assert.Equal(t, tt.want, got)
+
+			id, labels, err := ParseSeriesName(got)
+			require.NoError(t, err)
+			assert.Equal(t, tt.id, id)
+			if len(tt.labels) == 0 {
+				assert.Empty(t, labels)
+			} else {
+				assert.Equal(t, tt.labels, labels)
+			}
+		})
+	}
+}
+
+func TestParseSeriesName_Invalid(t *testing.T) {
+	for _, series := range []string{`a{b="c"`, `a{b}`, `a{b=c}`, `a{b="c"d="e"}`} {
+		_, _, err := ParseSeriesName(series)
+		assert.Error(t, err, series)
+	}
+}
+
+func TestLabelMatcher(t *testing.T) {
+	labels := map[string]string{"host": "web-1", "region": "eu"}
+	tests := []struct {
+		matcher string
+		want    bool
+	}{
+		{matcher: "host=web-1", want: true},
+		{matcher: "host=web-2", want: false},
+		{matcher: "host!=web-2", want: true},
+		{matcher: "host=~web-.*", want: true},
+		{matcher: "host=~web", want: false},
+		{matcher: "region!~e.", want: false},
+		{matcher: "zone=", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.matcher, func(t *testing.T) {
+			m, err := ParseLabelMatcher(tt.matcher)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, m.Matches(labels))
+		})
+	}
+
+	_, err := ParseLabelMatcher("host")
+	assert.Error(t, err)
+	_, err = ParseLabelMatcher("host=~(")
+	assert.Error(t, err)
+}
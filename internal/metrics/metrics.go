@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"fmt"
 	"strconv"
 )
 
@@ -24,4 +25,58 @@ type Metrics struct {
 	MType string   `json:"type"`
 	Delta *int64   `json:"delta,omitempty"` // counter
 	Value *float64 `json:"value,omitempty"` // gauge
+
+	Histogram *Histogram `json:"histogram,omitempty"` // histogram
+	Summary   *Summary   `json:"summary,omitempty"`   // summary
+	Set       *Set       `json:"set,omitempty"`       // set
+	Members   []string   `json:"members,omitempty"`   // set
+
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Stale is set in listings for series not updated for their TTL.
+	Stale bool `json:"stale,omitempty"`
+}
+
+//easyjson:json
+type MetricsList []Metrics
+
+// SeriesName returns the key the metric is stored under.
+func (m Metrics) SeriesName() string {
+	return SeriesName(m.ID, m.Labels)
+}
+
+// FromValue builds the wire representation of a stored value. The series
+// name is split into the metric ID and its labels.
+func FromValue(series string, mType string, val fmt.Stringer) (Metrics, error) {
+	id, labels, err := ParseSeriesName(series)
+	if err != nil {
+		return Metrics{}, err
+	}
+	m := Metrics{ID: id, MType: mType, Labels: labels}
+
+	var valType string
+	switch v := val.(type) {
+	case Gauge:
+		fVal := float64(v)
+		m.Value = &fVal
+		valType = GaugeName
+	case Counter:
+		iVal := int64(v)
+		m.Delta = &iVal
+		valType = CounterName
+	case Histogram:
+		m.Histogram = &v
+		valType = HistogramName
+	case Summary:
+		m.Summary = &v
+		valType = SummaryName
+	case Set:
+		m.Set = &v
+		valType = SetName
+	}
+
+	if valType != mType {
+		return Metrics{}, fmt.Errorf("unknown type %s", mType)
+	}
+	return m, nil
 }
@@ -17,7 +17,73 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjson2220f231DecodeGithubComAPalonskaaMetricsServerInternalMetrics(in *jlexer.Lexer, out *Metrics) {
+func easyjson2220f231DecodeGithubComAPalonskaaMetricsServerInternalMetrics(in *jlexer.Lexer, out *MetricsList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(MetricsList, 0, 0)
+			} else {
+				*out = MetricsList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v1 Metrics
+			(v1).UnmarshalEasyJSON(in)
+			*out = append(*out, v1)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson2220f231EncodeGithubComAPalonskaaMetricsServerInternalMetrics(out *jwriter.Writer, in MetricsList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v2, v3 := range in {
+			if v2 > 0 {
+				out.RawByte(',')
+			}
+			(v3).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v MetricsList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson2220f231EncodeGithubComAPalonskaaMetricsServerInternalMetrics(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v MetricsList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson2220f231EncodeGithubComAPalonskaaMetricsServerInternalMetrics(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *MetricsList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson2220f231DecodeGithubComAPalonskaaMetricsServerInternalMetrics(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *MetricsList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson2220f231DecodeGithubComAPalonskaaMetricsServerInternalMetrics(l, v)
+}
+func easyjson2220f231DecodeGithubComAPalonskaaMetricsServerInternalMetrics1(in *jlexer.Lexer, out *Metrics) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -60,6 +126,81 @@ func easyjson2220f231DecodeGithubComAPalonskaaMetricsServerInternalMetrics(in *j
 				}
 				*out.Value = float64(in.Float64())
 			}
+		case "histogram":
+			if in.IsNull() {
+				in.Skip()
+				out.Histogram = nil
+			} else {
+				if out.Histogram == nil {
+					out.Histogram = new(Histogram)
+				}
+				(*out.Histogram).UnmarshalEasyJSON(in)
+			}
+		case "summary":
+			if in.IsNull() {
+				in.Skip()
+				out.Summary = nil
+			} else {
+				if out.Summary == nil {
+					out.Summary = new(Summary)
+				}
+				(*out.Summary).UnmarshalEasyJSON(in)
+			}
+		case "set":
+			if in.IsNull() {
+				in.Skip()
+				out.Set = nil
+			} else {
+				if out.Set == nil {
+					out.Set = new(Set)
+				}
+				(*out.Set).UnmarshalEasyJSON(in)
+			}
+		case "members":
+			if in.IsNull() {
+				in.Skip()
+				out.Members = nil
+			} else {
+				in.Delim('[')
+				if out.Members == nil {
+					if !in.IsDelim(']') {
+						out.Members = make([]string, 0, 4)
+					} else {
+						out.Members = []string{}
+					}
+				} else {
+					out.Members = (out.Members)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 string
+					v4 = string(in.String())
+					out.Members = append(out.Members, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Labels = make(map[string]string)
+				} else {
+					out.Labels = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v5 string
+					v5 = string(in.String())
+					(out.Labels)[key] = v5
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "stale":
+			out.Stale = bool(in.Bool())
 		default:
 			in.SkipRecursive()
 		}
@@ -70,7 +211,7 @@ func easyjson2220f231DecodeGithubComAPalonskaaMetricsServerInternalMetrics(in *j
 		in.Consumed()
 	}
 }
-func easyjson2220f231EncodeGithubComAPalonskaaMetricsServerInternalMetrics(out *jwriter.Writer, in Metrics) {
+func easyjson2220f231EncodeGithubComAPalonskaaMetricsServerInternalMetrics1(out *jwriter.Writer, in Metrics) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -94,29 +235,82 @@ func easyjson2220f231EncodeGithubComAPalonskaaMetricsServerInternalMetrics(out *
 		out.RawString(prefix)
 		out.Float64(float64(*in.Value))
 	}
+	if in.Histogram != nil {
+		const prefix string = ",\"histogram\":"
+		out.RawString(prefix)
+		(*in.Histogram).MarshalEasyJSON(out)
+	}
+	if in.Summary != nil {
+		const prefix string = ",\"summary\":"
+		out.RawString(prefix)
+		(*in.Summary).MarshalEasyJSON(out)
+	}
+	if in.Set != nil {
+		const prefix string = ",\"set\":"
+		out.RawString(prefix)
+		(*in.Set).MarshalEasyJSON(out)
+	}
+	if len(in.Members) != 0 {
+		const prefix string = ",\"members\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v6, v7 := range in.Members {
+				if v6 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v7))
+			}
+			out.RawByte(']')
+		}
+	}
+	if len(in.Labels) != 0 {
+		const prefix string = ",\"labels\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v8First := true
+			for v8Name, v8Value := range in.Labels {
+				if v8First {
+					v8First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v8Name))
+				out.RawByte(':')
+				out.String(string(v8Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	if in.Stale {
+		const prefix string = ",\"stale\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Stale))
+	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
 func (v Metrics) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson2220f231EncodeGithubComAPalonskaaMetricsServerInternalMetrics(&w, v)
+	easyjson2220f231EncodeGithubComAPalonskaaMetricsServerInternalMetrics1(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Metrics) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson2220f231EncodeGithubComAPalonskaaMetricsServerInternalMetrics(w, v)
+	easyjson2220f231EncodeGithubComAPalonskaaMetricsServerInternalMetrics1(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Metrics) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson2220f231DecodeGithubComAPalonskaaMetricsServerInternalMetrics(&r, v)
+	easyjson2220f231DecodeGithubComAPalonskaaMetricsServerInternalMetrics1(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Metrics) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson2220f231DecodeGithubComAPalonskaaMetricsServerInternalMetrics(l, v)
+	easyjson2220f231DecodeGithubComAPalonskaaMetricsServerInternalMetrics1(l, v)
 }
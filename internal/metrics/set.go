@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"strconv"
+)
+
+const SetName = "set"
+
+// DefaultSetPrecision gives 4096 registers and a standard error of about 1.6%.
+const DefaultSetPrecision = 12
+
+const (
+	minSetPrecision = 4
+	maxSetPrecision = 18
+)
+
+// Set is a HyperLogLog sketch estimating the number of distinct members
+// added to it. Sketches of the same precision are merged by taking the
+// register-wise maximum, so a member reported by several agents is only
+// counted once.
+//
+//easyjson:json
+type Set struct {
+	Precision uint8  `json:"precision"`
+	Registers []byte `json:"registers"`
+}
+
+func NewSet(precision uint8) Set {
+	return Set{
+		Precision: precision,
+		Registers: make([]byte, 1<<precision),
+	}
+}
+
+// hashMember is stable across processes so that agents and the server agree
+// on the register a member falls into.
+func hashMember(member string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(member))
+
+	// fnv alone mixes the high bits poorly, finish with murmur3's fmix64
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+func (s *Set) Add(member string) {
+	if len(s.Registers) == 0 {
+		*s = NewSet(DefaultSetPrecision)
+	}
+
+	x := hashMember(member)
+	idx := x >> (64 - s.Precision)
+	rho := uint8(bits.LeadingZeros64(x<<s.Precision|1<<(s.Precision-1))) + 1
+	if rho > s.Registers[idx] {
+		s.Registers[idx] = rho
+	}
+}
+
+func (s Set) Validate() error {
+	if s.Precision < minSetPrecision || s.Precision > maxSetPrecision {
+		return fmt.Errorf("set precision must be in [%d, %d], got %d", minSetPrecision, maxSetPrecision, s.Precision)
+	}
+	if len(s.Registers) != 1<<s.Precision {
+		return fmt.Errorf("set of precision %d must have %d registers, got %d",
+			s.Precision, 1<<s.Precision, len(s.Registers))
+	}
+	// a register holds the position of the first set bit of the 64-p hash bits
+	// left after the index, Add never stores more than 64-p+1
+	maxRegister := 64 - s.Precision + 1
+	for i, r := range s.Registers {
+		if r > maxRegister {
+			return fmt.Errorf("set register %d of precision %d must be at most %d, got %d",
+				i, s.Precision, maxRegister, r)
+		}
+	}
+	return nil
+}
+
+// Merge makes s the union of s and other. Both sketches must have the same
+// precision.
+func (s *Set) Merge(other Set) error {
+	if s.Precision != other.Precision || len(s.Registers) != len(other.Registers) {
+		return fmt.Errorf("set precision mismatch: %d != %d", s.Precision, other.Precision)
+	}
+	for i, r := range other.Registers {
+		if r > s.Registers[i] {
+			s.Registers[i] = r
+		}
+	}
+	return nil
+}
+
+func (s Set) Clone() Set {
+	c := Set{Precision: s.Precision, Registers: make([]byte, len(s.Registers))}
+	copy(c.Registers, s.Registers)
+	return c
+}
+
+// Cardinality returns the estimated number of distinct members.
+func (s Set) Cardinality() uint64 {
+	m := float64(len(s.Registers))
+	if m == 0 {
+		return 0
+	}
+
+	var sum float64
+	zeros := 0
+	for _, r := range s.Registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	estimate := alpha(m) * m * m / sum
+	if estimate <= 2.5*m && zeros != 0 {
+		// linear counting is more accurate for small cardinalities
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+func alpha(m float64) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	}
+	return 0.7213 / (1 + 1.079/m)
+}
+
+func (s Set) String() string {
+	return strconv.FormatUint(s.Cardinality(), 10)
+}
@@ -0,0 +1,97 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package metrics
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonAb31f886DecodeGithubComAPalonskaaMetricsServerInternalMetrics(in *jlexer.Lexer, out *Set) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "precision":
+			out.Precision = uint8(in.Uint8())
+		case "registers":
+			if in.IsNull() {
+				in.Skip()
+				out.Registers = nil
+			} else {
+				out.Registers = in.Bytes()
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonAb31f886EncodeGithubComAPalonskaaMetricsServerInternalMetrics(out *jwriter.Writer, in Set) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"precision\":"
+		out.RawString(prefix[1:])
+		out.Uint8(uint8(in.Precision))
+	}
+	{
+		const prefix string = ",\"registers\":"
+		out.RawString(prefix)
+		out.Base64Bytes(in.Registers)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Set) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonAb31f886EncodeGithubComAPalonskaaMetricsServerInternalMetrics(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Set) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonAb31f886EncodeGithubComAPalonskaaMetricsServerInternalMetrics(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Set) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonAb31f886DecodeGithubComAPalonskaaMetricsServerInternalMetrics(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Set) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonAb31f886DecodeGithubComAPalonskaaMetricsServerInternalMetrics(l, v)
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSet_Cardinality(t *testing.T) {
+	for _, n := range []int{0, 1, 10, 100, 1000, 10000, 200000} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			s := NewSet(DefaultSetPrecision)
+			for i := 0; i < n; i++ {
+				member := "user-" + strconv.Itoa(i)
+				s.Add(member)
+				s.Add(member)
+			}
+
+			// 4 standard errors of a precision 12 sketch
+			bound := 4 * 1.04 / math.Sqrt(1<<DefaultSetPrecision)
+			assert.InDelta(t, n, s.Cardinality(), float64(n)*bound+0.5)
+		})
+	}
+}
+
+func TestSet_Merge(t *testing.T) {
+	a := NewSet(DefaultSetPrecision)
+	b := NewSet(DefaultSetPrecision)
+	whole := NewSet(DefaultSetPrecision)
+	for i := 0; i < 5000; i++ {
+		member := "10.0.0." + strconv.Itoa(i)
+		whole.Add(member)
+		if i < 3000 {
+			a.Add(member)
+		}
+		if i >= 2000 {
+			b.Add(member)
+		}
+	}
+
+	require.NoError(t, a.Merge(b))
+	assert.Equal(t, whole.Registers, a.Registers)
+	assert.Error(t, a.Merge(NewSet(DefaultSetPrecision+1)))
+}
+
+func TestSet_Validate(t *testing.T) {
+	assert.NoError(t, NewSet(DefaultSetPrecision).Validate())
+	assert.Error(t, NewSet(2).Validate())
+	assert.Error(t, Set{Precision: DefaultSetPrecision, Registers: make([]byte, 10)}.Validate())
+
+	s := NewSet(DefaultSetPrecision)
+	s.Registers[7] = 64 - DefaultSetPrecision + 1
+	assert.NoError(t, s.Validate())
+	s.Registers[7]++
+	assert.Error(t, s.Validate())
+	s.Registers[7] = 255
+	assert.Error(t, s.Validate())
+}
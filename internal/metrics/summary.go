@@ -0,0 +1,232 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const SummaryName = "summary"
+
+// DefaultSummaryAccuracy is the relative error guaranteed by quantiles of
+// summaries created without an explicit accuracy.
+const DefaultSummaryAccuracy = 0.01
+
+// DefaultQuantiles are rendered by Summary.String.
+var DefaultQuantiles = []float64{0.5, 0.9, 0.99}
+
+// values closer to zero than this are counted in the zero bucket
+const minIndexableValue = 1e-9
+
+// Summary is a DDSketch: values are mapped to logarithmically sized buckets so
+// that any quantile is estimated with a relative error of at most Accuracy.
+// Sketches with the same accuracy are merged by adding bucket counts, which
+// makes them safe to aggregate across agents.
+//
+//easyjson:json
+type Summary struct {
+	Accuracy float64        `json:"accuracy"`
+	Positive map[int]uint64 `json:"positive,omitempty"`
+	Negative map[int]uint64 `json:"negative,omitempty"`
+	Zero     uint64         `json:"zero"`
+	Count    uint64         `json:"count"`
+	Sum      float64        `json:"sum"`
+	Min      float64        `json:"min"`
+	Max      float64        `json:"max"`
+}
+
+func NewSummary(accuracy float64) Summary {
+	return Summary{
+		Accuracy: accuracy,
+		Positive: make(map[int]uint64),
+		Negative: make(map[int]uint64),
+	}
+}
+
+func (s Summary) gamma() float64 {
+	return (1 + s.Accuracy) / (1 - s.Accuracy)
+}
+
+func (s Summary) index(val float64) int {
+	return int(math.Ceil(math.Log(val) / math.Log(s.gamma())))
+}
+
+// value returns the representative value of bucket i, chosen so that
+// every value mapped to the bucket is within Accuracy of it.
+func (s Summary) value(i int) float64 {
+	g := s.gamma()
+	return 2 * math.Pow(g, float64(i)) / (g + 1)
+}
+
+func (s *Summary) Observe(val float64) {
+	if s.Positive == nil {
+		s.Positive = make(map[int]uint64)
+	}
+	if s.Negative == nil {
+		s.Negative = make(map[int]uint64)
+	}
+
+	switch {
+	case val > minIndexableValue:
+		s.Positive[s.index(val)]++
+	case val < -minIndexableValue:
+		s.Negative[s.index(-val)]++
+	default:
+		s.Zero++
+	}
+
+	if s.Count == 0 || val < s.Min {
+		s.Min = val
+	}
+	if s.Count == 0 || val > s.Max {
+		s.Max = val
+	}
+	s.Count++
+	s.Sum += val
+}
+
+func (s Summary) Validate() error {
+	if !(s.Accuracy > 0 && s.Accuracy < 1) {
+		return fmt.Errorf("summary accuracy must be in (0, 1), got %v", s.Accuracy)
+	}
+
+	total := s.Zero
+	for _, c := range s.Positive {
+		total += c
+	}
+	for _, c := range s.Negative {
+		total += c
+	}
+	if total != s.Count {
+		return fmt.Errorf("summary count %d does not match bucket total %d", s.Count, total)
+	}
+	for _, v := range []float64{s.Sum, s.Min, s.Max} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("summary sum, min and max must be finite, got %v", v)
+		}
+	}
+	return nil
+}
+
+// Merge adds the buckets of other into s. Both sketches must have been
+// created with the same accuracy.
+func (s *Summary) Merge(other Summary) error {
+	if s.Accuracy != other.Accuracy {
+		return fmt.Errorf("summary accuracy mismatch: %v != %v", s.Accuracy, other.Accuracy)
+	}
+	if other.Count == 0 {
+		return nil
+	}
+
+	if s.Positive == nil {
+		s.Positive = make(map[int]uint64)
+	}
+	if s.Negative == nil {
+		s.Negative = make(map[int]uint64)
+	}
+	for i, c := range other.Positive {
+		s.Positive[i] += c
+	}
+	for i, c := range other.Negative {
+		s.Negative[i] += c
+	}
+
+	if s.Count == 0 || other.Min < s.Min {
+		s.Min = other.Min
+	}
+	if s.Count == 0 || other.Max > s.Max {
+		s.Max = other.Max
+	}
+	s.Zero += other.Zero
+	s.Count += other.Count
+	s.Sum += other.Sum
+	return nil
+}
+
+func (s Summary) Clone() Summary {
+	c := s
+	c.Positive = make(map[int]uint64, len(s.Positive))
+	for i, v := range s.Positive {
+		c.Positive[i] = v
+	}
+	c.Negative = make(map[int]uint64, len(s.Negative))
+	for i, v := range s.Negative {
+		c.Negative[i] = v
+	}
+	return c
+}
+
+func (s *Summary) Reset() {
+	*s = NewSummary(s.Accuracy)
+}
+
+// Quantile estimates the q-quantile (0 <= q <= 1) of the observed values.
+func (s Summary) Quantile(q float64) (float64, error) {
+	if q < 0 || q > 1 || math.IsNaN(q) {
+		return 0, fmt.Errorf("quantile must be in [0, 1], got %v", q)
+	}
+	if s.Count == 0 {
+		return 0, fmt.Errorf("summary is empty")
+	}
+
+	rank := uint64(q * float64(s.Count-1))
+	var seen uint64
+
+	negative := sortedKeys(s.Negative)
+	for i := len(negative) - 1; i >= 0; i-- {
+		seen += s.Negative[negative[i]]
+		if seen > rank {
+			return s.clamp(-s.value(negative[i])), nil
+		}
+	}
+
+	seen += s.Zero
+	if seen > rank {
+		return s.clamp(0), nil
+	}
+
+	for _, i := range sortedKeys(s.Positive) {
+		seen += s.Positive[i]
+		if seen > rank {
+			return s.clamp(s.value(i)), nil
+		}
+	}
+	return s.Max, nil
+}
+
+func (s Summary) clamp(val float64) float64 {
+	return math.Max(s.Min, math.Min(s.Max, val))
+}
+
+// String renders count, sum and DefaultQuantiles in the form
+// "count=N sum=S p50=a p90=b p99=c".
+func (s Summary) String() string {
+	var sb strings.Builder
+	sb.WriteString("count=")
+	sb.WriteString(strconv.FormatUint(s.Count, 10))
+	sb.WriteString(" sum=")
+	sb.WriteString(strconv.FormatFloat(s.Sum, 'f', -1, 64))
+
+	for _, q := range DefaultQuantiles {
+		val, err := s.Quantile(q)
+		if err != nil {
+			break
+		}
+		sb.WriteString(" p")
+		sb.WriteString(strconv.FormatFloat(q*100, 'f', -1, 64))
+		sb.WriteByte('=')
+		sb.WriteString(strconv.FormatFloat(val, 'f', -1, 64))
+	}
+	return sb.String()
+}
+
+func sortedKeys(m map[int]uint64) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,198 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package metrics
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonF381ebcaDecodeGithubComAPalonskaaMetricsServerInternalMetrics(in *jlexer.Lexer, out *Summary) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "accuracy":
+			out.Accuracy = float64(in.Float64())
+		case "positive":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Positive = make(map[int]uint64)
+				} else {
+					out.Positive = nil
+				}
+				for !in.IsDelim('}') {
+					key := int(in.IntStr())
+					in.WantColon()
+					var v1 uint64
+					v1 = uint64(in.Uint64())
+					(out.Positive)[key] = v1
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "negative":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Negative = make(map[int]uint64)
+				} else {
+					out.Negative = nil
+				}
+				for !in.IsDelim('}') {
+					key := int(in.IntStr())
+					in.WantColon()
+					var v2 uint64
+					v2 = uint64(in.Uint64())
+					(out.Negative)[key] = v2
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "zero":
+			out.Zero = uint64(in.Uint64())
+		case "count":
+			out.Count = uint64(in.Uint64())
+		case "sum":
+			out.Sum = float64(in.Float64())
+		case "min":
+			out.Min = float64(in.Float64())
+		case "max":
+			out.Max = float64(in.Float64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonF381ebcaEncodeGithubComAPalonskaaMetricsServerInternalMetrics(out *jwriter.Writer, in Summary) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"accuracy\":"
+		out.RawString(prefix[1:])
+		out.Float64(float64(in.Accuracy))
+	}
+	if len(in.Positive) != 0 {
+		const prefix string = ",\"positive\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v3First := true
+			for v3Name, v3Value := range in.Positive {
+				if v3First {
+					v3First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.IntStr(int(v3Name))
+				out.RawByte(':')
+				out.Uint64(uint64(v3Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	if len(in.Negative) != 0 {
+		const prefix string = ",\"negative\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v4First := true
+			for v4Name, v4Value := range in.Negative {
+				if v4First {
+					v4First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.IntStr(int(v4Name))
+				out.RawByte(':')
+				out.Uint64(uint64(v4Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"zero\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Zero))
+	}
+	{
+		const prefix string = ",\"count\":"
+		out.RawString(prefix)
+		out.Uint64(uint64(in.Count))
+	}
+	{
+		const prefix string = ",\"sum\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Sum))
+	}
+	{
+		const prefix string = ",\"min\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Min))
+	}
+	{
+		const prefix string = ",\"max\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.Max))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Summary) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonF381ebcaEncodeGithubComAPalonskaaMetricsServerInternalMetrics(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Summary) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonF381ebcaEncodeGithubComAPalonskaaMetricsServerInternalMetrics(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Summary) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonF381ebcaDecodeGithubComAPalonskaaMetricsServerInternalMetrics(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Summary) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonF381ebcaDecodeGithubComAPalonskaaMetricsServerInternalMetrics(l, v)
+}
@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exactQuantile(sorted []float64, q float64) float64 {
+	return sorted[int(q*float64(len(sorted)-1))]
+}
+
+func TestSummary_QuantileErrorBound(t *testing.T) {
+	tests := []struct {
+		name     string
+		accuracy float64
+		gen      func(r *rand.Rand) float64
+	}{
+		{
+			name:     "uniform",
+			accuracy: 0.01,
+			gen:      func(r *rand.Rand) float64 { return r.Float64() * 1000 },
+		},
+		{
+			name:     "exponential",
+			accuracy: 0.01,
+			gen:      func(r *rand.Rand) float64 { return r.ExpFloat64() * 1e6 },
+		},
+		{
+			name:     "lognormal-coarse",
+			accuracy: 0.05,
+			gen:      func(r *rand.Rand) float64 { return math.Exp(r.NormFloat64() * 3) },
+		},
+		{
+			name:     "mixed-sign",
+			accuracy: 0.02,
+			gen:      func(r *rand.Rand) float64 { return r.NormFloat64() * 100 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(42))
+			s := NewSummary(tt.accuracy)
+			values := make([]float64, 10000)
+			for i := range values {
+				values[i] = tt.gen(r)
+				s.Observe(values[i])
+			}
+			sort.Float64s(values)
+
+			for _, q := range []float64{0, 0.1, 0.25, 0.5, 0.75, 0.9, 0.99, 0.999, 1} {
+				got, err := s.Quantile(q)
+				require.NoError(t, err)
+
+				want := exactQuantile(values, q)
+				assert.InDelta(t, want, got, math.Abs(want)*tt.accuracy+1e-9, "q=%v", q)
+			}
+		})
+	}
+}
+
+func TestSummary_Merge(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	whole := NewSummary(DefaultSummaryAccuracy)
+	parts := []Summary{
+		NewSummary(DefaultSummaryAccuracy),
+		NewSummary(DefaultSummaryAccuracy),
+		NewSummary(DefaultSummaryAccuracy),
+	}
+	for i := 0; i < 3000; i++ {
+		val := r.ExpFloat64() * 50
+		whole.Observe(val)
+		parts[i%len(parts)].Observe(val)
+	}
+
+	merged := NewSummary(DefaultSummaryAccuracy)
+	for _, part := range parts {
+		require.NoError(t, merged.Merge(part))
+	}
+	require.NoError(t, merged.Validate())
+
+	assert.Equal(t, whole.Count, merged.Count)
+	assert.Equal(t, whole.Min, merged.Min)
+	assert.Equal(t, whole.Max, merged.Max)
+	for _, q := range DefaultQuantiles {
+		want, _ := whole.Quantile(q)
+		got, _ := merged.Quantile(q)
+		assert.Equal(t, want, got, "q=%v", q)
+	}
+
+	assert.Error(t, merged.Merge(NewSummary(0.05)))
+}
+
+func TestSummary_Quantile(t *testing.T) {
+	s := NewSummary(DefaultSummaryAccuracy)
+	_, err := s.Quantile(0.5)
+	assert.Error(t, err)
+
+	s.Observe(10)
+	_, err = s.Quantile(1.5)
+	assert.Error(t, err)
+
+	got, err := s.Quantile(0.5)
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, got)
+	assert.Equal(t, "count=1 sum=10 p50=10 p90=10 p99=10", s.String())
+}
+
+func TestSummary_Validate(t *testing.T) {
+	s := NewSummary(DefaultSummaryAccuracy)
+	s.Observe(2)
+	assert.NoError(t, s.Validate())
+
+	for _, v := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		for _, field := range []*float64{&s.Sum, &s.Min, &s.Max} {
+			saved := *field
+			*field = v
+			assert.Error(t, s.Validate(), v)
+			*field = saved
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package metricsstorage
+
+import (
+	"sort"
+	"time"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+)
+
+var metricTypes = []string{
+	metrics.GaugeName, metrics.CounterName, metrics.HistogramName, metrics.SummaryName, metrics.SetName,
+}
+
+// DeleteSeries removes every series match returns true for, together with
+// its counter and chart history, and returns the removed series by type.
+// The runtime metrics refreshed by Update are allowed again and come back on
+// the next update.
+func (m *MetricsStorage) DeleteSeries(match func(mType, series string) bool) map[string][]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := make(map[string][]string)
+	for _, mType := range metricTypes {
+		for _, series := range m.storedNames(mType) {
+			if match(mType, series) {
+				m.deleteSeries(mType, series)
+				deleted[mType] = append(deleted[mType], series)
+			}
+		}
+	}
+	return deleted
+}
+
+// storedNames returns the sorted names of a type that have a value or are
+// allowed. It is called with mu held.
+func (m *MetricsStorage) storedNames(mType string) []string {
+	seen := make(map[string]bool)
+	for name := range m.allowedNames(mType) {
+		seen[name] = true
+	}
+	switch mType {
+	case metrics.GaugeName:
+		for name := range m.GaugeMetrics {
+			seen[name] = true
+		}
+	case metrics.CounterName:
+		for name := range m.CounterMetrics {
+			seen[name] = true
+		}
+	case metrics.HistogramName:
+		for name := range m.HistogramMetrics {
+			seen[name] = true
+		}
+	case metrics.SummaryName:
+		for name := range m.SummaryMetrics {
+			seen[name] = true
+		}
+	case metrics.SetName:
+		for name := range m.SetMetrics {
+			seen[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// deleteSeries is called with mu held.
+func (m *MetricsStorage) deleteSeries(mType string, series string) {
+	switch mType {
+	case metrics.GaugeName:
+		delete(m.GaugeMetrics, series)
+	case metrics.CounterName:
+		delete(m.CounterMetrics, series)
+		delete(m.counterHistory, series)
+	case metrics.HistogramName:
+		delete(m.HistogramMetrics, series)
+	case metrics.SummaryName:
+		delete(m.SummaryMetrics, series)
+	case metrics.SetName:
+		delete(m.SetMetrics, series)
+	}
+	delete(m.allowedNames(mType), series)
+
+	key := seriesKey{mType: mType, series: series}
+	delete(m.timeline, key)
+	delete(m.updated, key)
+}
+
+// ResetCounters sets every counter match returns true for to 0 and returns
+// the sorted names of the reset counters. Rates see the reset like any other
+// counter reset.
+func (m *MetricsStorage) ResetCounters(match func(series string) bool) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	reset := []string{}
+	for series, ok := range m.AllowedCounterNames {
+		if !ok || !match(series) {
+			continue
+		}
+		m.recordCounterSample(series, m.CounterMetrics[series], 0)
+		m.CounterMetrics[series] = 0
+		m.notify(metrics.CounterName, series)
+		reset = append(reset, series)
+	}
+	sort.Strings(reset)
+	return reset
+}
+
+// Compact drops what no series needs anymore: values and history of names
+// that are not allowed, names marked as not allowed and counter samples older
+// than the widest rate window. It returns the number of removed entries.
+func (m *MetricsStorage) Compact() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := 0
+	for _, mType := range metricTypes {
+		allowed := m.allowedNames(mType)
+		for _, series := range m.storedNames(mType) {
+			if !allowed[series] {
+				m.deleteSeries(mType, series)
+				removed++
+			}
+		}
+	}
+
+	for key, points := range m.timeline {
+		if len(points) == 0 || !m.allowedNames(key.mType)[key.series] {
+			delete(m.timeline, key)
+			removed++
+		}
+	}
+	for key := range m.updated {
+		if !m.allowedNames(key.mType)[key.series] {
+			delete(m.updated, key)
+			removed++
+		}
+	}
+
+	cutoff := now().Add(-historyRetention())
+	for series, samples := range m.counterHistory {
+		if !m.AllowedCounterNames[series] {
+			delete(m.counterHistory, series)
+			removed++
+			continue
+		}
+		kept := trimSamples(samples, cutoff)
+		removed += len(samples) - len(kept)
+		m.counterHistory[series] = kept
+	}
+	return removed
+}
+
+// trimSamples drops the samples not after cutoff except the last of them,
+// which stays as the baseline of a window starting at cutoff.
+func trimSamples(samples []Sample, cutoff time.Time) []Sample {
+	drop := 0
+	for drop+1 < len(samples) && !samples[drop+1].Time.After(cutoff) {
+		drop++
+	}
+	return samples[drop:]
+}
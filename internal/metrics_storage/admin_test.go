@@ -0,0 +1,95 @@
+package metricsstorage
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+)
+
+func TestMemStorage_DeleteSeries(t *testing.T) {
+	storage := NewMetricsStorage()
+	storage.AddGauge(`Load{host="a"}`, 1)
+	storage.AddGauge(`Load{host="b"}`, 2)
+	storage.AddCounter(`Load{host="a"}`, 3)
+	storage.AddGauge("Temp", 4)
+	storage.ObserveHistogram("Latency", 0.1)
+
+	deleted := storage.DeleteSeries(func(mType, series string) bool {
+		return strings.HasPrefix(series, `Load{host="a"}`)
+	})
+	assert.Equal(t, map[string][]string{
+		metrics.GaugeName:   {`Load{host="a"}`},
+		metrics.CounterName: {`Load{host="a"}`},
+	}, deleted)
+
+	_, ok := storage.GetGaugeValue(`Load{host="a"}`)
+	assert.False(t, ok)
+	_, ok = storage.History(metrics.GaugeName, `Load{host="a"}`)
+	assert.False(t, ok)
+	_, ok = storage.LastUpdate(metrics.CounterName, `Load{host="a"}`)
+	assert.False(t, ok)
+	_, ok = storage.CounterIncrease(`Load{host="a"}`, time.Minute)
+	assert.False(t, ok)
+	assert.Equal(t, []string{`Load{host="b"}`, "Temp"}, storage.Series(metrics.GaugeName))
+
+	deleted = storage.DeleteSeries(func(mType, _ string) bool { return mType == metrics.HistogramName })
+	assert.Equal(t, map[string][]string{metrics.HistogramName: {"Latency"}}, deleted)
+	assert.Empty(t, storage.HistogramMetrics)
+
+	data, err := storage.MarshalJSON()
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Latency")
+	assert.NotContains(t, string(data), `host=\"a\"`)
+}
+
+func TestMemStorage_ResetCounters(t *testing.T) {
+	storage := NewMetricsStorage()
+	storage.AddCounter("requests", 10)
+	storage.AddCounter("errors", 2)
+
+	reset := storage.ResetCounters(func(series string) bool { return series != "errors" })
+	assert.Equal(t, []string{"requests"}, reset)
+
+	val, _ := storage.GetCounterValue("requests")
+	assert.Equal(t, metrics.Counter(0), val)
+	val, _ = storage.GetCounterValue("errors")
+	assert.Equal(t, metrics.Counter(2), val)
+
+	storage.AddCounter("requests", 5)
+	increase, _ := storage.CounterIncrease("requests", time.Minute)
+	assert.Equal(t, metrics.Gauge(15), increase)
+}
+
+func TestMemStorage_Compact(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	current := base
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	storage := NewMetricsStorage()
+	for i := 0; i < 5; i++ {
+		storage.AddCounter("requests", 1)
+		current = current.Add(time.Minute)
+	}
+	storage.AddGauge("Load", 1)
+	// left over from an older snapshot
+	storage.GaugeMetrics["Orphan"] = 1
+	storage.AllowedCounterNames["Disabled"] = false
+
+	current = base.Add(time.Hour)
+	removed := storage.Compact()
+	// the orphan gauge, the disabled name and all but the last counter sample
+	assert.Equal(t, 1+1+5, removed)
+	assert.NotContains(t, storage.GaugeMetrics, "Orphan")
+	assert.NotContains(t, storage.AllowedCounterNames, "Disabled")
+	assert.Len(t, storage.counterHistory["requests"], 1)
+
+	val, _ := storage.GetCounterValue("requests")
+	assert.Equal(t, metrics.Counter(5), val)
+	assert.Equal(t, 0, storage.Compact())
+}
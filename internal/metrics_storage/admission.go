@@ -0,0 +1,94 @@
+package metricsstorage
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/a-palonskaa/metrics-server/internal/selfmetrics"
+)
+
+// Admission decides whether a series that is not stored yet may be created,
+// an error rejects the update. It is called with the storage locked, so it
+// must not access the storage.
+type Admission func(mType string, series string) error
+
+var admission atomic.Pointer[Admission]
+
+// SetAdmission installs the check of new series, nil accepts every series.
+func SetAdmission(a Admission) {
+	if a == nil {
+		admission.Store(nil)
+		return
+	}
+	admission.Store(&a)
+}
+
+var ErrSeriesLimit = errors.New("series limit exceeded")
+
+// maxSeries is the number of series of all types a storage creates, zero
+// disables the limit. Stored series are still updated once it is reached.
+var maxSeries atomic.Int64
+
+func SetMaxSeries(n int) {
+	maxSeries.Store(int64(n))
+}
+
+// Reasons of the rejected updates counted by the storage.
+const (
+	RejectedAdmission   = "admission"
+	RejectedSeriesLimit = "series_limit"
+)
+
+// check is called with mu held, it returns the reason of a rejection along
+// with the error.
+func (m *MetricsStorage) check(mType string, series string) (string, error) {
+	if m.allowedNames(mType)[series] {
+		return "", nil
+	}
+	if a := admission.Load(); a != nil {
+		if err := (*a)(mType, series); err != nil {
+			return RejectedAdmission, err
+		}
+	}
+	if limit := int(maxSeries.Load()); limit > 0 && m.seriesCount() >= limit {
+		return RejectedSeriesLimit, fmt.Errorf("%w: at most %d series are stored", ErrSeriesLimit, limit)
+	}
+	return "", nil
+}
+
+// admit is called with mu held by the updates, the rejected ones are counted.
+func (m *MetricsStorage) admit(mType string, series string) error {
+	reason, err := m.check(mType, series)
+	if err != nil {
+		selfmetrics.CountRejected(reason)
+	}
+	return err
+}
+
+// Admit returns the error an update of a series would be rejected with, nil
+// if it would be stored. The rejection is not counted.
+func (m *MetricsStorage) Admit(mType string, series string) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, err := m.check(mType, series)
+	return err
+}
+
+// seriesCount is called with mu held.
+func (m *MetricsStorage) seriesCount() int {
+	n := 0
+	for _, mType := range metricTypes {
+		n += len(m.allowedNames(mType))
+	}
+	return n
+}
+
+// SeriesCount returns the number of stored series of all types.
+func (m *MetricsStorage) SeriesCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.seriesCount()
+}
@@ -0,0 +1,40 @@
+package metricsstorage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	"github.com/a-palonskaa/metrics-server/internal/selfmetrics"
+)
+
+func TestMetricsStorage_MaxSeries(t *testing.T) {
+	SetMaxSeries(2)
+	defer SetMaxSeries(0)
+
+	storage := NewMetricsStorage()
+	before := selfmetrics.RejectedUpdates()[RejectedSeriesLimit]
+
+	require.NoError(t, storage.AddGauge("Alloc", 1))
+	require.NoError(t, storage.AddCounter("PollCount", 1))
+	assert.ErrorIs(t, storage.AddGauge("Frees", 1), ErrSeriesLimit)
+	assert.ErrorIs(t, storage.AddHistogram("Latency", metrics.NewHistogram(nil)), ErrSeriesLimit)
+	assert.Equal(t, 2, storage.SeriesCount())
+	assert.Equal(t, before+2, selfmetrics.RejectedUpdates()[RejectedSeriesLimit])
+
+	// stored series are still updated, and the check alone is not counted
+	assert.NoError(t, storage.AddGauge("Alloc", 2))
+	assert.NoError(t, storage.Admit(metrics.GaugeName, "Alloc"))
+	assert.ErrorIs(t, storage.Admit(metrics.GaugeName, "Frees"), ErrSeriesLimit)
+	assert.Equal(t, before+2, selfmetrics.RejectedUpdates()[RejectedSeriesLimit])
+	val, ok := storage.GetGaugeValue("Alloc")
+	require.True(t, ok)
+	assert.Equal(t, metrics.Gauge(2), val)
+
+	// deleting a series frees room for another one
+	storage.DeleteSeries(func(mType, series string) bool { return series == "Alloc" })
+	assert.NoError(t, storage.AddGauge("Frees", 1))
+	assert.Equal(t, []string{"Frees"}, storage.Series(metrics.GaugeName))
+}
@@ -0,0 +1,153 @@
+package metricsstorage
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// TTLRule marks the series of Type (every type if empty) whose name matches
+// Pattern (every name if nil) as stale once they are not updated for TTL.
+type TTLRule struct {
+	Type    string
+	Pattern *regexp.Regexp
+	TTL     time.Duration
+}
+
+// TTLRules are checked in order, the first rule matching a series applies.
+// Series no rule matches never expire.
+var TTLRules []TTLRule
+
+// EvictAfter is how long a stale series is kept, and listed as stale, before
+// it is removed by ExpireSeries.
+var EvictAfter = 10 * time.Minute
+
+// ExpiryCheckInterval is how often RunExpiryRoutine evicts stale series.
+const ExpiryCheckInterval = 10 * time.Second
+
+func SetTTLRules(rules []TTLRule, evictAfter time.Duration) {
+	TTLRules = rules
+	EvictAfter = evictAfter
+}
+
+// ParseTTLRule parses a rule of the forms
+//
+//	gauge=10m          every gauge
+//	counter:^tmp_=1m   counters whose series name matches ^tmp_
+//	^agent_=5m         series of any type whose name starts with agent_
+//
+// The pattern is an unanchored regular expression on the series name
+// including its labels.
+func ParseTTLRule(s string) (TTLRule, error) {
+	i := strings.LastIndex(s, "=")
+	if i < 0 {
+		return TTLRule{}, fmt.Errorf("ttl rule %q: expected <selector>=<ttl>", s)
+	}
+	selector, ttlStr := s[:i], s[i+1:]
+
+	ttl, err := time.ParseDuration(ttlStr)
+	if err != nil {
+		return TTLRule{}, fmt.Errorf("ttl rule %q: %w", s, err)
+	}
+	if ttl <= 0 {
+		return TTLRule{}, fmt.Errorf("ttl rule %q: ttl must be positive", s)
+	}
+	rule := TTLRule{TTL: ttl}
+
+	pattern := selector
+	if IsTypeAllowed(selector) {
+		rule.Type, pattern = selector, ""
+	} else if mType, rest, ok := strings.Cut(selector, ":"); ok && IsTypeAllowed(mType) {
+		rule.Type, pattern = mType, rest
+	}
+	if pattern != "" {
+		if rule.Pattern, err = regexp.Compile(pattern); err != nil {
+			return TTLRule{}, fmt.Errorf("ttl rule %q: %w", s, err)
+		}
+	}
+	if rule.Type == "" && rule.Pattern == nil {
+		return TTLRule{}, fmt.Errorf("ttl rule %q: empty selector", s)
+	}
+	return rule, nil
+}
+
+func (r TTLRule) matches(mType string, series string) bool {
+	return (r.Type == "" || r.Type == mType) && (r.Pattern == nil || r.Pattern.MatchString(series))
+}
+
+func ttlOf(mType string, series string) (time.Duration, bool) {
+	for _, rule := range TTLRules {
+		if rule.matches(mType, series) {
+			return rule.TTL, true
+		}
+	}
+	return 0, false
+}
+
+// idle returns how long a series has not been updated. It is called with mu
+// held.
+func (m *MetricsStorage) idle(mType string, series string, t time.Time) time.Duration {
+	return t.Sub(m.updatedAt(mType, series))
+}
+
+// updatedAt returns when a series was last updated. Series not updated since
+// the storage was created or restored count from then. It is called with mu
+// held.
+func (m *MetricsStorage) updatedAt(mType string, series string) time.Time {
+	last, ok := m.updated[seriesKey{mType: mType, series: series}]
+	if !ok || last.Before(m.since) {
+		last = m.since
+	}
+	return last
+}
+
+// IsStale reports whether a series has not been updated for its TTL.
+func (m *MetricsStorage) IsStale(mType string, series string) bool {
+	ttl, ok := ttlOf(mType, series)
+	if !ok {
+		return false
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.idle(mType, series, now()) >= ttl
+}
+
+// ExpireSeries removes the series stale for longer than EvictAfter with their
+// history, and returns them by type.
+func (m *MetricsStorage) ExpireSeries() map[string][]string {
+	if len(TTLRules) == 0 {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := now()
+	expired := make(map[string][]string)
+	for _, mType := range metricTypes {
+		for _, series := range m.storedNames(mType) {
+			ttl, ok := ttlOf(mType, series)
+			if ok && m.idle(mType, series, t) >= ttl+EvictAfter {
+				m.deleteSeries(mType, series)
+				expired[mType] = append(expired[mType], series)
+			}
+		}
+	}
+	return expired
+}
+
+func RunExpiryRoutine(interval time.Duration) {
+	go func() {
+		for {
+			time.Sleep(interval)
+			for mType, series := range MS.ExpireSeries() {
+				log.Info().Str("type", mType).Strs("series", series).Msg("stale series evicted")
+			}
+		}
+	}()
+}
@@ -0,0 +1,132 @@
+package metricsstorage
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+)
+
+func TestParseTTLRule(t *testing.T) {
+	tests := []struct {
+		input   string
+		mType   string
+		pattern string
+		ttl     time.Duration
+		wantErr bool
+	}{
+		{input: "gauge=10m", mType: metrics.GaugeName, ttl: 10 * time.Minute},
+		{input: "counter:^tmp_=1m", mType: metrics.CounterName, pattern: "^tmp_", ttl: time.Minute},
+		{input: `^agent_.*{host="a"}=5m`, pattern: `^agent_.*{host="a"}`, ttl: 5 * time.Minute},
+		{input: "cluster:load=1h", pattern: "cluster:load", ttl: time.Hour},
+		{input: "gauge", wantErr: true},
+		{input: "gauge=soon", wantErr: true},
+		{input: "gauge=-1m", wantErr: true},
+		{input: "=1m", wantErr: true},
+		{input: "(=1m", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			rule, err := ParseTTLRule(test.input)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.mType, rule.Type)
+			assert.Equal(t, test.ttl, rule.TTL)
+			if test.pattern == "" {
+				assert.Nil(t, rule.Pattern)
+			} else {
+				assert.Equal(t, test.pattern, rule.Pattern.String())
+			}
+		})
+	}
+}
+
+func TestMemStorage_ExpireSeries(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	current := base
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	rules := TTLRules
+	evictAfter := EvictAfter
+	defer SetTTLRules(rules, evictAfter)
+
+	tmp, err := ParseTTLRule("gauge:^tmp_=1m")
+	require.NoError(t, err)
+	gauges, err := ParseTTLRule("gauge=10m")
+	require.NoError(t, err)
+	SetTTLRules([]TTLRule{tmp, gauges}, 5*time.Minute)
+
+	storage := NewMetricsStorage()
+	storage.AddGauge("tmp_load", 1)
+	storage.AddGauge("load", 1)
+	storage.AddCounter("tmp_requests", 1)
+
+	current = base.Add(2 * time.Minute)
+	assert.True(t, storage.IsStale(metrics.GaugeName, "tmp_load"))
+	assert.False(t, storage.IsStale(metrics.GaugeName, "load"))
+	assert.False(t, storage.IsStale(metrics.CounterName, "tmp_requests"))
+	assert.Empty(t, storage.ExpireSeries())
+
+	current = base.Add(6 * time.Minute)
+	assert.Equal(t, map[string][]string{metrics.GaugeName: {"tmp_load"}}, storage.ExpireSeries())
+	_, ok := storage.GetGaugeValue("tmp_load")
+	assert.False(t, ok)
+
+	// an update makes a series fresh again
+	storage.AddGauge("load", 2)
+	current = base.Add(15 * time.Minute)
+	assert.False(t, storage.IsStale(metrics.GaugeName, "load"))
+	current = base.Add(20 * time.Minute)
+	assert.True(t, storage.IsStale(metrics.GaugeName, "load"))
+	assert.Empty(t, storage.ExpireSeries())
+	current = base.Add(21 * time.Minute)
+	assert.Equal(t, map[string][]string{metrics.GaugeName: {"load"}}, storage.ExpireSeries())
+
+	// series restored without an update time count from the restore
+	storage.GaugeMetrics["restored"] = 1
+	storage.AllowedGaugeNames["restored"] = true
+	storage.since = current
+	assert.False(t, storage.IsStale(metrics.GaugeName, "restored"))
+	current = current.Add(10 * time.Minute)
+	assert.True(t, storage.IsStale(metrics.GaugeName, "restored"))
+	assert.Equal(t, []string{"tmp_requests"}, storage.Series(metrics.CounterName))
+}
+
+func TestMemStorage_ExpireRuntimeSeries(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	current := base
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	rules := TTLRules
+	evictAfter := EvictAfter
+	defer SetTTLRules(rules, evictAfter)
+
+	gauges, err := ParseTTLRule("gauge=1m")
+	require.NoError(t, err)
+	SetTTLRules([]TTLRule{gauges}, time.Minute)
+
+	storage := NewMetricsStorage()
+	storage.Update(&runtime.MemStats{})
+
+	current = base.Add(5 * time.Minute)
+	assert.Len(t, storage.ExpireSeries()[metrics.GaugeName], len(runtimeGauges))
+	_, ok := storage.GetGaugeValue("Alloc")
+	assert.False(t, ok)
+
+	// the next update brings the runtime series back
+	storage.Update(&runtime.MemStats{})
+	_, ok = storage.GetGaugeValue("Alloc")
+	assert.True(t, ok)
+	assert.False(t, storage.IsStale(metrics.GaugeName, "Alloc"))
+	assert.Equal(t, 0, storage.Compact())
+}
@@ -0,0 +1,157 @@
+package metricsstorage
+
+import (
+	"strings"
+	"time"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+)
+
+type Sample struct {
+	Time  time.Time
+	Value float64
+}
+
+// RateWindows are the windows derived counter gauges are listed for.
+var RateWindows = []time.Duration{time.Minute, 5 * time.Minute}
+
+// now is replaced in tests.
+var now = time.Now
+
+// minRetention keeps counter samples for windows wider than RateWindows,
+// see RetainCounterHistory.
+var minRetention time.Duration
+
+// historyRetention is how long counter samples are kept, enough to cover the
+// widest rate window.
+func historyRetention() time.Duration {
+	retention := minRetention
+	for _, w := range RateWindows {
+		retention = max(retention, w)
+	}
+	return retention
+}
+
+func SetRateWindows(windows []time.Duration) {
+	RateWindows = windows
+}
+
+// RetainCounterHistory keeps enough counter samples to compute increases
+// over window, even if it is wider than every RateWindows entry.
+func RetainCounterHistory(window time.Duration) {
+	minRetention = max(minRetention, window)
+}
+
+// SampleResolution bounds the counter samples to one per series per interval,
+// a later update within the same interval replaces the last sample unless it
+// is the first one, which stays as the baseline.
+var SampleResolution = time.Second
+
+// recordCounterSample records a change of a counter from prev to val. Samples
+// hold the increase of the counter since its first sample, so that replacing a
+// sample keeps the increase before it. A decrease is treated as a counter
+// reset, in which case the value after the reset is counted as the increase.
+func (m *MetricsStorage) recordCounterSample(name string, prev metrics.Counter, val metrics.Counter) {
+	if m.counterHistory == nil {
+		m.counterHistory = make(map[string][]Sample)
+	}
+
+	t := now()
+	samples := m.counterHistory[name]
+	n := len(samples)
+
+	var total float64
+	if n > 0 {
+		delta := val - prev
+		if delta < 0 {
+			delta = val
+		}
+		total = samples[n-1].Value + float64(delta)
+	}
+
+	if n > 1 && samples[n-1].Time.Truncate(SampleResolution).Equal(t.Truncate(SampleResolution)) {
+		samples[n-1] = Sample{Time: t, Value: total}
+	} else {
+		samples = append(samples, Sample{Time: t, Value: total})
+	}
+
+	// keep one sample older than the retention as the baseline of the
+	// widest window
+	m.counterHistory[name] = trimSamples(samples, t.Add(-historyRetention()))
+}
+
+// CounterIncrease returns how much the counter grew during the last window.
+// A decrease between two samples is treated as a counter reset, in which case
+// the value after the reset is counted as the increase.
+func (m *MetricsStorage) CounterIncrease(name string, window time.Duration) (metrics.Gauge, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.counterIncrease(name, window)
+}
+
+func (m *MetricsStorage) counterIncrease(name string, window time.Duration) (metrics.Gauge, bool) {
+	if !m.AllowedCounterNames[name] {
+		return 0, false
+	}
+
+	samples := m.counterHistory[name]
+	start := now().Add(-window)
+
+	// the last sample at or before the start of the window is the baseline
+	first := 0
+	for first+1 < len(samples) && !samples[first+1].Time.After(start) {
+		first++
+	}
+
+	if len(samples) == 0 {
+		return 0, true
+	}
+	return metrics.Gauge(samples[len(samples)-1].Value - samples[first].Value), true
+}
+
+// CounterRate returns the per-second increase of the counter over the last
+// window.
+func (m *MetricsStorage) CounterRate(name string, window time.Duration) (metrics.Gauge, bool) {
+	increase, ok := m.CounterIncrease(name, window)
+	if !ok || window <= 0 {
+		return 0, ok
+	}
+	return increase / metrics.Gauge(window.Seconds()), true
+}
+
+// DerivedGauges returns virtual gauges "<counter>:rate_<window>" and
+// "<counter>:increase_<window>" for every counter and every RateWindows entry.
+// Labels of the counter are kept on the derived gauges.
+func (m *MetricsStorage) DerivedGauges() map[string]metrics.Gauge {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	derived := make(map[string]metrics.Gauge)
+	for series := range m.AllowedCounterNames {
+		name, labels, err := metrics.ParseSeriesName(series)
+		if err != nil {
+			continue
+		}
+		for _, window := range RateWindows {
+			suffix := FormatWindow(window)
+			increase, _ := m.counterIncrease(series, window)
+			derived[metrics.SeriesName(name+":increase_"+suffix, labels)] = increase
+			derived[metrics.SeriesName(name+":rate_"+suffix, labels)] = increase / metrics.Gauge(window.Seconds())
+		}
+	}
+	return derived
+}
+
+// FormatWindow renders a window without zero trailing units, e.g. "5m"
+// instead of "5m0s".
+func FormatWindow(window time.Duration) string {
+	s := window.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
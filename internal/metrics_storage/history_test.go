@@ -0,0 +1,120 @@
+package metricsstorage
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+)
+
+func TestMemStorage_CounterIncrease(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	current := base
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	type update struct {
+		after time.Duration
+		delta metrics.Counter
+	}
+
+	tests := []struct {
+		name         string
+		updates      []update
+		window       time.Duration
+		wantIncrease metrics.Gauge
+		wantRate     metrics.Gauge
+	}{
+		{
+			name:         "new-counter",
+			updates:      []update{{after: 0, delta: 6}},
+			window:       time.Minute,
+			wantIncrease: 6,
+			wantRate:     0.1,
+		},
+		{
+			name: "old-samples-outside-window",
+			updates: []update{
+				{after: 0, delta: 100},
+				{after: 2 * time.Minute, delta: 30},
+				{after: 2*time.Minute + 30*time.Second, delta: 30},
+			},
+			window:       time.Minute,
+			wantIncrease: 60,
+			wantRate:     1,
+		},
+		{
+			name: "counter-reset",
+			updates: []update{
+				{after: 0, delta: 100},
+				{after: 10 * time.Second, delta: -100},
+				{after: 20 * time.Second, delta: 12},
+			},
+			window:       time.Minute,
+			wantIncrease: 112,
+			wantRate:     112.0 / 60,
+		},
+		{
+			name: "no-updates-in-window",
+			updates: []update{
+				{after: 0, delta: 100},
+				{after: 10 * time.Minute, delta: 0},
+			},
+			window:       time.Minute,
+			wantIncrease: 0,
+			wantRate:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := &MetricsStorage{
+				mu:                  new(sync.RWMutex),
+				CounterMetrics:      make(map[string]metrics.Counter),
+				AllowedCounterNames: make(map[string]bool),
+			}
+			for _, u := range tt.updates {
+				current = base.Add(u.after)
+				ms.AddCounter("requests", u.delta)
+			}
+
+			increase, ok := ms.CounterIncrease("requests", tt.window)
+			assert.True(t, ok)
+			assert.InDelta(t, float64(tt.wantIncrease), float64(increase), 1e-9)
+
+			rate, ok := ms.CounterRate("requests", tt.window)
+			assert.True(t, ok)
+			assert.InDelta(t, float64(tt.wantRate), float64(rate), 1e-9)
+		})
+	}
+}
+
+func TestMemStorage_CounterSamplesPerSecond(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	current := base
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	ms := NewMetricsStorage()
+	for i := range 1000 {
+		current = base.Add(time.Duration(i) * time.Millisecond)
+		ms.AddCounter("requests", 1)
+	}
+	current = base.Add(time.Second)
+	ms.AddCounter("requests", 1)
+
+	// the baseline of the new counter and one sample per second
+	assert.Len(t, ms.counterHistory["requests"], 3)
+	increase, _ := ms.CounterIncrease("requests", time.Minute)
+	assert.InDelta(t, 1001, float64(increase), 1e-9)
+}
+
+func TestFormatWindow(t *testing.T) {
+	assert.Equal(t, "30s", FormatWindow(30*time.Second))
+	assert.Equal(t, "5m", FormatWindow(5*time.Minute))
+	assert.Equal(t, "1h", FormatWindow(time.Hour))
+	assert.Equal(t, "1h30m", FormatWindow(90*time.Minute))
+}
@@ -0,0 +1,63 @@
+package metricsstorage
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+)
+
+// UpdateHook receives the new value of every series changed through the
+// storage methods. It is called with the storage locked, so it must not
+// block or access the storage.
+type UpdateHook func(series string, mType string, val fmt.Stringer)
+
+var updateHook atomic.Pointer[UpdateHook]
+
+// SetUpdateHook installs hook, nil removes it. Histograms, summaries and sets
+// are only cloned for the hook while one is installed.
+func SetUpdateHook(hook UpdateHook) {
+	if hook == nil {
+		updateHook.Store(nil)
+		return
+	}
+	updateHook.Store(&hook)
+}
+
+// notify records the update time and chart point of a changed series and
+// passes its stored value to the update hook. It is called with mu held.
+func (m *MetricsStorage) notify(mType string, name string) {
+	m.touch(mType, name)
+	switch mType {
+	case metrics.GaugeName:
+		m.recordPoint(mType, name, float64(m.GaugeMetrics[name]))
+	case metrics.CounterName:
+		m.recordPoint(mType, name, float64(m.CounterMetrics[name]))
+	case metrics.HistogramName:
+		m.recordPoint(mType, name, float64(m.HistogramMetrics[name].Count))
+	case metrics.SummaryName:
+		m.recordPoint(mType, name, float64(m.SummaryMetrics[name].Count))
+	}
+
+	hook := updateHook.Load()
+	if hook == nil {
+		return
+	}
+
+	var val fmt.Stringer
+	switch mType {
+	case metrics.GaugeName:
+		val = m.GaugeMetrics[name]
+	case metrics.CounterName:
+		val = m.CounterMetrics[name]
+	case metrics.HistogramName:
+		val = m.HistogramMetrics[name].Clone()
+	case metrics.SummaryName:
+		val = m.SummaryMetrics[name].Clone()
+	case metrics.SetName:
+		val = m.SetMetrics[name].Clone()
+	default:
+		return
+	}
+	(*hook)(name, mType, val)
+}
@@ -2,8 +2,12 @@ package metricsstorage
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
 	"runtime"
+	"sort"
+	"sync"
+	"time"
 
 	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
 )
@@ -13,86 +17,511 @@ type MetricsStorage struct {
 	GaugeMetrics   map[string]metrics.Gauge
 	CounterMetrics map[string]metrics.Counter
 
-	AllowedGaugeNames   map[string]bool
-	AllowedCounterNames map[string]bool
+	HistogramMetrics map[string]metrics.Histogram
+	SummaryMetrics   map[string]metrics.Summary
+	SetMetrics       map[string]metrics.Set
+
+	AllowedGaugeNames     map[string]bool
+	AllowedCounterNames   map[string]bool
+	AllowedHistogramNames map[string]bool
+	AllowedSummaryNames   map[string]bool
+	AllowedSetNames       map[string]bool
+
+	// mu guards the storage, it is a pointer so that easyjson can marshal
+	// the storage by value
+	mu             *sync.RWMutex
+	lastNumGC      uint32
+	counterHistory map[string][]Sample
+	timeline       map[seriesKey][]Sample
+	updated        map[seriesKey]time.Time
+	// since is when the storage was created or restored
+	since time.Time
 }
 
+// GCPauseBuckets cover GC pauses from 10µs up to ~1.3s (in nanoseconds).
+var GCPauseBuckets = metrics.ExponentialBuckets(10_000, 4, 9)
+
 var MS = &MetricsStorage{
 	GaugeMetrics:   make(map[string]metrics.Gauge),
 	CounterMetrics: make(map[string]metrics.Counter),
 
-	AllowedGaugeNames: map[string]bool{
-		"Alloc": true, "BuckHashSys": true, "Frees": true, "GCCPUFraction": true, "GCSys": true,
-		"HeapAlloc": true, "HeapIdle": true, "HeapInuse": true, "HeapObjects": true, "HeapReleased": true,
-		"LastGC": true, "Lookups": true, "MCacheInuse": true, "MCacheSys": true, "MSpanInuse": true,
-		"MSpanSys": true, "Mallocs": true, "NextGC": true, "NumForcedGC": true, "NumGC": true, "OtherSys": true,
-		"PauseTotalNs": true, "StackInuse": true, "StackSys": true, "Sys": true, "TotalAlloc": true,
-		"RandomValue": true, "HeapSys": true},
-	AllowedCounterNames: map[string]bool{"PollCount": true},
+	HistogramMetrics: map[string]metrics.Histogram{
+		"GCPauseNs": metrics.NewHistogram(GCPauseBuckets),
+	},
+	SummaryMetrics: map[string]metrics.Summary{
+		"GCPauseQuantiles": metrics.NewSummary(metrics.DefaultSummaryAccuracy),
+	},
+	SetMetrics: make(map[string]metrics.Set),
+
+	AllowedGaugeNames:     runtimeGaugeNames(),
+	AllowedCounterNames:   map[string]bool{"PollCount": true},
+	AllowedHistogramNames: map[string]bool{"GCPauseNs": true},
+	AllowedSummaryNames:   map[string]bool{"GCPauseQuantiles": true},
+	AllowedSetNames:       make(map[string]bool),
+
+	mu:    new(sync.RWMutex),
+	since: now(),
+}
+
+// runtimeGauges are the gauges refreshed by Update.
+var runtimeGauges = []struct {
+	name  string
+	value func(memStats *runtime.MemStats) metrics.Gauge
+}{
+	{"Alloc", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.Alloc) }},
+	{"BuckHashSys", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.BuckHashSys) }},
+	{"Frees", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.Frees) }},
+	{"GCCPUFraction", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.GCCPUFraction) }},
+	{"GCSys", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.GCSys) }},
+	{"HeapAlloc", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.HeapAlloc) }},
+	{"HeapIdle", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.HeapIdle) }},
+	{"HeapInuse", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.HeapInuse) }},
+	{"HeapObjects", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.HeapObjects) }},
+	{"HeapReleased", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.HeapReleased) }},
+	{"LastGC", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.LastGC) }},
+	{"Lookups", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.Lookups) }},
+	{"MCacheInuse", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.MCacheInuse) }},
+	{"MCacheSys", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.MCacheSys) }},
+	{"MSpanInuse", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.MSpanInuse) }},
+	{"MSpanSys", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.MSpanSys) }},
+	{"Mallocs", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.Mallocs) }},
+	{"NextGC", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.NextGC) }},
+	{"NumForcedGC", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.NumForcedGC) }},
+	{"NumGC", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.NumGC) }},
+	{"OtherSys", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.OtherSys) }},
+	{"PauseTotalNs", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.PauseTotalNs) }},
+	{"StackInuse", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.StackInuse) }},
+	{"StackSys", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.StackSys) }},
+	{"Sys", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.Sys) }},
+	{"TotalAlloc", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.TotalAlloc) }},
+	{"HeapSys", func(ms *runtime.MemStats) metrics.Gauge { return metrics.Gauge(ms.HeapSys) }},
+	{"RandomValue", func(*runtime.MemStats) metrics.Gauge { return metrics.Gauge(rand.Float64()) }},
+}
+
+func runtimeGaugeNames() map[string]bool {
+	names := make(map[string]bool, len(runtimeGauges))
+	for _, g := range runtimeGauges {
+		names[g.name] = true
+	}
+	return names
+}
+
+// NewMetricsStorage returns an empty storage without the predefined runtime
+// metrics of MS.
+func NewMetricsStorage() *MetricsStorage {
+	return &MetricsStorage{
+		GaugeMetrics:     make(map[string]metrics.Gauge),
+		CounterMetrics:   make(map[string]metrics.Counter),
+		HistogramMetrics: make(map[string]metrics.Histogram),
+		SummaryMetrics:   make(map[string]metrics.Summary),
+		SetMetrics:       make(map[string]metrics.Set),
+
+		AllowedGaugeNames:     make(map[string]bool),
+		AllowedCounterNames:   make(map[string]bool),
+		AllowedHistogramNames: make(map[string]bool),
+		AllowedSummaryNames:   make(map[string]bool),
+		AllowedSetNames:       make(map[string]bool),
+
+		mu:    new(sync.RWMutex),
+		since: now(),
+	}
 }
 
 func (m *MetricsStorage) IsGaugeAllowed(name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	return m.AllowedGaugeNames[name]
 }
 
 func (m *MetricsStorage) IsCounterAllowed(name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	return m.AllowedCounterNames[name]
 }
 
+func (m *MetricsStorage) IsHistogramAllowed(name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.AllowedHistogramNames[name]
+}
+
+func (m *MetricsStorage) IsSummaryAllowed(name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.AllowedSummaryNames[name]
+}
+
+func (m *MetricsStorage) IsSetAllowed(name string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.AllowedSetNames[name]
+}
+
+// allowedNames returns the names map of a type, nil for unknown types.
+func (m *MetricsStorage) allowedNames(mType string) map[string]bool {
+	switch mType {
+	case metrics.GaugeName:
+		return m.AllowedGaugeNames
+	case metrics.CounterName:
+		return m.AllowedCounterNames
+	case metrics.HistogramName:
+		return m.AllowedHistogramNames
+	case metrics.SummaryName:
+		return m.AllowedSummaryNames
+	case metrics.SetName:
+		return m.AllowedSetNames
+	}
+	return nil
+}
+
+// Series returns the sorted names of the known series of a type.
+func (m *MetricsStorage) Series(mType string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.allowedNames(mType)))
+	for name, ok := range m.allowedNames(mType) {
+		if ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (m *MetricsStorage) IsNameAllowed(mType, name string) bool {
 	switch mType {
 	case metrics.GaugeName:
 		return m.IsGaugeAllowed(name)
 	case metrics.CounterName:
 		return m.IsCounterAllowed(name)
+	case metrics.HistogramName:
+		return m.IsHistogramAllowed(name)
+	case metrics.SummaryName:
+		return m.IsSummaryAllowed(name)
+	case metrics.SetName:
+		return m.IsSetAllowed(name)
 	}
 	return false
 }
 
-func (m *MetricsStorage) AddGauge(name string, val metrics.Gauge) {
-	if !m.IsGaugeAllowed(name) {
+func (m *MetricsStorage) AddGauge(name string, val metrics.Gauge) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.admit(metrics.GaugeName, name); err != nil {
+		return err
+	}
+
+	if !m.AllowedGaugeNames[name] {
 		m.AllowedGaugeNames[name] = true
 	}
 	m.GaugeMetrics[name] = val
+	m.notify(metrics.GaugeName, name)
+	return nil
+}
+
+// AdjustGauge adds delta to the stored gauge, a missing gauge starts at 0.
+func (m *MetricsStorage) AdjustGauge(name string, delta metrics.Gauge) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.admit(metrics.GaugeName, name); err != nil {
+		return err
+	}
+
+	m.AllowedGaugeNames[name] = true
+	m.GaugeMetrics[name] += delta
+	m.notify(metrics.GaugeName, name)
+	return nil
 }
 
-func (m *MetricsStorage) AddCounter(name string, val metrics.Counter) {
-	if !m.IsCounterAllowed(name) {
+func (m *MetricsStorage) AddCounter(name string, val metrics.Counter) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.admit(metrics.CounterName, name); err != nil {
+		return err
+	}
+
+	if !m.AllowedCounterNames[name] {
 		m.AllowedCounterNames[name] = true
 	}
+	prev := m.CounterMetrics[name]
+	if len(m.counterHistory[name]) == 0 {
+		m.recordCounterSample(name, prev, prev)
+	}
 	m.CounterMetrics[name] += val
+	m.recordCounterSample(name, prev, m.CounterMetrics[name])
+	m.notify(metrics.CounterName, name)
+	return nil
+}
+
+// AddHistogram merges bucket counts, sum and count of val into the stored
+// histogram. The first report for a name fixes its bucket boundaries.
+func (m *MetricsStorage) AddHistogram(name string, val metrics.Histogram) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.admit(metrics.HistogramName, name); err != nil {
+		return err
+	}
+
+	if err := val.Validate(); err != nil {
+		return err
+	}
+
+	stored, ok := m.HistogramMetrics[name]
+	if !ok || !m.AllowedHistogramNames[name] {
+		m.AllowedHistogramNames[name] = true
+		m.HistogramMetrics[name] = val.Clone()
+		m.notify(metrics.HistogramName, name)
+		return nil
+	}
+
+	if err := stored.Merge(val); err != nil {
+		return err
+	}
+	m.HistogramMetrics[name] = stored
+	m.notify(metrics.HistogramName, name)
+	return nil
+}
+
+// ObserveHistogram records a single observation, creating the histogram
+// with metrics.DefaultBuckets if it does not exist yet.
+func (m *MetricsStorage) ObserveHistogram(name string, val float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		return fmt.Errorf("histogram observation must be finite, got %v", val)
+	}
+
+	if err := m.admit(metrics.HistogramName, name); err != nil {
+		return err
+	}
+
+	stored, ok := m.HistogramMetrics[name]
+	if !ok {
+		stored = metrics.NewHistogram(metrics.DefaultBuckets)
+	}
+	m.AllowedHistogramNames[name] = true
+	stored.Observe(val)
+	m.HistogramMetrics[name] = stored
+	m.notify(metrics.HistogramName, name)
+	return nil
+}
+
+func (m *MetricsStorage) ResetHistogram(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if stored, ok := m.HistogramMetrics[name]; ok {
+		stored.Reset()
+		m.HistogramMetrics[name] = stored
+		m.notify(metrics.HistogramName, name)
+	}
+}
+
+// AddSummary merges the sketch val into the stored summary. Sketches of one
+// name must share the same accuracy.
+func (m *MetricsStorage) AddSummary(name string, val metrics.Summary) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.admit(metrics.SummaryName, name); err != nil {
+		return err
+	}
+
+	if err := val.Validate(); err != nil {
+		return err
+	}
+
+	stored, ok := m.SummaryMetrics[name]
+	if !ok || !m.AllowedSummaryNames[name] {
+		m.AllowedSummaryNames[name] = true
+		m.SummaryMetrics[name] = val.Clone()
+		m.notify(metrics.SummaryName, name)
+		return nil
+	}
+
+	if err := stored.Merge(val); err != nil {
+		return err
+	}
+	m.SummaryMetrics[name] = stored
+	m.notify(metrics.SummaryName, name)
+	return nil
+}
+
+// ObserveSummary records a single observation, creating the summary with
+// metrics.DefaultSummaryAccuracy if it does not exist yet.
+func (m *MetricsStorage) ObserveSummary(name string, val float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		return fmt.Errorf("summary observation must be finite, got %v", val)
+	}
+
+	if err := m.admit(metrics.SummaryName, name); err != nil {
+		return err
+	}
+
+	stored, ok := m.SummaryMetrics[name]
+	if !ok {
+		stored = metrics.NewSummary(metrics.DefaultSummaryAccuracy)
+	}
+	m.AllowedSummaryNames[name] = true
+	stored.Observe(val)
+	m.SummaryMetrics[name] = stored
+	m.notify(metrics.SummaryName, name)
+	return nil
+}
+
+func (m *MetricsStorage) ResetSummary(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if stored, ok := m.SummaryMetrics[name]; ok {
+		stored.Reset()
+		m.SummaryMetrics[name] = stored
+		m.notify(metrics.SummaryName, name)
+	}
+}
+
+// AddSet merges the HyperLogLog sketch val into the stored set.
+func (m *MetricsStorage) AddSet(name string, val metrics.Set) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.admit(metrics.SetName, name); err != nil {
+		return err
+	}
+
+	if err := val.Validate(); err != nil {
+		return err
+	}
+
+	stored, ok := m.SetMetrics[name]
+	if !ok || !m.AllowedSetNames[name] {
+		m.AllowedSetNames[name] = true
+		m.SetMetrics[name] = val.Clone()
+		m.notify(metrics.SetName, name)
+		return nil
+	}
+
+	if err := stored.Merge(val); err != nil {
+		return err
+	}
+	m.SetMetrics[name] = stored
+	m.notify(metrics.SetName, name)
+	return nil
+}
+
+// AddSetMembers adds raw members to the stored set, creating it with
+// metrics.DefaultSetPrecision if it does not exist yet.
+func (m *MetricsStorage) AddSetMembers(name string, members ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.admit(metrics.SetName, name); err != nil {
+		return err
+	}
+
+	stored, ok := m.SetMetrics[name]
+	if !ok {
+		stored = metrics.NewSet(metrics.DefaultSetPrecision)
+	}
+	m.AllowedSetNames[name] = true
+	for _, member := range members {
+		stored.Add(member)
+	}
+	m.SetMetrics[name] = stored
+	m.notify(metrics.SetName, name)
+	return nil
 }
 
 func (m *MetricsStorage) AddValue(mType, name string, val any) bool {
 	switch mType {
 	case metrics.GaugeName:
 		if v, ok := val.(metrics.Gauge); ok {
-			m.AddGauge(name, v)
-			return true
+			return m.AddGauge(name, v) == nil
 		}
 	case metrics.CounterName:
 		if v, ok := val.(metrics.Counter); ok {
-			m.AddCounter(name, v)
-			return true
+			return m.AddCounter(name, v) == nil
+		}
+	case metrics.HistogramName:
+		if v, ok := val.(metrics.Histogram); ok {
+			return m.AddHistogram(name, v) == nil
+		}
+	case metrics.SummaryName:
+		if v, ok := val.(metrics.Summary); ok {
+			return m.AddSummary(name, v) == nil
+		}
+	case metrics.SetName:
+		if v, ok := val.(metrics.Set); ok {
+			return m.AddSet(name, v) == nil
 		}
 	}
 	return false
 }
 
 func (m *MetricsStorage) GetGaugeValue(name string) (metrics.Gauge, bool) {
-	if m.IsGaugeAllowed(name) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.AllowedGaugeNames[name] {
 		return m.GaugeMetrics[name], true
 	}
 	return 0, false
 }
 
 func (m *MetricsStorage) GetCounterValue(name string) (metrics.Counter, bool) {
-	if m.IsCounterAllowed(name) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.AllowedCounterNames[name] {
 		return m.CounterMetrics[name], true
 	}
 	return 0, false
 }
 
+func (m *MetricsStorage) GetHistogramValue(name string) (metrics.Histogram, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.AllowedHistogramNames[name] {
+		return m.HistogramMetrics[name].Clone(), true
+	}
+	return metrics.Histogram{}, false
+}
+
+func (m *MetricsStorage) GetSummaryValue(name string) (metrics.Summary, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.AllowedSummaryNames[name] {
+		return m.SummaryMetrics[name].Clone(), true
+	}
+	return metrics.Summary{}, false
+}
+
+func (m *MetricsStorage) GetSetValue(name string) (metrics.Set, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.AllowedSetNames[name] {
+		return m.SetMetrics[name].Clone(), true
+	}
+	return metrics.Set{}, false
+}
+
 func (m *MetricsStorage) GetValue(mType, name string) (any, bool) {
 	switch mType {
 	case metrics.GaugeName:
@@ -101,58 +530,119 @@ func (m *MetricsStorage) GetValue(mType, name string) (any, bool) {
 	case metrics.CounterName:
 		val, ok := m.GetCounterValue(name)
 		return val, ok
+	case metrics.HistogramName:
+		val, ok := m.GetHistogramValue(name)
+		return val, ok
+	case metrics.SummaryName:
+		val, ok := m.GetSummaryValue(name)
+		return val, ok
+	case metrics.SetName:
+		val, ok := m.GetSetValue(name)
+		return val, ok
 	default:
 		return nil, false
 	}
 }
 
 func IsTypeAllowed(mType string) bool {
-	return mType == metrics.GaugeName || mType == metrics.CounterName
+	switch mType {
+	case metrics.GaugeName, metrics.CounterName, metrics.HistogramName, metrics.SummaryName, metrics.SetName:
+		return true
+	}
+	return false
 }
 
 func (m *MetricsStorage) Update(memStats *runtime.MemStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	runtime.ReadMemStats(memStats)
 
-	// gauge metrics
-	m.GaugeMetrics["Alloc"] = metrics.Gauge(memStats.Alloc)
-	m.GaugeMetrics["BuckHashSys"] = metrics.Gauge(memStats.BuckHashSys)
-	m.GaugeMetrics["Frees"] = metrics.Gauge(memStats.Frees)
-	m.GaugeMetrics["GCCPUFraction"] = metrics.Gauge(memStats.GCCPUFraction)
-	m.GaugeMetrics["GCSys"] = metrics.Gauge(memStats.GCSys)
-	m.GaugeMetrics["HeapAlloc"] = metrics.Gauge(memStats.HeapAlloc)
-	m.GaugeMetrics["HeapIdle"] = metrics.Gauge(memStats.HeapIdle)
-	m.GaugeMetrics["HeapInuse"] = metrics.Gauge(memStats.HeapInuse)
-	m.GaugeMetrics["HeapObjects"] = metrics.Gauge(memStats.HeapObjects)
-	m.GaugeMetrics["HeapReleased"] = metrics.Gauge(memStats.HeapReleased)
-	m.GaugeMetrics["LastGC"] = metrics.Gauge(memStats.LastGC)
-	m.GaugeMetrics["Lookups"] = metrics.Gauge(memStats.Lookups)
-	m.GaugeMetrics["MCacheInuse"] = metrics.Gauge(memStats.MCacheInuse)
-	m.GaugeMetrics["MCacheSys"] = metrics.Gauge(memStats.MCacheSys)
-	m.GaugeMetrics["MSpanInuse"] = metrics.Gauge(memStats.MSpanInuse)
-	m.GaugeMetrics["MSpanSys"] = metrics.Gauge(memStats.MSpanSys)
-	m.GaugeMetrics["Mallocs"] = metrics.Gauge(memStats.Mallocs)
-	m.GaugeMetrics["NextGC"] = metrics.Gauge(memStats.NextGC)
-	m.GaugeMetrics["NumForcedGC"] = metrics.Gauge(memStats.NumForcedGC)
-	m.GaugeMetrics["NumGC"] = metrics.Gauge(memStats.NumGC)
-	m.GaugeMetrics["OtherSys"] = metrics.Gauge(memStats.OtherSys)
-	m.GaugeMetrics["PauseTotalNs"] = metrics.Gauge(memStats.PauseTotalNs)
-	m.GaugeMetrics["StackInuse"] = metrics.Gauge(memStats.StackInuse)
-	m.GaugeMetrics["StackSys"] = metrics.Gauge(memStats.StackSys)
-	m.GaugeMetrics["Sys"] = metrics.Gauge(memStats.Sys)
-	m.GaugeMetrics["TotalAlloc"] = metrics.Gauge(memStats.TotalAlloc)
-	m.GaugeMetrics["HeapSys"] = metrics.Gauge(memStats.HeapSys)
-	m.GaugeMetrics["RandomValue"] = metrics.Gauge(rand.Float64())
-
-	// counter metrics
+	// the runtime metrics are allowed again if they were deleted or evicted,
+	// and they are current after every update, so they never go stale
+	for _, g := range runtimeGauges {
+		m.GaugeMetrics[g.name] = g.value(memStats)
+		m.AllowedGaugeNames[g.name] = true
+		m.touch(metrics.GaugeName, g.name)
+	}
+
 	m.CounterMetrics["PollCount"]++
+	m.AllowedCounterNames["PollCount"] = true
+	m.touch(metrics.CounterName, "PollCount")
 }
 
+// ObserveGCPauses feeds the pauses of collections finished since the previous
+// call into the GCPauseNs histogram and the GCPauseQuantiles summary. Only the
+// agent observes its pauses, with memStats as read by Update, the server keeps
+// these series for the pauses reported by agents. PauseNs is a circular buffer
+// holding the most recent 256 pauses, older ones are lost.
+func (m *MetricsStorage) ObserveGCPauses(memStats *runtime.MemStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hist, ok := m.HistogramMetrics["GCPauseNs"]
+	if !ok {
+		hist = metrics.NewHistogram(GCPauseBuckets)
+	}
+	summary, ok := m.SummaryMetrics["GCPauseQuantiles"]
+	if !ok {
+		summary = metrics.NewSummary(metrics.DefaultSummaryAccuracy)
+	}
+
+	size := uint32(len(memStats.PauseNs))
+	from := min(m.lastNumGC, memStats.NumGC)
+	if memStats.NumGC-from > size {
+		from = memStats.NumGC - size
+	}
+	for i := from; i < memStats.NumGC; i++ {
+		pause := float64(memStats.PauseNs[i%size])
+		hist.Observe(pause)
+		summary.Observe(pause)
+	}
+	m.lastNumGC = memStats.NumGC
+
+	m.AllowedHistogramNames["GCPauseNs"] = true
+	m.HistogramMetrics["GCPauseNs"] = hist
+	m.AllowedSummaryNames["GCPauseQuantiles"] = true
+	m.SummaryMetrics["GCPauseQuantiles"] = summary
+
+	m.touch(metrics.HistogramName, "GCPauseNs")
+	m.touch(metrics.SummaryName, "GCPauseQuantiles")
+}
+
+// Iterate calls f for every stored metric. f is called on a snapshot taken
+// under the read lock, so it may modify the storage.
 func (m *MetricsStorage) Iterate(f func(string, string, fmt.Stringer)) {
+	type entry struct {
+		name  string
+		mType string
+		val   fmt.Stringer
+	}
+
+	m.mu.RLock()
+	entries := make([]entry, 0, len(m.GaugeMetrics)+len(m.CounterMetrics))
 	for key, value := range m.GaugeMetrics {
-		f(key, metrics.GaugeName, value)
+		entries = append(entries, entry{key, metrics.GaugeName, value})
 	}
 
 	for key, value := range m.CounterMetrics {
-		f(key, metrics.CounterName, value)
+		entries = append(entries, entry{key, metrics.CounterName, value})
+	}
+
+	for key, value := range m.HistogramMetrics {
+		entries = append(entries, entry{key, metrics.HistogramName, value.Clone()})
+	}
+
+	for key, value := range m.SummaryMetrics {
+		entries = append(entries, entry{key, metrics.SummaryName, value.Clone()})
+	}
+
+	for key, value := range m.SetMetrics {
+		entries = append(entries, entry{key, metrics.SetName, value.Clone()})
+	}
+	m.mu.RUnlock()
+
+	for _, e := range entries {
+		f(e.name, e.mType, e.val)
 	}
 }
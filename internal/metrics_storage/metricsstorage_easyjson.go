@@ -69,6 +69,54 @@ func easyjson138723c2DecodeGithubComAPalonskaaMetricsServerInternalMetricsStorag
 				}
 				in.Delim('}')
 			}
+		case "HistogramMetrics":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.HistogramMetrics = make(map[string]metrics.Histogram)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v3 metrics.Histogram
+					(v3).UnmarshalEasyJSON(in)
+					(out.HistogramMetrics)[key] = v3
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "SummaryMetrics":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.SummaryMetrics = make(map[string]metrics.Summary)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v4 metrics.Summary
+					(v4).UnmarshalEasyJSON(in)
+					(out.SummaryMetrics)[key] = v4
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "SetMetrics":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.SetMetrics = make(map[string]metrics.Set)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v5 metrics.Set
+					(v5).UnmarshalEasyJSON(in)
+					(out.SetMetrics)[key] = v5
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
 		case "AllowedGaugeNames":
 			if in.IsNull() {
 				in.Skip()
@@ -78,9 +126,9 @@ func easyjson138723c2DecodeGithubComAPalonskaaMetricsServerInternalMetricsStorag
 				for !in.IsDelim('}') {
 					key := string(in.String())
 					in.WantColon()
-					var v3 bool
-					v3 = bool(in.Bool())
-					(out.AllowedGaugeNames)[key] = v3
+					var v6 bool
+					v6 = bool(in.Bool())
+					(out.AllowedGaugeNames)[key] = v6
 					in.WantComma()
 				}
 				in.Delim('}')
@@ -94,9 +142,57 @@ func easyjson138723c2DecodeGithubComAPalonskaaMetricsServerInternalMetricsStorag
 				for !in.IsDelim('}') {
 					key := string(in.String())
 					in.WantColon()
-					var v4 bool
-					v4 = bool(in.Bool())
-					(out.AllowedCounterNames)[key] = v4
+					var v7 bool
+					v7 = bool(in.Bool())
+					(out.AllowedCounterNames)[key] = v7
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "AllowedHistogramNames":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.AllowedHistogramNames = make(map[string]bool)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v8 bool
+					v8 = bool(in.Bool())
+					(out.AllowedHistogramNames)[key] = v8
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "AllowedSummaryNames":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.AllowedSummaryNames = make(map[string]bool)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v9 bool
+					v9 = bool(in.Bool())
+					(out.AllowedSummaryNames)[key] = v9
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "AllowedSetNames":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.AllowedSetNames = make(map[string]bool)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v10 bool
+					v10 = bool(in.Bool())
+					(out.AllowedSetNames)[key] = v10
 					in.WantComma()
 				}
 				in.Delim('}')
@@ -122,16 +218,16 @@ func easyjson138723c2EncodeGithubComAPalonskaaMetricsServerInternalMetricsStorag
 			out.RawString(`null`)
 		} else {
 			out.RawByte('{')
-			v5First := true
-			for v5Name, v5Value := range in.GaugeMetrics {
-				if v5First {
-					v5First = false
+			v11First := true
+			for v11Name, v11Value := range in.GaugeMetrics {
+				if v11First {
+					v11First = false
 				} else {
 					out.RawByte(',')
 				}
-				out.String(string(v5Name))
+				out.String(string(v11Name))
 				out.RawByte(':')
-				out.Float64(float64(v5Value))
+				out.Float64(float64(v11Value))
 			}
 			out.RawByte('}')
 		}
@@ -143,16 +239,79 @@ func easyjson138723c2EncodeGithubComAPalonskaaMetricsServerInternalMetricsStorag
 			out.RawString(`null`)
 		} else {
 			out.RawByte('{')
-			v6First := true
-			for v6Name, v6Value := range in.CounterMetrics {
-				if v6First {
-					v6First = false
+			v12First := true
+			for v12Name, v12Value := range in.CounterMetrics {
+				if v12First {
+					v12First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v12Name))
+				out.RawByte(':')
+				out.Int64(int64(v12Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"HistogramMetrics\":"
+		out.RawString(prefix)
+		if in.HistogramMetrics == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v13First := true
+			for v13Name, v13Value := range in.HistogramMetrics {
+				if v13First {
+					v13First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v13Name))
+				out.RawByte(':')
+				(v13Value).MarshalEasyJSON(out)
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"SummaryMetrics\":"
+		out.RawString(prefix)
+		if in.SummaryMetrics == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v14First := true
+			for v14Name, v14Value := range in.SummaryMetrics {
+				if v14First {
+					v14First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v14Name))
+				out.RawByte(':')
+				(v14Value).MarshalEasyJSON(out)
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"SetMetrics\":"
+		out.RawString(prefix)
+		if in.SetMetrics == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v15First := true
+			for v15Name, v15Value := range in.SetMetrics {
+				if v15First {
+					v15First = false
 				} else {
 					out.RawByte(',')
 				}
-				out.String(string(v6Name))
+				out.String(string(v15Name))
 				out.RawByte(':')
-				out.Int64(int64(v6Value))
+				(v15Value).MarshalEasyJSON(out)
 			}
 			out.RawByte('}')
 		}
@@ -164,16 +323,16 @@ func easyjson138723c2EncodeGithubComAPalonskaaMetricsServerInternalMetricsStorag
 			out.RawString(`null`)
 		} else {
 			out.RawByte('{')
-			v7First := true
-			for v7Name, v7Value := range in.AllowedGaugeNames {
-				if v7First {
-					v7First = false
+			v16First := true
+			for v16Name, v16Value := range in.AllowedGaugeNames {
+				if v16First {
+					v16First = false
 				} else {
 					out.RawByte(',')
 				}
-				out.String(string(v7Name))
+				out.String(string(v16Name))
 				out.RawByte(':')
-				out.Bool(bool(v7Value))
+				out.Bool(bool(v16Value))
 			}
 			out.RawByte('}')
 		}
@@ -185,16 +344,79 @@ func easyjson138723c2EncodeGithubComAPalonskaaMetricsServerInternalMetricsStorag
 			out.RawString(`null`)
 		} else {
 			out.RawByte('{')
-			v8First := true
-			for v8Name, v8Value := range in.AllowedCounterNames {
-				if v8First {
-					v8First = false
+			v17First := true
+			for v17Name, v17Value := range in.AllowedCounterNames {
+				if v17First {
+					v17First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v17Name))
+				out.RawByte(':')
+				out.Bool(bool(v17Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"AllowedHistogramNames\":"
+		out.RawString(prefix)
+		if in.AllowedHistogramNames == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v18First := true
+			for v18Name, v18Value := range in.AllowedHistogramNames {
+				if v18First {
+					v18First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v18Name))
+				out.RawByte(':')
+				out.Bool(bool(v18Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"AllowedSummaryNames\":"
+		out.RawString(prefix)
+		if in.AllowedSummaryNames == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v19First := true
+			for v19Name, v19Value := range in.AllowedSummaryNames {
+				if v19First {
+					v19First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v19Name))
+				out.RawByte(':')
+				out.Bool(bool(v19Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"AllowedSetNames\":"
+		out.RawString(prefix)
+		if in.AllowedSetNames == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v20First := true
+			for v20Name, v20Value := range in.AllowedSetNames {
+				if v20First {
+					v20First = false
 				} else {
 					out.RawByte(',')
 				}
-				out.String(string(v8Name))
+				out.String(string(v20Name))
 				out.RawByte(':')
-				out.Bool(bool(v8Value))
+				out.Bool(bool(v20Value))
 			}
 			out.RawByte('}')
 		}
@@ -1,6 +1,9 @@
 package metricsstorage
 
 import (
+	"math"
+	"runtime"
+	"sync"
 	"testing"
 
 	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
@@ -93,6 +96,7 @@ func TestMemStorage_AddGauge(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ms := &MetricsStorage{
+				mu:                  new(sync.RWMutex),
 				GaugeMetrics:        tt.fields.Gauge,
 				CounterMetrics:      tt.fields.Counter,
 				AllowedGaugeNames:   make(map[string]bool),
@@ -187,6 +191,7 @@ func TestMemStorage_AddCounter(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ms := &MetricsStorage{
+				mu:                  new(sync.RWMutex),
 				GaugeMetrics:        tt.fields.Gauge,
 				CounterMetrics:      tt.fields.Counter,
 				AllowedGaugeNames:   make(map[string]bool),
@@ -196,3 +201,146 @@ func TestMemStorage_AddCounter(t *testing.T) {
 		})
 	}
 }
+
+func TestMemStorage_AddHistogram(t *testing.T) {
+	tests := []struct {
+		name      string
+		stored    map[string]metrics.Histogram
+		val       metrics.Histogram
+		wantErr   bool
+		wantCount uint64
+		wantSum   float64
+	}{
+		{
+			name:   "new-name",
+			stored: map[string]metrics.Histogram{},
+			val: metrics.Histogram{
+				Bounds: []float64{1, 2}, Counts: []uint64{1, 0, 1}, Sum: 3.5, Count: 2,
+			},
+			wantCount: 2,
+			wantSum:   3.5,
+		},
+		{
+			name: "merge-existing",
+			stored: map[string]metrics.Histogram{"hist": {
+				Bounds: []float64{1, 2}, Counts: []uint64{0, 1, 0}, Sum: 1.5, Count: 1,
+			}},
+			val: metrics.Histogram{
+				Bounds: []float64{1, 2}, Counts: []uint64{1, 0, 1}, Sum: 3.5, Count: 2,
+			},
+			wantCount: 3,
+			wantSum:   5,
+		},
+		{
+			name: "bounds-mismatch",
+			stored: map[string]metrics.Histogram{"hist": {
+				Bounds: []float64{1, 2}, Counts: []uint64{0, 1, 0}, Sum: 1.5, Count: 1,
+			}},
+			val: metrics.Histogram{
+				Bounds: []float64{1, 3}, Counts: []uint64{1, 0, 1}, Sum: 3.5, Count: 2,
+			},
+			wantErr:   true,
+			wantCount: 1,
+			wantSum:   1.5,
+		},
+		{
+			name:   "invalid-counts",
+			stored: map[string]metrics.Histogram{},
+			val: metrics.Histogram{
+				Bounds: []float64{1, 2}, Counts: []uint64{1}, Sum: 3.5, Count: 1,
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := &MetricsStorage{
+				mu:                    new(sync.RWMutex),
+				HistogramMetrics:      tt.stored,
+				AllowedHistogramNames: make(map[string]bool),
+			}
+			for name := range tt.stored {
+				ms.AllowedHistogramNames[name] = true
+			}
+
+			err := ms.AddHistogram("hist", tt.val)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got := ms.HistogramMetrics["hist"]
+			if got.Count != tt.wantCount || got.Sum != tt.wantSum {
+				t.Errorf("got count=%d sum=%v, want count=%d sum=%v", got.Count, got.Sum, tt.wantCount, tt.wantSum)
+			}
+		})
+	}
+}
+
+func TestMemStorage_SetSnapshot(t *testing.T) {
+	ms := &MetricsStorage{
+		mu:              new(sync.RWMutex),
+		SetMetrics:      make(map[string]metrics.Set),
+		AllowedSetNames: make(map[string]bool),
+	}
+	ms.AddSetMembers("users", "alice", "bob", "alice")
+
+	data, err := ms.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restored := NewMetricsStorage()
+	if err := restored.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+
+	val, ok := restored.GetSetValue("users")
+	if !ok {
+		t.Fatal("set was not restored")
+	}
+	if val.Cardinality() != 2 {
+		t.Errorf("cardinality = %d, want 2", val.Cardinality())
+	}
+}
+
+func TestMemStorage_ObserveGCPauses(t *testing.T) {
+	ms := NewMetricsStorage()
+	memStats := &runtime.MemStats{}
+
+	runtime.GC()
+	ms.Update(memStats)
+	if _, ok := ms.GetHistogramValue("GCPauseNs"); ok {
+		t.Fatal("Update observed GC pauses")
+	}
+
+	want := uint64(min(memStats.NumGC, uint32(len(memStats.PauseNs))))
+	// the pauses are observed once
+	for range 2 {
+		ms.ObserveGCPauses(memStats)
+		hist, ok := ms.GetHistogramValue("GCPauseNs")
+		if !ok {
+			t.Fatal("GC pauses were not observed")
+		}
+		if hist.Count != want {
+			t.Errorf("observed %d pauses, want %d", hist.Count, want)
+		}
+	}
+}
+
+func TestMemStorage_ObserveNonFinite(t *testing.T) {
+	storage := NewMetricsStorage()
+	for _, val := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if err := storage.ObserveSummary("latency", val); err == nil {
+			t.Errorf("ObserveSummary(%v) succeeded", val)
+		}
+		if err := storage.ObserveHistogram("latency", val); err == nil {
+			t.Errorf("ObserveHistogram(%v) succeeded", val)
+		}
+	}
+	if _, ok := storage.GetSummaryValue("latency"); ok {
+		t.Error("summary stored from non-finite observations")
+	}
+	if _, ok := storage.GetHistogramValue("latency"); ok {
+		t.Error("histogram stored from non-finite observations")
+	}
+}
@@ -2,26 +2,81 @@ package metricsstorage
 
 import (
 	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/a-palonskaa/metrics-server/internal/selfmetrics"
+)
+
+// saveMu serializes writes of the storage file by the saving routine, the
+// saving handler and admin snapshots.
+var saveMu sync.Mutex
+
+var (
+	// storageFile is the last file a snapshot was written to.
+	storageFile atomic.Pointer[os.File]
+	// snapshotErr holds the error of the last snapshot, nil if it succeeded.
+	snapshotErr atomic.Pointer[error]
 )
 
+// SnapshotError returns the error of the last snapshot, nil if it succeeded
+// or no snapshot was written yet.
+func SnapshotError() error {
+	if err := snapshotErr.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+// Ping checks that the storage can be read and that its file still exists,
+// it blocks while the storage is locked.
+func Ping() error {
+	_ = MS.SeriesCount()
+
+	if f := storageFile.Load(); f != nil {
+		if _, err := os.Stat(f.Name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteMetricsStorage replaces the contents of ostream with MS.
 func WriteMetricsStorage(ostream *os.File) error {
+	saveMu.Lock()
+	defer saveMu.Unlock()
+
+	start := time.Now()
+	size, err := writeMetricsStorage(ostream)
+	selfmetrics.ObserveSnapshot(time.Since(start), size, err)
+	storageFile.Store(ostream)
+	snapshotErr.Store(&err)
+	return err
+}
+
+// writeMetricsStorage is called with saveMu held, it returns the size of
+// the snapshot.
+func writeMetricsStorage(ostream *os.File) (int, error) {
 	if _, err := ostream.Seek(0, 0); err != nil {
-		return err
+		return 0, err
+	}
+	// a snapshot gets shorter once metrics are deleted
+	if err := ostream.Truncate(0); err != nil {
+		return 0, err
 	}
 
+	MS.mu.RLock()
 	data, err := MS.MarshalJSON()
+	MS.mu.RUnlock()
 	if err != nil {
 		log.Error().Err(err)
-		return err
+		return 0, err
 	}
 
-	if _, err := ostream.Write(append(data, '\n')); err != nil {
-		return err
-	}
-	return nil
+	return ostream.Write(append(data, '\n'))
 }
 
 func RunSavingStorageRoutine(ostream *os.File, interval int) {
@@ -49,9 +104,13 @@ func ReadMetricsStorage(istream *os.File) error {
 		return err
 	}
 
+	MS.mu.Lock()
+	defer MS.mu.Unlock()
+
 	if err := MS.UnmarshalJSON(data); err != nil {
 		log.Error().Err(err)
 		return err
 	}
+	MS.since = now()
 	return nil
 }
@@ -0,0 +1,102 @@
+package metricsstorage
+
+import (
+	"time"
+)
+
+// HistoryPoints is the number of chart points kept per series, one per
+// HistoryResolution. Updates within the same interval replace the last point.
+var (
+	HistoryPoints     = 120
+	HistoryResolution = 5 * time.Second
+)
+
+// seriesKey identifies a series of a type, the same name may be used by
+// several types.
+type seriesKey struct {
+	mType  string
+	series string
+}
+
+// recordPoint appends val to the chart history of a series. Gauges and
+// counters record their value, histograms and summaries the number of
+// observations. It is called with mu held.
+func (m *MetricsStorage) recordPoint(mType string, series string, val float64) {
+	if m.timeline == nil {
+		m.timeline = make(map[seriesKey][]Sample)
+	}
+
+	key := seriesKey{mType: mType, series: series}
+	t := now().Truncate(HistoryResolution)
+	points := m.timeline[key]
+	if n := len(points); n > 0 && !points[n-1].Time.Before(t) {
+		points[n-1].Value = val
+		return
+	}
+
+	points = append(points, Sample{Time: t, Value: val})
+	if len(points) > HistoryPoints {
+		points = points[len(points)-HistoryPoints:]
+	}
+	m.timeline[key] = points
+}
+
+// History returns a copy of the chart points of a series, oldest first.
+func (m *MetricsStorage) History(mType string, series string) ([]Sample, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.allowedNames(mType)[series] {
+		return nil, false
+	}
+	points := m.timeline[seriesKey{mType: mType, series: series}]
+	res := make([]Sample, len(points))
+	copy(res, points)
+	return res, true
+}
+
+// Histories returns copies of the chart points of every series of a type
+// that has any.
+func (m *MetricsStorage) Histories(mType string) map[string][]Sample {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	res := make(map[string][]Sample)
+	for key, points := range m.timeline {
+		if key.mType != mType || len(points) == 0 || !m.allowedNames(mType)[key.series] {
+			continue
+		}
+		res[key.series] = append([]Sample(nil), points...)
+	}
+	return res
+}
+
+// touch records the time a series was last changed. It is called with mu
+// held.
+func (m *MetricsStorage) touch(mType string, series string) {
+	if m.updated == nil {
+		m.updated = make(map[seriesKey]time.Time)
+	}
+	m.updated[seriesKey{mType: mType, series: series}] = now()
+}
+
+// LastUpdate returns when a series was last changed through the storage
+// methods. Series restored from a file or never updated since the start
+// report false.
+func (m *MetricsStorage) LastUpdate(mType string, series string) (time.Time, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	t, ok := m.updated[seriesKey{mType: mType, series: series}]
+	return t, ok
+}
+
+// UpdatedAt returns when the current value of a series was last updated,
+// unlike LastUpdate series not updated since the storage was created or
+// restored report that time.
+func (m *MetricsStorage) UpdatedAt(mType string, series string) time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.updatedAt(mType, series)
+}
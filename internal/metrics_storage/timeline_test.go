@@ -0,0 +1,68 @@
+package metricsstorage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+)
+
+func TestMemStorage_History(t *testing.T) {
+	current := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	points := HistoryPoints
+	HistoryPoints = 3
+	defer func() { HistoryPoints = points }()
+
+	storage := NewMetricsStorage()
+	for _, val := range []metrics.Gauge{1, 2, 3, 4, 5} {
+		storage.AddGauge("Load", 0)
+		storage.AddGauge("Load", 10)
+		storage.AddGauge("Load", val) // replaces the points of the same interval
+		current = current.Add(HistoryResolution)
+	}
+	storage.ObserveHistogram("Latency", 0.1)
+	storage.ObserveHistogram("Latency", 0.2)
+
+	history, ok := storage.History("gauge", "Load")
+	require.True(t, ok)
+	require.Len(t, history, 3)
+	assert.Equal(t, []float64{3, 4, 5}, []float64{history[0].Value, history[1].Value, history[2].Value})
+
+	latency, ok := storage.History("histogram", "Latency")
+	require.True(t, ok)
+	require.Len(t, latency, 1)
+	assert.Equal(t, float64(2), latency[0].Value)
+
+	_, ok = storage.History("counter", "Load")
+	assert.False(t, ok)
+	assert.Len(t, storage.Histories("gauge"), 1)
+}
+
+func TestMemStorage_LastUpdate(t *testing.T) {
+	current := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	storage := NewMetricsStorage()
+	storage.AddCounter("PollCount", 1)
+	current = current.Add(time.Minute)
+	storage.AddGauge("PollCount", 1)
+
+	last, ok := storage.LastUpdate("counter", "PollCount")
+	require.True(t, ok)
+	assert.Equal(t, current.Add(-time.Minute), last)
+
+	last, ok = storage.LastUpdate("gauge", "PollCount")
+	require.True(t, ok)
+	assert.Equal(t, current, last)
+
+	_, ok = storage.LastUpdate("histogram", "PollCount")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"PollCount"}, storage.Series("gauge"))
+}
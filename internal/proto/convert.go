@@ -0,0 +1,113 @@
+// Package proto holds the gRPC API of the server and the conversions between
+// its messages and the metrics package types.
+package proto
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative metrics.proto
+
+import (
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+)
+
+func FromMetrics(m metrics.Metrics) *Metric {
+	pm := &Metric{
+		Id:      m.ID,
+		Type:    m.MType,
+		Delta:   m.Delta,
+		Value:   m.Value,
+		Members: m.Members,
+		Labels:  m.Labels,
+	}
+
+	if m.Histogram != nil {
+		pm.Histogram = &Histogram{
+			Bounds: m.Histogram.Bounds,
+			Counts: m.Histogram.Counts,
+			Sum:    m.Histogram.Sum,
+			Count:  m.Histogram.Count,
+		}
+	}
+
+	if m.Summary != nil {
+		pm.Summary = &Summary{
+			Accuracy: m.Summary.Accuracy,
+			Positive: toProtoBins(m.Summary.Positive),
+			Negative: toProtoBins(m.Summary.Negative),
+			Zero:     m.Summary.Zero,
+			Count:    m.Summary.Count,
+			Sum:      m.Summary.Sum,
+			Min:      m.Summary.Min,
+			Max:      m.Summary.Max,
+		}
+	}
+
+	if m.Set != nil {
+		pm.Set = &Set{
+			Precision: uint32(m.Set.Precision),
+			Registers: m.Set.Registers,
+		}
+	}
+	return pm
+}
+
+func (pm *Metric) ToMetrics() metrics.Metrics {
+	m := metrics.Metrics{
+		ID:      pm.GetId(),
+		MType:   pm.GetType(),
+		Delta:   pm.Delta,
+		Value:   pm.Value,
+		Members: pm.GetMembers(),
+		Labels:  pm.GetLabels(),
+	}
+
+	if h := pm.GetHistogram(); h != nil {
+		m.Histogram = &metrics.Histogram{
+			Bounds: h.GetBounds(),
+			Counts: h.GetCounts(),
+			Sum:    h.GetSum(),
+			Count:  h.GetCount(),
+		}
+	}
+
+	if s := pm.GetSummary(); s != nil {
+		m.Summary = &metrics.Summary{
+			Accuracy: s.GetAccuracy(),
+			Positive: fromProtoBins(s.GetPositive()),
+			Negative: fromProtoBins(s.GetNegative()),
+			Zero:     s.GetZero(),
+			Count:    s.GetCount(),
+			Sum:      s.GetSum(),
+			Min:      s.GetMin(),
+			Max:      s.GetMax(),
+		}
+	}
+
+	if s := pm.GetSet(); s != nil {
+		m.Set = &metrics.Set{
+			Precision: uint8(s.GetPrecision()),
+			Registers: s.GetRegisters(),
+		}
+	}
+	return m
+}
+
+func toProtoBins(bins map[int]uint64) map[int32]uint64 {
+	if bins == nil {
+		return nil
+	}
+	res := make(map[int32]uint64, len(bins))
+	for k, v := range bins {
+		res[int32(k)] = v
+	}
+	return res
+}
+
+func fromProtoBins(bins map[int32]uint64) map[int]uint64 {
+	if bins == nil {
+		return nil
+	}
+	res := make(map[int]uint64, len(bins))
+	for k, v := range bins {
+		res[int(k)] = v
+	}
+	return res
+}
@@ -0,0 +1,109 @@
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+)
+
+func sampleMetrics() []metrics.Metrics {
+	gauge, delta := 1234.5678, int64(42)
+
+	hist := metrics.NewHistogram(metrics.DefaultBuckets)
+	summary := metrics.NewSummary(metrics.DefaultSummaryAccuracy)
+	for i := range 100 {
+		hist.Observe(float64(i) / 10)
+		summary.Observe(float64(i) / 10)
+	}
+
+	labels := map[string]string{"host": "web01", "region": "eu"}
+	return []metrics.Metrics{
+		{ID: "HeapAlloc", MType: metrics.GaugeName, Value: &gauge, Labels: labels},
+		{ID: "PollCount", MType: metrics.CounterName, Delta: &delta, Labels: labels},
+		{ID: "Latency", MType: metrics.HistogramName, Histogram: &hist, Labels: labels},
+		{ID: "Duration", MType: metrics.SummaryName, Summary: &summary, Labels: labels},
+	}
+}
+
+func TestConvertRoundtrip(t *testing.T) {
+	set := metrics.NewSet(metrics.DefaultSetPrecision)
+	set.Add("alice")
+	all := append(sampleMetrics(), metrics.Metrics{ID: "Users", MType: metrics.SetName, Set: &set})
+
+	for _, m := range all {
+		data, err := proto.Marshal(FromMetrics(m))
+		require.NoError(t, err)
+
+		var pm Metric
+		require.NoError(t, proto.Unmarshal(data, &pm))
+		got := pm.ToMetrics()
+		if m.Summary != nil && got.Summary != nil {
+			// empty bins come back as nil maps, Clone allocates both
+			*m.Summary, *got.Summary = m.Summary.Clone(), got.Summary.Clone()
+		}
+		assert.Equal(t, m, got, m.ID)
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	all := sampleMetrics()
+
+	b.Run("easyjson", func(b *testing.B) {
+		var size int
+		for b.Loop() {
+			size = 0
+			for _, m := range all {
+				data, _ := m.MarshalJSON()
+				size += len(data)
+			}
+		}
+		b.ReportMetric(float64(size), "bytes/op")
+	})
+
+	b.Run("protobuf", func(b *testing.B) {
+		var size int
+		for b.Loop() {
+			size = 0
+			for _, m := range all {
+				data, _ := proto.Marshal(FromMetrics(m))
+				size += len(data)
+			}
+		}
+		b.ReportMetric(float64(size), "bytes/op")
+	})
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	all := sampleMetrics()
+
+	b.Run("easyjson", func(b *testing.B) {
+		bodies := make([][]byte, len(all))
+		for i, m := range all {
+			bodies[i], _ = m.MarshalJSON()
+		}
+		for b.Loop() {
+			for _, body := range bodies {
+				var m metrics.Metrics
+				_ = m.UnmarshalJSON(body)
+			}
+		}
+	})
+
+	b.Run("protobuf", func(b *testing.B) {
+		bodies := make([][]byte, len(all))
+		for i, m := range all {
+			bodies[i], _ = proto.Marshal(FromMetrics(m))
+		}
+		for b.Loop() {
+			for _, body := range bodies {
+				var pm Metric
+				_ = proto.Unmarshal(body, &pm)
+				_ = pm.ToMetrics()
+			}
+		}
+	})
+}
@@ -0,0 +1,453 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+// MetricNameLabel holds the metric name in the labels of selected series.
+// Arithmetic, functions and aggregations drop it.
+const MetricNameLabel = "__name__"
+
+// DefaultLookback is how old the latest sample of a series may be for the
+// series to be selected at a time.
+const DefaultLookback = 5 * time.Minute
+
+// maxRange is the longest range of rate() and increase(), the length of the
+// chart timeline they read.
+func maxRange() time.Duration {
+	return time.Duration(memstorage.HistoryPoints) * memstorage.HistoryResolution
+}
+
+// MaxPoints limits the number of steps of a range query.
+const MaxPoints = 11000
+
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+type Vector []Sample
+
+type Scalar float64
+
+type Point struct {
+	T time.Time
+	V float64
+}
+
+type Series struct {
+	Labels map[string]string
+	Points []Point
+}
+
+type Matrix []Series
+
+// Value is the result of an instant query, a Vector or a Scalar.
+type Value interface {
+	Type() ValueType
+}
+
+func (Vector) Type() ValueType { return TypeVector }
+func (Scalar) Type() ValueType { return TypeScalar }
+
+// Engine evaluates expressions over the chart timeline of the gauges and
+// counters of a storage (see memstorage.HistoryPoints) and their current
+// values. Ranges longer than the kept timeline are rejected by Parse, times
+// before it see no samples.
+type Engine struct {
+	Lookback time.Duration
+
+	storage *memstorage.MetricsStorage
+	// source returns the series of a name with their samples, oldest first.
+	// It is replaced in tests.
+	source func(name string) []storedSeries
+}
+
+type storedSeries struct {
+	labels  map[string]string
+	samples []memstorage.Sample
+}
+
+func NewEngine(storage *memstorage.MetricsStorage) *Engine {
+	e := &Engine{Lookback: DefaultLookback, storage: storage}
+	e.source = e.storageSeries
+	return e
+}
+
+// storageSeries loads the gauges and counters of a name, a counter is skipped
+// if there is a gauge of the same series. The current value is added as a
+// sample at the time of its last update, so that series no longer updated
+// fall out of the lookback.
+func (e *Engine) storageSeries(name string) []storedSeries {
+	var res []storedSeries
+	seen := make(map[string]bool)
+	for _, mType := range []string{metrics.GaugeName, metrics.CounterName} {
+		for _, series := range e.storage.Series(mType) {
+			id, labels, err := metrics.ParseSeriesName(series)
+			if err != nil || id != name || seen[series] {
+				continue
+			}
+			seen[series] = true
+
+			samples, _ := e.storage.History(mType, series)
+			if val, ok := e.storage.GetValue(mType, series); ok {
+				var current float64
+				switch v := val.(type) {
+				case metrics.Gauge:
+					current = float64(v)
+				case metrics.Counter:
+					current = float64(v)
+				}
+				samples = append(samples, memstorage.Sample{Time: e.storage.UpdatedAt(mType, series), Value: current})
+			}
+			res = append(res, storedSeries{labels: labels, samples: samples})
+		}
+	}
+	return res
+}
+
+// Instant evaluates an expression at t.
+func (e *Engine) Instant(expr Expr, t time.Time) (Value, error) {
+	return e.newEvaluator().eval(expr, t)
+}
+
+// Range evaluates an expression at every step from start to end. Scalars
+// become a series without labels.
+func (e *Engine) Range(expr Expr, start, end time.Time, step time.Duration) (Matrix, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("end is before start")
+	}
+	if end.Sub(start)/step >= MaxPoints {
+		return nil, fmt.Errorf("too many points, at most %d are allowed", MaxPoints)
+	}
+
+	ev := e.newEvaluator()
+	byKey := make(map[string]*Series)
+	add := func(labels map[string]string, p Point) {
+		if math.IsNaN(p.V) || math.IsInf(p.V, 0) {
+			return
+		}
+		key := labelsKey(labels)
+		s, ok := byKey[key]
+		if !ok {
+			s = &Series{Labels: labels}
+			byKey[key] = s
+		}
+		s.Points = append(s.Points, p)
+	}
+
+	for t := start; !t.After(end); t = t.Add(step) {
+		val, err := ev.eval(expr, t)
+		if err != nil {
+			return nil, err
+		}
+		switch v := val.(type) {
+		case Scalar:
+			add(map[string]string{}, Point{T: t, V: float64(v)})
+		case Vector:
+			for _, s := range v {
+				add(s.Labels, Point{T: t, V: s.Value})
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	res := make(Matrix, len(keys))
+	for i, key := range keys {
+		res[i] = *byKey[key]
+	}
+	return res, nil
+}
+
+type evaluator struct {
+	engine *Engine
+	cache  map[string][]storedSeries
+}
+
+func (e *Engine) newEvaluator() *evaluator {
+	return &evaluator{engine: e, cache: make(map[string][]storedSeries)}
+}
+
+// load returns the series of a name matching all matchers. Series are loaded
+// once per query.
+func (ev *evaluator) load(sel *VectorSelector) []storedSeries {
+	all, ok := ev.cache[sel.Name]
+	if !ok {
+		all = ev.engine.source(sel.Name)
+		ev.cache[sel.Name] = all
+	}
+
+	var res []storedSeries
+	for _, s := range all {
+		matches := true
+		for _, matcher := range sel.Matchers {
+			if !matcher.Matches(s.labels) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+func (ev *evaluator) eval(expr Expr, t time.Time) (Value, error) {
+	switch e := expr.(type) {
+	case NumberLiteral:
+		return Scalar(e.Value), nil
+	case VectorSelector:
+		return ev.evalSelector(&e, t), nil
+	case Call:
+		return ev.evalCall(e, t), nil
+	case AggregateExpr:
+		val, err := ev.eval(e.Expr, t)
+		if err != nil {
+			return nil, err
+		}
+		return aggregate(e, val.(Vector)), nil
+	case BinaryExpr:
+		lhs, err := ev.eval(e.LHS, t)
+		if err != nil {
+			return nil, err
+		}
+		rhs, err := ev.eval(e.RHS, t)
+		if err != nil {
+			return nil, err
+		}
+		return binary(e.Op, lhs, rhs)
+	}
+	return nil, fmt.Errorf("unexpected expression %T", expr)
+}
+
+func (ev *evaluator) evalSelector(sel *VectorSelector, t time.Time) Vector {
+	vec := Vector{}
+	for _, s := range ev.load(sel) {
+		i := lastAtOrBefore(s.samples, t)
+		if i < 0 || t.Sub(s.samples[i].Time) > ev.engine.Lookback {
+			continue
+		}
+		labels := copyLabels(s.labels)
+		labels[MetricNameLabel] = sel.Name
+		vec = append(vec, Sample{Labels: labels, Value: s.samples[i].Value})
+	}
+	return vec
+}
+
+// evalCall computes the counter increase within (t-range, t] from the last
+// sample before the range on, a decrease is taken as a counter reset. Series
+// with less than two samples are skipped.
+func (ev *evaluator) evalCall(call Call, t time.Time) Vector {
+	vec := Vector{}
+	start := t.Add(-call.Arg.Range)
+	for _, s := range ev.load(call.Arg) {
+		last := lastAtOrBefore(s.samples, t)
+		first := max(lastAtOrBefore(s.samples, start), 0)
+		if last < 0 || last <= first {
+			continue
+		}
+
+		var increase float64
+		for i := first + 1; i <= last; i++ {
+			delta := s.samples[i].Value - s.samples[i-1].Value
+			if delta < 0 {
+				delta = s.samples[i].Value
+			}
+			increase += delta
+		}
+		if call.Func == "rate" {
+			increase /= call.Arg.Range.Seconds()
+		}
+		vec = append(vec, Sample{Labels: copyLabels(s.labels), Value: increase})
+	}
+	return vec
+}
+
+// lastAtOrBefore returns the index of the last sample not after t, -1 if
+// there is none.
+func lastAtOrBefore(samples []memstorage.Sample, t time.Time) int {
+	return sort.Search(len(samples), func(i int) bool { return samples[i].Time.After(t) }) - 1
+}
+
+func aggregate(agg AggregateExpr, vec Vector) Vector {
+	type group struct {
+		labels map[string]string
+		values []float64
+	}
+	groups := make(map[string]*group)
+	var order []string
+	for _, s := range vec {
+		labels := make(map[string]string, len(agg.By))
+		for _, name := range agg.By {
+			if v, ok := s.Labels[name]; ok {
+				labels[name] = v
+			}
+		}
+		key := labelsKey(labels)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{labels: labels}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.values = append(g.values, s.Value)
+	}
+
+	res := make(Vector, 0, len(groups))
+	for _, key := range order {
+		g := groups[key]
+		var val float64
+		switch agg.Op {
+		case "sum", "avg":
+			for _, v := range g.values {
+				val += v
+			}
+			if agg.Op == "avg" {
+				val /= float64(len(g.values))
+			}
+		case "min":
+			val = g.values[0]
+			for _, v := range g.values[1:] {
+				val = math.Min(val, v)
+			}
+		case "max":
+			val = g.values[0]
+			for _, v := range g.values[1:] {
+				val = math.Max(val, v)
+			}
+		case "count":
+			val = float64(len(g.values))
+		}
+		res = append(res, Sample{Labels: g.labels, Value: val})
+	}
+	return res
+}
+
+// binary applies an operator to scalars and vectors. Vectors are matched by
+// their labels without the metric name. Comparisons of vectors keep the
+// left-hand samples the comparison holds for, comparisons of scalars return
+// 1 or 0.
+func binary(op string, lhs, rhs Value) (Value, error) {
+	ls, lScalar := lhs.(Scalar)
+	rs, rScalar := rhs.(Scalar)
+	switch {
+	case lScalar && rScalar:
+		val, keep := apply(op, float64(ls), float64(rs))
+		if comparisonOps[op] {
+			val = 0
+			if keep {
+				val = 1
+			}
+		}
+		return Scalar(val), nil
+	case rScalar:
+		return vectorScalar(op, lhs.(Vector), float64(rs), false), nil
+	case lScalar:
+		return vectorScalar(op, rhs.(Vector), float64(ls), true), nil
+	}
+
+	right := make(map[string]Sample)
+	for _, s := range rhs.(Vector) {
+		key := labelsKey(withoutName(s.Labels))
+		if _, ok := right[key]; ok {
+			return nil, fmt.Errorf("many-to-many matching is not supported, %s is not unique on the right-hand side", key)
+		}
+		right[key] = s
+	}
+
+	res := Vector{}
+	for _, l := range lhs.(Vector) {
+		labels := withoutName(l.Labels)
+		r, ok := right[labelsKey(labels)]
+		if !ok {
+			continue
+		}
+		val, keep := apply(op, l.Value, r.Value)
+		if comparisonOps[op] {
+			if keep {
+				res = append(res, l)
+			}
+			continue
+		}
+		res = append(res, Sample{Labels: labels, Value: val})
+	}
+	return res, nil
+}
+
+func vectorScalar(op string, vec Vector, scalar float64, scalarLeft bool) Vector {
+	res := Vector{}
+	for _, s := range vec {
+		a, b := s.Value, scalar
+		if scalarLeft {
+			a, b = b, a
+		}
+		val, keep := apply(op, a, b)
+		if comparisonOps[op] {
+			if keep {
+				res = append(res, s)
+			}
+			continue
+		}
+		res = append(res, Sample{Labels: withoutName(s.Labels), Value: val})
+	}
+	return res
+}
+
+// apply returns the arithmetic result or whether the comparison holds.
+func apply(op string, a, b float64) (float64, bool) {
+	switch op {
+	case "+":
+		return a + b, true
+	case "-":
+		return a - b, true
+	case "*":
+		return a * b, true
+	case "/":
+		return a / b, true
+	case "==":
+		return a, a == b
+	case "!=":
+		return a, a != b
+	case ">":
+		return a, a > b
+	case "<":
+		return a, a < b
+	case ">=":
+		return a, a >= b
+	case "<=":
+		return a, a <= b
+	}
+	return math.NaN(), false
+}
+
+func labelsKey(labels map[string]string) string {
+	return metrics.SeriesName("", labels)
+}
+
+func withoutName(labels map[string]string) map[string]string {
+	res := copyLabels(labels)
+	delete(res, MetricNameLabel)
+	return res
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	res := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		res[k] = v
+	}
+	return res
+}
@@ -0,0 +1,232 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+var t0 = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// testEngine serves fixed series instead of a storage.
+func testEngine(series map[string][]storedSeries) *Engine {
+	e := NewEngine(memstorage.NewMetricsStorage())
+	e.source = func(name string) []storedSeries {
+		return series[name]
+	}
+	return e
+}
+
+func samples(values ...float64) []memstorage.Sample {
+	res := make([]memstorage.Sample, len(values))
+	for i, v := range values {
+		res[i] = memstorage.Sample{Time: t0.Add(time.Duration(i) * time.Minute), Value: v}
+	}
+	return res
+}
+
+func TestEngine_Instant(t *testing.T) {
+	engine := testEngine(map[string][]storedSeries{
+		"Load": {
+			{labels: map[string]string{"host": "a", "dc": "eu"}, samples: samples(1, 2, 3)},
+			{labels: map[string]string{"host": "b", "dc": "eu"}, samples: samples(10, 20, 30)},
+			{labels: map[string]string{"host": "c", "dc": "us"}, samples: samples(5)},
+		},
+		"Capacity": {
+			{labels: map[string]string{"host": "a", "dc": "eu"}, samples: samples(4, 4, 4)},
+			{labels: map[string]string{"host": "b", "dc": "eu"}, samples: samples(40, 40, 40)},
+		},
+		"requests": {
+			// reset after the third sample
+			{labels: map[string]string{"host": "a"}, samples: samples(0, 60, 120, 30)},
+		},
+	})
+
+	tests := []struct {
+		name  string
+		input string
+		at    time.Time
+		want  Value
+	}{
+		{name: "scalar", input: "1 + 2 * 3", at: t0, want: Scalar(7)},
+		{name: "scalar comparison", input: "2 > 1", at: t0, want: Scalar(1)},
+		{
+			name:  "selector",
+			input: `Load{dc="eu"}`,
+			at:    t0.Add(2 * time.Minute),
+			want: Vector{
+				{Labels: map[string]string{"__name__": "Load", "host": "a", "dc": "eu"}, Value: 3},
+				{Labels: map[string]string{"__name__": "Load", "host": "b", "dc": "eu"}, Value: 30},
+			},
+		},
+		{
+			name:  "stale series are not selected",
+			input: `Load{host="c"}`,
+			at:    t0.Add(DefaultLookback + time.Second),
+			want:  Vector{},
+		},
+		{
+			name:  "vector and scalar",
+			input: `Load{host="a"} * 2`,
+			at:    t0.Add(time.Minute),
+			want:  Vector{{Labels: map[string]string{"host": "a", "dc": "eu"}, Value: 4}},
+		},
+		{
+			name:  "vector and vector",
+			input: "Load / Capacity",
+			at:    t0,
+			want: Vector{
+				{Labels: map[string]string{"host": "a", "dc": "eu"}, Value: 0.25},
+				{Labels: map[string]string{"host": "b", "dc": "eu"}, Value: 0.25},
+			},
+		},
+		{
+			name:  "filter",
+			input: "Load > 2",
+			at:    t0,
+			want: Vector{
+				{Labels: map[string]string{"__name__": "Load", "host": "b", "dc": "eu"}, Value: 10},
+				{Labels: map[string]string{"__name__": "Load", "host": "c", "dc": "us"}, Value: 5},
+			},
+		},
+		{
+			name:  "sum by",
+			input: "sum by (dc) (Load)",
+			at:    t0,
+			want: Vector{
+				{Labels: map[string]string{"dc": "eu"}, Value: 11},
+				{Labels: map[string]string{"dc": "us"}, Value: 5},
+			},
+		},
+		{name: "avg", input: "avg(Load)", at: t0, want: Vector{{Labels: map[string]string{}, Value: 16.0 / 3}}},
+		{name: "count", input: "count(Load > 1)", at: t0, want: Vector{{Labels: map[string]string{}, Value: 2}}},
+		{
+			name:  "max minus min",
+			input: "max(Load) - min(Load)",
+			at:    t0,
+			want:  Vector{{Labels: map[string]string{}, Value: 9}},
+		},
+		{
+			name:  "increase with reset",
+			input: "increase(requests[3m])",
+			at:    t0.Add(3 * time.Minute),
+			want:  Vector{{Labels: map[string]string{"host": "a"}, Value: 150}},
+		},
+		{
+			name:  "rate",
+			input: "rate(requests[2m])",
+			at:    t0.Add(2 * time.Minute),
+			want:  Vector{{Labels: map[string]string{"host": "a"}, Value: 1}},
+		},
+		{
+			name:  "rate needs two samples",
+			input: "rate(requests[1m])",
+			at:    t0,
+			want:  Vector{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expr, err := Parse(test.input)
+			require.NoError(t, err)
+			val, err := engine.Instant(expr, test.at)
+			require.NoError(t, err)
+			if vec, ok := test.want.(Vector); ok {
+				assert.ElementsMatch(t, vec, val)
+				return
+			}
+			assert.Equal(t, test.want, val)
+		})
+	}
+}
+
+func TestEngine_Matching(t *testing.T) {
+	engine := testEngine(map[string][]storedSeries{
+		"a": {{labels: map[string]string{"host": "x"}, samples: samples(1)}},
+		"b": {
+			{labels: map[string]string{"host": "x", "dc": "eu"}, samples: samples(1)},
+			{labels: map[string]string{"host": "y"}, samples: samples(1)},
+		},
+	})
+
+	// series without a match on the other side are dropped
+	expr, err := Parse("a + sum by (host) (b)")
+	require.NoError(t, err)
+	val, err := engine.Instant(expr, t0)
+	require.NoError(t, err)
+	assert.Equal(t, Vector{{Labels: map[string]string{"host": "x"}, Value: 2}}, val)
+
+	expr, err = Parse("a + b")
+	require.NoError(t, err)
+	val, err = engine.Instant(expr, t0)
+	require.NoError(t, err)
+	assert.Equal(t, Vector{}, val)
+}
+
+func TestEngine_Range(t *testing.T) {
+	engine := testEngine(map[string][]storedSeries{
+		"Load": {
+			{labels: map[string]string{"host": "a"}, samples: samples(1, 2, 3)},
+			{labels: map[string]string{"host": "b"}, samples: samples(0, 0, 1)},
+		},
+	})
+
+	expr, err := Parse("Load / Load{host=\"b\"} + 0")
+	require.NoError(t, err)
+	matrix, err := engine.Range(expr, t0, t0.Add(2*time.Minute), time.Minute)
+	require.NoError(t, err)
+	// 0/0 is dropped
+	require.Len(t, matrix, 1)
+	assert.Equal(t, []Point{{T: t0.Add(2 * time.Minute), V: 1}}, matrix[0].Points)
+
+	expr, err = Parse("sum(Load)")
+	require.NoError(t, err)
+	matrix, err = engine.Range(expr, t0, t0.Add(2*time.Minute), time.Minute)
+	require.NoError(t, err)
+	require.Len(t, matrix, 1)
+	assert.Equal(t, []Point{{T: t0, V: 1}, {T: t0.Add(time.Minute), V: 2}, {T: t0.Add(2 * time.Minute), V: 4}}, matrix[0].Points)
+
+	_, err = engine.Range(expr, t0, t0.Add(time.Hour), time.Millisecond)
+	assert.Error(t, err)
+	_, err = engine.Range(expr, t0, t0.Add(-time.Hour), time.Minute)
+	assert.Error(t, err)
+}
+
+func TestEngine_Storage(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	storage.AddGauge(`Load{host="a"}`, 1.5)
+	storage.AddCounter(`Load{host="a"}`, 7) // shadowed by the gauge
+	storage.AddCounter(`requests{host="a"}`, 7)
+
+	engine := NewEngine(storage)
+	expr, err := Parse("Load + requests")
+	require.NoError(t, err)
+	val, err := engine.Instant(expr, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, Vector{{Labels: map[string]string{"host": "a"}, Value: 8.5}}, val)
+}
+
+func TestEngine_StorageLookback(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	storage.AddGauge("Idle", 1)
+	updated, ok := storage.LastUpdate("gauge", "Idle")
+	require.True(t, ok)
+
+	engine := NewEngine(storage)
+	series := engine.storageSeries("Idle")
+	require.Len(t, series, 1)
+	last := series[0].samples[len(series[0].samples)-1]
+	assert.False(t, last.Time.After(updated), "current value at %v, updated at %v", last.Time, updated)
+	assert.Equal(t, 1.0, last.Value)
+
+	expr, err := Parse("Idle")
+	require.NoError(t, err)
+	val, err := engine.Instant(expr, updated.Add(DefaultLookback+time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, val)
+}
@@ -0,0 +1,142 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokIdent
+	tokString
+	tokDuration // the contents of [...]
+	tokOp
+	tokLParen
+	tokRParen
+	tokLBrace
+	tokRBrace
+	tokComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of input"
+	}
+	return strconv.Quote(t.text)
+}
+
+// operators are matched longest first.
+var operators = []string{"==", "!=", ">=", "<=", "=~", "!~", "+", "-", "*", "/", ">", "<", "="}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || r == ':' || unicode.IsLetter(r)
+}
+
+func isIdentChar(r rune) bool {
+	return isIdentStart(r) || r == '.' || unicode.IsDigit(r)
+}
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, token{tokLParen, "(", start})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokRParen, ")", start})
+			i++
+		case r == '{':
+			tokens = append(tokens, token{tokLBrace, "{", start})
+			i++
+		case r == '}':
+			tokens = append(tokens, token{tokRBrace, "}", start})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{tokComma, ",", start})
+			i++
+		case r == '[':
+			end := i + 1
+			for end < len(runes) && runes[end] != ']' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unclosed [ at %d", start)
+			}
+			tokens = append(tokens, token{tokDuration, strings.TrimSpace(string(runes[i+1 : end])), start})
+			i = end + 1
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				if runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unclosed string at %d", start)
+			}
+			s, err := strconv.Unquote(string(runes[i : end+1]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d: %w", start, err)
+			}
+			tokens = append(tokens, token{tokString, s, start})
+			i = end + 1
+		case unicode.IsDigit(r) || r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			end := i
+			for end < len(runes) {
+				c := runes[end]
+				if unicode.IsDigit(c) || c == '.' {
+					end++
+				} else if (c == 'e' || c == 'E') && end+1 < len(runes) {
+					end++
+					if runes[end] == '+' || runes[end] == '-' {
+						end++
+					}
+				} else {
+					break
+				}
+			}
+			tokens = append(tokens, token{tokNumber, string(runes[i:end]), start})
+			i = end
+		case isIdentStart(r):
+			end := i
+			for end < len(runes) && isIdentChar(runes[end]) {
+				end++
+			}
+			tokens = append(tokens, token{tokIdent, string(runes[i:end]), start})
+			i = end
+		default:
+			op := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(string(runes[i:]), candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at %d", r, start)
+			}
+			tokens = append(tokens, token{tokOp, op, start})
+			i += len([]rune(op))
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(runes)}), nil
+}
@@ -0,0 +1,354 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+)
+
+type ValueType string
+
+const (
+	TypeScalar ValueType = "scalar"
+	TypeVector ValueType = "vector"
+	TypeMatrix ValueType = "matrix"
+)
+
+// Expr is a node of a parsed expression.
+type Expr interface {
+	Type() ValueType
+}
+
+type NumberLiteral struct {
+	Value float64
+}
+
+// VectorSelector selects the gauges and counters of a name. With a Range it
+// selects the samples of the range and is only valid as a function argument.
+type VectorSelector struct {
+	Name     string
+	Matchers []metrics.LabelMatcher
+	Range    time.Duration
+}
+
+// Call is rate() or increase() of a range selector.
+type Call struct {
+	Func string
+	Arg  *VectorSelector
+}
+
+// AggregateExpr is sum, avg, min, max or count over the series of Expr,
+// grouped by the By labels.
+type AggregateExpr struct {
+	Op   string
+	By   []string
+	Expr Expr
+}
+
+type BinaryExpr struct {
+	Op  string
+	LHS Expr
+	RHS Expr
+}
+
+func (NumberLiteral) Type() ValueType { return TypeScalar }
+func (Call) Type() ValueType          { return TypeVector }
+func (AggregateExpr) Type() ValueType { return TypeVector }
+
+func (s VectorSelector) Type() ValueType {
+	if s.Range > 0 {
+		return TypeMatrix
+	}
+	return TypeVector
+}
+
+func (b BinaryExpr) Type() ValueType {
+	if b.LHS.Type() == TypeScalar && b.RHS.Type() == TypeScalar {
+		return TypeScalar
+	}
+	return TypeVector
+}
+
+var (
+	functions    = map[string]bool{"rate": true, "increase": true}
+	aggregations = map[string]bool{"sum": true, "avg": true, "min": true, "max": true, "count": true}
+
+	comparisonOps     = map[string]bool{"==": true, "!=": true, ">": true, "<": true, ">=": true, "<=": true}
+	additiveOps       = map[string]bool{"+": true, "-": true}
+	multiplicativeOps = map[string]bool{"*": true, "/": true}
+	matchOps          = map[string]bool{
+		metrics.MatchEqual: true, metrics.MatchNotEqual: true, metrics.MatchRegexp: true, metrics.MatchNotRegexp: true,
+	}
+)
+
+// Parse parses an expression:
+//
+//	HeapAlloc / 1e6
+//	sum by (host) (rate(requests{code=~"5.."}[5m]))
+//	max(Load) - min(Load)
+//	Alloc{host="a"} > 1e9
+//
+// Comparisons filter the series of the left-hand side.
+func Parse(input string) (Expr, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %s at %d", t, t.pos)
+	}
+	if expr.Type() == TypeMatrix {
+		return nil, fmt.Errorf("range selectors are only allowed in rate() and increase()")
+	}
+	return expr, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, fmt.Errorf("expected %s, got %s at %d", what, t, t.pos)
+	}
+	return t, nil
+}
+
+func (p *parser) parseExpr() (Expr, error) {
+	return p.parseBinary(comparisonOps, p.parseAdditive)
+}
+
+func (p *parser) parseAdditive() (Expr, error) {
+	return p.parseBinary(additiveOps, p.parseMultiplicative)
+}
+
+func (p *parser) parseMultiplicative() (Expr, error) {
+	return p.parseBinary(multiplicativeOps, p.parseUnary)
+}
+
+// parseBinary parses left-associative operators of one precedence level.
+func (p *parser) parseBinary(ops map[string]bool, operand func() (Expr, error)) (Expr, error) {
+	lhs, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokOp || !ops[t.text] {
+			return lhs, nil
+		}
+		p.next()
+		rhs, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		for _, side := range []Expr{lhs, rhs} {
+			if side.Type() == TypeMatrix {
+				return nil, fmt.Errorf("range selectors are only allowed in rate() and increase()")
+			}
+		}
+		lhs = BinaryExpr{Op: t.text, LHS: lhs, RHS: rhs}
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if t := p.peek(); t.kind == tokOp && (t.text == "-" || t.text == "+") {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if expr.Type() == TypeMatrix {
+			return nil, fmt.Errorf("range selectors are only allowed in rate() and increase()")
+		}
+		if t.text == "+" {
+			return expr, nil
+		}
+		if n, ok := expr.(NumberLiteral); ok {
+			return NumberLiteral{Value: -n.Value}, nil
+		}
+		return BinaryExpr{Op: "*", LHS: NumberLiteral{Value: -1}, RHS: expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		val, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at %d", t, t.pos)
+		}
+		return NumberLiteral{Value: val}, nil
+	case tokLParen:
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, ")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	case tokIdent:
+		next := p.peek()
+		if aggregations[t.text] && (next.kind == tokLParen || next.kind == tokIdent && next.text == "by") {
+			return p.parseAggregation(t.text)
+		}
+		if functions[t.text] && next.kind == tokLParen {
+			return p.parseCall(t.text)
+		}
+		return p.parseSelector(t.text)
+	}
+	return nil, fmt.Errorf("unexpected %s at %d", t, t.pos)
+}
+
+func (p *parser) parseAggregation(op string) (Expr, error) {
+	agg := AggregateExpr{Op: op}
+	if t := p.peek(); t.kind == tokIdent && t.text == "by" {
+		p.next()
+		by, err := p.parseLabelList()
+		if err != nil {
+			return nil, err
+		}
+		agg.By = by
+	}
+
+	if _, err := p.expect(tokLParen, "("); err != nil {
+		return nil, err
+	}
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokRParen, ")"); err != nil {
+		return nil, err
+	}
+	if expr.Type() != TypeVector {
+		return nil, fmt.Errorf("%s() expects a vector, got %s", op, expr.Type())
+	}
+	agg.Expr = expr
+
+	if t := p.peek(); agg.By == nil && t.kind == tokIdent && t.text == "by" {
+		p.next()
+		if agg.By, err = p.parseLabelList(); err != nil {
+			return nil, err
+		}
+	}
+	return agg, nil
+}
+
+func (p *parser) parseLabelList() ([]string, error) {
+	if _, err := p.expect(tokLParen, "("); err != nil {
+		return nil, err
+	}
+	labels := []string{}
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokRParen:
+			return labels, nil
+		case t.kind == tokIdent:
+			labels = append(labels, t.text)
+			if p.peek().kind == tokComma {
+				p.next()
+			}
+		default:
+			return nil, fmt.Errorf("expected label name, got %s at %d", t, t.pos)
+		}
+	}
+}
+
+func (p *parser) parseCall(fn string) (Expr, error) {
+	p.next() // (
+	t, err := p.expect(tokIdent, "metric name")
+	if err != nil {
+		return nil, err
+	}
+	arg, err := p.parseSelector(t.text)
+	if err != nil {
+		return nil, err
+	}
+	sel := arg.(VectorSelector)
+	if sel.Range == 0 {
+		return nil, fmt.Errorf("%s() expects a range selector such as %s[5m]", fn, sel.Name)
+	}
+	if limit := maxRange(); sel.Range > limit {
+		return nil, fmt.Errorf("%s() range %s is longer than the %s of kept history", fn, sel.Range, limit)
+	}
+	if _, err := p.expect(tokRParen, ")"); err != nil {
+		return nil, err
+	}
+	return Call{Func: fn, Arg: &sel}, nil
+}
+
+func (p *parser) parseSelector(name string) (Expr, error) {
+	sel := VectorSelector{Name: name}
+	if p.peek().kind == tokLBrace {
+		p.next()
+		for p.peek().kind != tokRBrace {
+			matcher, err := p.parseMatcher()
+			if err != nil {
+				return nil, err
+			}
+			sel.Matchers = append(sel.Matchers, matcher)
+			if p.peek().kind == tokComma {
+				p.next()
+			} else if p.peek().kind != tokRBrace {
+				t := p.peek()
+				return nil, fmt.Errorf("expected , or }, got %s at %d", t, t.pos)
+			}
+		}
+		p.next() // }
+	}
+
+	if t := p.peek(); t.kind == tokDuration {
+		p.next()
+		window, err := time.ParseDuration(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range [%s] at %d", t.text, t.pos)
+		}
+		if window <= 0 {
+			return nil, fmt.Errorf("range must be positive at %d", t.pos)
+		}
+		sel.Range = window
+	}
+	return sel, nil
+}
+
+func (p *parser) parseMatcher() (metrics.LabelMatcher, error) {
+	name, err := p.expect(tokIdent, "label name")
+	if err != nil {
+		return metrics.LabelMatcher{}, err
+	}
+	op := p.next()
+	if op.kind != tokOp || !matchOps[op.text] {
+		return metrics.LabelMatcher{}, fmt.Errorf("expected label match operator, got %s at %d", op, op.pos)
+	}
+	value, err := p.expect(tokString, "quoted label value")
+	if err != nil {
+		return metrics.LabelMatcher{}, err
+	}
+	return metrics.NewLabelMatcher(name.text, op.text, value.text)
+}
@@ -0,0 +1,111 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+)
+
+func mustMatcher(t *testing.T, name, op, value string) metrics.LabelMatcher {
+	t.Helper()
+	m, err := metrics.NewLabelMatcher(name, op, value)
+	require.NoError(t, err)
+	return m
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Expr
+	}{
+		{name: "number", input: "1.5e3", want: NumberLiteral{Value: 1500}},
+		{name: "negative number", input: "-2", want: NumberLiteral{Value: -2}},
+		{name: "selector", input: "HeapAlloc", want: VectorSelector{Name: "HeapAlloc"}},
+		{
+			name:  "matchers",
+			input: `Load{host="a", dc=~"eu.*"}`,
+			want: VectorSelector{Name: "Load", Matchers: []metrics.LabelMatcher{
+				mustMatcher(t, "host", "=", "a"), mustMatcher(t, "dc", "=~", "eu.*"),
+			}},
+		},
+		{
+			name:  "precedence",
+			input: "a + b * 2",
+			want: BinaryExpr{Op: "+", LHS: VectorSelector{Name: "a"}, RHS: BinaryExpr{
+				Op: "*", LHS: VectorSelector{Name: "b"}, RHS: NumberLiteral{Value: 2},
+			}},
+		},
+		{
+			name:  "left associative",
+			input: "a - b - c",
+			want: BinaryExpr{Op: "-", LHS: BinaryExpr{Op: "-", LHS: VectorSelector{Name: "a"}, RHS: VectorSelector{Name: "b"}},
+				RHS: VectorSelector{Name: "c"}},
+		},
+		{
+			name:  "comparison",
+			input: "(a + 1) > 10",
+			want: BinaryExpr{Op: ">", LHS: BinaryExpr{Op: "+", LHS: VectorSelector{Name: "a"}, RHS: NumberLiteral{Value: 1}},
+				RHS: NumberLiteral{Value: 10}},
+		},
+		{
+			name:  "rate",
+			input: "rate(requests[5m])",
+			want:  Call{Func: "rate", Arg: &VectorSelector{Name: "requests", Range: 5 * time.Minute}},
+		},
+		{
+			name:  "aggregation by before",
+			input: "sum by (host, dc) (Load)",
+			want:  AggregateExpr{Op: "sum", By: []string{"host", "dc"}, Expr: VectorSelector{Name: "Load"}},
+		},
+		{
+			name:  "aggregation by after",
+			input: "max(Load) by (host)",
+			want:  AggregateExpr{Op: "max", By: []string{"host"}, Expr: VectorSelector{Name: "Load"}},
+		},
+		{name: "metric named like an aggregation", input: "count", want: VectorSelector{Name: "count"}},
+		{
+			name:  "unary minus",
+			input: "-Load",
+			want:  BinaryExpr{Op: "*", LHS: NumberLiteral{Value: -1}, RHS: VectorSelector{Name: "Load"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expr, err := Parse(test.input)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, expr)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"a +",
+		"(a",
+		"a b",
+		"Load[5m]",
+		"Load[5m] + 1",
+		"rate(Load)",
+		"rate(Load[soon])",
+		"rate(Load[1h])",
+		"-Load[5m]",
+		"+Load[5m]",
+		"-Load[5m] + 1",
+		"sum(1)",
+		`Load{host=a}`,
+		`Load{host~"a"}`,
+		`Load{host="a"`,
+		`Load{host=~"("}`,
+		"a # b",
+	} {
+		_, err := Parse(input)
+		assert.Error(t, err, input)
+	}
+}
@@ -0,0 +1,202 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/a-palonskaa/metrics-server/internal/selfmetrics"
+)
+
+var (
+	ErrRateLimited   = errors.New("update rate limit of the agent exceeded")
+	ErrSeriesLimit   = errors.New("series limit of the agent exceeded")
+	ErrBatchTooLarge = errors.New("update batch of the agent above its burst")
+)
+
+// Reasons of the updates rejected by a Limiter.
+const (
+	RejectedRate   = "agent_rate_limit"
+	RejectedSeries = "agent_series_limit"
+	RejectedBatch  = "agent_batch_limit"
+)
+
+// Limits apply to every agent separately, zero disables a limit.
+type Limits struct {
+	// MaxSeries is the number of distinct series an agent may update.
+	MaxSeries int
+	// Rate is the number of updates per second an agent may send on average,
+	// Burst the number it may send at once (at least 1).
+	Rate  float64
+	Burst int
+}
+
+// IdleTimeout is how long an agent is remembered after its last update. A
+// forgotten agent starts over with no series and a full burst.
+const IdleTimeout = 10 * time.Minute
+
+// MaxAgents is the number of agents a Limiter tracks at once. A new agent
+// beyond it makes the least recently seen one forgotten.
+var MaxAgents = 10_000
+
+// Limiter tracks the series and the update rate of agents.
+type Limiter struct {
+	limits Limits
+
+	mu        sync.Mutex
+	agents    map[string]*agent
+	lastSweep time.Time
+	// now is replaced in tests
+	now func() time.Time
+}
+
+type agent struct {
+	series   map[string]bool
+	tokens   float64
+	lastSeen time.Time
+}
+
+func NewLimiter(limits Limits) *Limiter {
+	limits.Burst = max(limits.Burst, 1)
+	return &Limiter{limits: limits, agents: make(map[string]*agent), now: time.Now}
+}
+
+func (l *Limiter) Limits() Limits {
+	return l.limits
+}
+
+// agent returns the state of an agent, creating it on first use. Agents idle
+// for IdleTimeout are dropped at most once per IdleTimeout, or when a new
+// agent does not fit MaxAgents, in which case the least recently seen agent is
+// dropped if none is idle. It is called with mu held.
+func (l *Limiter) agent(id string, now time.Time) *agent {
+	if now.Sub(l.lastSweep) >= IdleTimeout {
+		l.sweep(now)
+	}
+
+	a, ok := l.agents[id]
+	if ok {
+		return a
+	}
+	if len(l.agents) >= MaxAgents {
+		l.sweep(now)
+	}
+	for len(l.agents) >= MaxAgents {
+		l.evictOldest()
+	}
+	a = &agent{series: make(map[string]bool), tokens: float64(l.limits.Burst), lastSeen: now}
+	l.agents[id] = a
+	return a
+}
+
+// evictOldest drops the least recently seen agent. It is called with mu held.
+func (l *Limiter) evictOldest() {
+	var oldest string
+	var oldestSeen time.Time
+	for key, a := range l.agents {
+		if oldest == "" || a.lastSeen.Before(oldestSeen) {
+			oldest, oldestSeen = key, a.lastSeen
+		}
+	}
+	delete(l.agents, oldest)
+}
+
+// sweep drops the agents idle for IdleTimeout. It is called with mu held.
+func (l *Limiter) sweep(now time.Time) {
+	for key, a := range l.agents {
+		if now.Sub(a.lastSeen) >= IdleTimeout {
+			delete(l.agents, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// Allow takes one update from the token bucket of an agent.
+func (l *Limiter) Allow(id string) error {
+	return l.AllowN(id, 1)
+}
+
+// AllowN takes n updates sent together from the token bucket of an agent,
+// either all of them or none. More updates than the burst can never be taken
+// and are rejected with ErrBatchTooLarge rather than ErrRateLimited.
+func (l *Limiter) AllowN(id string, n int) error {
+	if l.limits.Rate <= 0 {
+		return nil
+	}
+	if n > l.limits.Burst {
+		return fmt.Errorf("%w: %d updates are sent together, at most %d are allowed",
+			ErrBatchTooLarge, n, l.limits.Burst)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	a := l.agent(id, now)
+	a.tokens = min(a.tokens+now.Sub(a.lastSeen).Seconds()*l.limits.Rate, float64(l.limits.Burst))
+	a.lastSeen = now
+	if a.tokens < float64(n) {
+		return fmt.Errorf("%w: at most %g updates per second with bursts of %d are allowed",
+			ErrRateLimited, l.limits.Rate, l.limits.Burst)
+	}
+	a.tokens -= float64(n)
+	return nil
+}
+
+// RetryAfter is how long an agent out of tokens waits for the next one.
+func (l *Limiter) RetryAfter() time.Duration {
+	if l.limits.Rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / l.limits.Rate)
+}
+
+// AdmitSeries records a series updated by an agent, an agent at its limit
+// may only update the series it already did.
+func (l *Limiter) AdmitSeries(id string, mType string, series string) error {
+	if l.limits.MaxSeries <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	a := l.agent(id, now)
+	a.lastSeen = now
+	key := mType + " " + series
+	if a.series[key] {
+		return nil
+	}
+	if len(a.series) >= l.limits.MaxSeries {
+		return fmt.Errorf("%w: at most %d series per agent are allowed", ErrSeriesLimit, l.limits.MaxSeries)
+	}
+	a.series[key] = true
+	return nil
+}
+
+// Admit takes an update of a series from the token bucket of an agent and
+// records the series, a rejection is counted by its reason.
+func (l *Limiter) Admit(id string, mType string, series string) error {
+	if err := l.Allow(id); err != nil {
+		selfmetrics.CountRejected(RejectedRate)
+		return err
+	}
+	if err := l.AdmitSeries(id, mType, series); err != nil {
+		selfmetrics.CountRejected(RejectedSeries)
+		return err
+	}
+	return nil
+}
+
+// Host identifies the agents of the listeners without an agent ID by the
+// host of their address.
+func Host(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
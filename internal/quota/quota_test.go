@@ -0,0 +1,104 @@
+package quota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	clock := start
+	limiter := NewLimiter(Limits{Rate: 2, Burst: 3})
+	limiter.now = func() time.Time { return clock }
+
+	for range 3 {
+		assert.NoError(t, limiter.Allow("a"))
+	}
+	assert.ErrorIs(t, limiter.Allow("a"), ErrRateLimited)
+	// every agent has its own bucket
+	assert.NoError(t, limiter.Allow("b"))
+
+	clock = start.Add(500 * time.Millisecond)
+	assert.NoError(t, limiter.Allow("a"))
+	assert.ErrorIs(t, limiter.Allow("a"), ErrRateLimited)
+
+	// the bucket refills up to the burst only
+	clock = start.Add(time.Minute)
+	for range 3 {
+		assert.NoError(t, limiter.Allow("a"))
+	}
+	assert.ErrorIs(t, limiter.Allow("a"), ErrRateLimited)
+	assert.Equal(t, 500*time.Millisecond, limiter.RetryAfter())
+}
+
+func TestLimiter_AdmitSeries(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	clock := start
+	limiter := NewLimiter(Limits{MaxSeries: 2})
+	limiter.now = func() time.Time { return clock }
+
+	assert.NoError(t, limiter.AdmitSeries("a", "gauge", "Alloc"))
+	assert.NoError(t, limiter.AdmitSeries("a", "counter", "Alloc"))
+	assert.ErrorIs(t, limiter.AdmitSeries("a", "gauge", "Frees"), ErrSeriesLimit)
+	assert.NoError(t, limiter.AdmitSeries("a", "gauge", "Alloc"))
+	assert.NoError(t, limiter.AdmitSeries("b", "gauge", "Frees"))
+
+	// idle agents are forgotten
+	clock = start.Add(IdleTimeout)
+	assert.NoError(t, limiter.AdmitSeries("a", "gauge", "Frees"))
+}
+
+func TestLimiter_Disabled(t *testing.T) {
+	limiter := NewLimiter(Limits{})
+	for range 100 {
+		assert.NoError(t, limiter.Allow("a"))
+	}
+	assert.Zero(t, limiter.RetryAfter())
+}
+
+func TestLimiter_AllowN(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	clock := start
+	limiter := NewLimiter(Limits{Rate: 1, Burst: 5})
+	limiter.now = func() time.Time { return clock }
+
+	assert.NoError(t, limiter.AllowN("a", 3))
+	// a batch is taken whole or not at all
+	assert.ErrorIs(t, limiter.AllowN("a", 3), ErrRateLimited)
+	assert.NoError(t, limiter.AllowN("a", 2))
+	assert.ErrorIs(t, limiter.Allow("a"), ErrRateLimited)
+
+	// a batch above the burst never fits, however long the agent waits
+	clock = start.Add(time.Hour)
+	assert.ErrorIs(t, limiter.AllowN("a", 6), ErrBatchTooLarge)
+	assert.NoError(t, limiter.AllowN("a", 5))
+}
+
+func TestLimiter_MaxAgents(t *testing.T) {
+	maxAgents := MaxAgents
+	MaxAgents = 2
+	defer func() { MaxAgents = maxAgents }()
+
+	start := time.Unix(1_700_000_000, 0)
+	clock := start
+	limiter := NewLimiter(Limits{MaxSeries: 1, Rate: 1})
+	limiter.now = func() time.Time { return clock }
+
+	assert.NoError(t, limiter.AdmitSeries("a", "gauge", "Alloc"))
+	clock = start.Add(time.Minute)
+	assert.NoError(t, limiter.AdmitSeries("b", "gauge", "Alloc"))
+	assert.ErrorIs(t, limiter.AdmitSeries("b", "gauge", "Frees"), ErrSeriesLimit)
+
+	// a new agent makes the least recently seen one forgotten
+	clock = start.Add(2 * time.Minute)
+	assert.NoError(t, limiter.Allow("c"))
+	assert.Len(t, limiter.agents, 2)
+	assert.NotContains(t, limiter.agents, "a")
+
+	// the forgotten agent starts over with no series
+	clock = start.Add(3 * time.Minute)
+	assert.NoError(t, limiter.AdmitSeries("a", "gauge", "Frees"))
+	assert.NotContains(t, limiter.agents, "b")
+}
@@ -0,0 +1,150 @@
+package recording
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	"github.com/a-palonskaa/metrics-server/internal/query"
+)
+
+// RuleConfig is a rule as written in the rules file:
+//
+//	{"rules": [
+//	  {"record": "cluster:HeapAlloc:sum", "expr": "sum(HeapAlloc)"},
+//	  {"record": "host:requests:rate5m", "expr": "sum by (host) (rate(requests[5m]))",
+//	   "labels": {"source": "recording"}}
+//	]}
+//
+//easyjson:json
+type RuleConfig struct {
+	Record string            `json:"record"`
+	Expr   string            `json:"expr"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+//easyjson:json
+type RulesFile struct {
+	Rules []RuleConfig `json:"rules"`
+}
+
+// Rule stores the result of Expr as gauges named Record. Every series of the
+// result becomes a gauge with its labels and the rule's Labels.
+type Rule struct {
+	Record string
+	Expr   query.Expr
+	Labels map[string]string
+}
+
+func ParseRule(cfg RuleConfig) (Rule, error) {
+	if cfg.Record == "" || strings.ContainsAny(cfg.Record, "{} \t") {
+		return Rule{}, fmt.Errorf("invalid record name %q", cfg.Record)
+	}
+	expr, err := query.Parse(cfg.Expr)
+	if err != nil {
+		return Rule{}, fmt.Errorf("rule %s: %w", cfg.Record, err)
+	}
+	return Rule{Record: cfg.Record, Expr: expr, Labels: cfg.Labels}, nil
+}
+
+func ParseRules(data []byte) ([]Rule, error) {
+	var file RulesFile
+	if err := file.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+
+	rules := make([]Rule, 0, len(file.Rules))
+	records := make(map[string]bool, len(file.Rules))
+	for _, cfg := range file.Rules {
+		rule, err := ParseRule(cfg)
+		if err != nil {
+			return nil, err
+		}
+		// two rules recording the same name would overwrite each other
+		if records[rule.Record] {
+			return nil, fmt.Errorf("duplicate record name %q", rule.Record)
+		}
+		records[rule.Record] = true
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRules(data)
+}
+
+// Evaluator writes the results of recording rules into a storage. Rules are
+// evaluated in order, so a rule may use the series recorded by the ones
+// before it.
+type Evaluator struct {
+	storage *memstorage.MetricsStorage
+	engine  *query.Engine
+	rules   []Rule
+}
+
+func NewEvaluator(storage *memstorage.MetricsStorage, rules []Rule) *Evaluator {
+	return &Evaluator{storage: storage, engine: query.NewEngine(storage), rules: rules}
+}
+
+// Eval evaluates every rule once at now. Results that are not finite numbers
+// are skipped.
+func (e *Evaluator) Eval(now time.Time) {
+	for _, rule := range e.rules {
+		val, err := e.engine.Instant(rule.Expr, now)
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to evaluate recording rule %s", rule.Record)
+			continue
+		}
+
+		switch v := val.(type) {
+		case query.Scalar:
+			e.record(rule, nil, float64(v))
+		case query.Vector:
+			for _, s := range v {
+				e.record(rule, s.Labels, s.Value)
+			}
+		}
+	}
+}
+
+func (e *Evaluator) record(rule Rule, labels map[string]string, val float64) {
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		return
+	}
+
+	merged := make(map[string]string, len(labels)+len(rule.Labels))
+	for k, v := range labels {
+		if k != query.MetricNameLabel {
+			merged[k] = v
+		}
+	}
+	for k, v := range rule.Labels {
+		merged[k] = v
+	}
+	series := metrics.SeriesName(rule.Record, merged)
+	if err := e.storage.AddGauge(series, metrics.Gauge(val)); err != nil {
+		log.Error().Err(err).Msgf("failed to record %s", series)
+	}
+}
+
+// RunEvalRoutine evaluates the rules every interval in the background.
+func (e *Evaluator) RunEvalRoutine(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			e.Eval(now)
+		}
+	}()
+}
@@ -0,0 +1,229 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package recording
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonFede6e5bDecodeGithubComAPalonskaaMetricsServerInternalRecording(in *jlexer.Lexer, out *RulesFile) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "rules":
+			if in.IsNull() {
+				in.Skip()
+				out.Rules = nil
+			} else {
+				in.Delim('[')
+				if out.Rules == nil {
+					if !in.IsDelim(']') {
+						out.Rules = make([]RuleConfig, 0, 1)
+					} else {
+						out.Rules = []RuleConfig{}
+					}
+				} else {
+					out.Rules = (out.Rules)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 RuleConfig
+					(v1).UnmarshalEasyJSON(in)
+					out.Rules = append(out.Rules, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonFede6e5bEncodeGithubComAPalonskaaMetricsServerInternalRecording(out *jwriter.Writer, in RulesFile) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"rules\":"
+		out.RawString(prefix[1:])
+		if in.Rules == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.Rules {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				(v3).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RulesFile) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonFede6e5bEncodeGithubComAPalonskaaMetricsServerInternalRecording(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RulesFile) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonFede6e5bEncodeGithubComAPalonskaaMetricsServerInternalRecording(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RulesFile) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonFede6e5bDecodeGithubComAPalonskaaMetricsServerInternalRecording(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RulesFile) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonFede6e5bDecodeGithubComAPalonskaaMetricsServerInternalRecording(l, v)
+}
+func easyjsonFede6e5bDecodeGithubComAPalonskaaMetricsServerInternalRecording1(in *jlexer.Lexer, out *RuleConfig) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "record":
+			out.Record = string(in.String())
+		case "expr":
+			out.Expr = string(in.String())
+		case "labels":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Labels = make(map[string]string)
+				} else {
+					out.Labels = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v4 string
+					v4 = string(in.String())
+					(out.Labels)[key] = v4
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonFede6e5bEncodeGithubComAPalonskaaMetricsServerInternalRecording1(out *jwriter.Writer, in RuleConfig) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"record\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Record))
+	}
+	{
+		const prefix string = ",\"expr\":"
+		out.RawString(prefix)
+		out.String(string(in.Expr))
+	}
+	if len(in.Labels) != 0 {
+		const prefix string = ",\"labels\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('{')
+			v5First := true
+			for v5Name, v5Value := range in.Labels {
+				if v5First {
+					v5First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v5Name))
+				out.RawByte(':')
+				out.String(string(v5Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RuleConfig) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonFede6e5bEncodeGithubComAPalonskaaMetricsServerInternalRecording1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RuleConfig) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonFede6e5bEncodeGithubComAPalonskaaMetricsServerInternalRecording1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RuleConfig) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonFede6e5bDecodeGithubComAPalonskaaMetricsServerInternalRecording1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RuleConfig) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonFede6e5bDecodeGithubComAPalonskaaMetricsServerInternalRecording1(l, v)
+}
@@ -0,0 +1,72 @@
+package recording
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules([]byte(`{"rules": [
+		{"record": "cluster:HeapAlloc:sum", "expr": "sum(HeapAlloc)"},
+		{"record": "dc:Load:max", "expr": "max by (dc) (Load)", "labels": {"source": "rule"}}
+	]}`))
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "dc:Load:max", rules[1].Record)
+	assert.Equal(t, map[string]string{"source": "rule"}, rules[1].Labels)
+
+	for _, data := range []string{
+		`{"rules": [{"record": "", "expr": "1"}]}`,
+		`{"rules": [{"record": "a{b=\"c\"}", "expr": "1"}]}`,
+		`{"rules": [{"record": "a", "expr": "sum("}]}`,
+		`{"rules": [{"record": "a", "expr": "1"}, {"record": "a", "expr": "2"}]}`,
+		`{"rules": [`,
+	} {
+		_, err := ParseRules([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestEvaluator(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	storage.AddGauge(`HeapAlloc{agent="a",dc="eu"}`, 100)
+	storage.AddGauge(`HeapAlloc{agent="b",dc="eu"}`, 200)
+	storage.AddGauge(`HeapAlloc{agent="c",dc="us"}`, 50)
+
+	rules, err := ParseRules([]byte(`{"rules": [
+		{"record": "cluster:HeapAlloc:sum", "expr": "sum(HeapAlloc)"},
+		{"record": "dc:HeapAlloc:sum", "expr": "sum by (dc) (HeapAlloc)", "labels": {"source": "rule"}},
+		{"record": "cluster:HeapAlloc:share", "expr": "dc:HeapAlloc:sum / on_missing"},
+		{"record": "cluster:HeapAlloc:mb", "expr": "cluster:HeapAlloc:sum / 1e6"},
+		{"record": "zero", "expr": "1 / 0"}
+	]}`))
+	require.NoError(t, err)
+
+	NewEvaluator(storage, rules).Eval(time.Now())
+
+	tests := []struct {
+		series string
+		want   metrics.Gauge
+	}{
+		{series: "cluster:HeapAlloc:sum", want: 350},
+		{series: `dc:HeapAlloc:sum{dc="eu",source="rule"}`, want: 300},
+		{series: `dc:HeapAlloc:sum{dc="us",source="rule"}`, want: 50},
+		{series: "cluster:HeapAlloc:mb", want: 0.00035},
+	}
+	for _, test := range tests {
+		val, ok := storage.GetGaugeValue(test.series)
+		require.True(t, ok, test.series)
+		assert.InDelta(t, float64(test.want), float64(val), 1e-12, test.series)
+	}
+
+	_, ok := storage.GetGaugeValue("zero")
+	assert.False(t, ok)
+	_, ok = storage.GetGaugeValue("cluster:HeapAlloc:share")
+	assert.False(t, ok)
+}
@@ -0,0 +1,276 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+// Modes decide what happens to series of undeclared names.
+const (
+	ModeReject = "reject" // the update is rejected
+	ModeWarn   = "warn"   // the update is accepted and a warning is logged
+	ModeAuto   = "auto"   // the name is declared with the type of the update
+)
+
+var ErrRejected = errors.New("rejected by the metrics schema")
+
+// Metric declares a metric name. Updates of another type or with labels not
+// in Labels break the declaration, an empty Labels allows any labels.
+//
+//easyjson:json
+type Metric struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Unit        string   `json:"unit,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+//easyjson:json
+type MetricList []Metric
+
+// File is the schema as written in the schema file:
+//
+//	{"mode": "reject",
+//	 "allow": ["^debug_"],
+//	 "deny": ["^tmp_"],
+//	 "metrics": [
+//	   {"name": "HeapAlloc", "type": "gauge", "unit": "bytes",
+//	    "description": "Bytes of allocated heap objects", "labels": ["host"]}
+//	 ]}
+//
+// Names matching a Deny pattern are always rejected, names matching an Allow
+// pattern are accepted without a declaration. Patterns are unanchored
+// regular expressions on the metric name.
+//
+//easyjson:json
+type File struct {
+	Mode    string   `json:"mode"`
+	Allow   []string `json:"allow,omitempty"`
+	Deny    []string `json:"deny,omitempty"`
+	Metrics []Metric `json:"metrics"`
+}
+
+// Registry checks new series against the declared metrics. Violations of a
+// declaration are rejected unless the mode is ModeWarn.
+type Registry struct {
+	mode  string
+	allow []*regexp.Regexp
+	deny  []*regexp.Regexp
+
+	mu      sync.RWMutex
+	metrics map[string]Metric
+	// warned holds the series a warning was logged for, each one is logged
+	// once
+	warned map[string]bool
+}
+
+func New(file File) (*Registry, error) {
+	switch file.Mode {
+	case ModeReject, ModeWarn, ModeAuto:
+	case "":
+		file.Mode = ModeReject
+	default:
+		return nil, fmt.Errorf("unknown schema mode %q", file.Mode)
+	}
+
+	r := &Registry{
+		mode:    file.Mode,
+		metrics: make(map[string]Metric, len(file.Metrics)),
+		warned:  make(map[string]bool),
+	}
+	var err error
+	if r.allow, err = compilePatterns(file.Allow); err != nil {
+		return nil, err
+	}
+	if r.deny, err = compilePatterns(file.Deny); err != nil {
+		return nil, err
+	}
+
+	for _, m := range file.Metrics {
+		if m.Name == "" {
+			return nil, fmt.Errorf("metric without a name")
+		}
+		if !memstorage.IsTypeAllowed(m.Type) {
+			return nil, fmt.Errorf("metric %s: unknown type %q", m.Name, m.Type)
+		}
+		if _, ok := r.metrics[m.Name]; ok {
+			return nil, fmt.Errorf("metric %s is declared twice", m.Name)
+		}
+		r.metrics[m.Name] = m
+	}
+	return r, nil
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+func Parse(data []byte) (*Registry, error) {
+	var file File
+	if err := file.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return New(file)
+}
+
+func Load(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+func matchAny(patterns []*regexp.Regexp, name string) bool {
+	for _, re := range patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check decides whether a new series of a type may be stored, the returned
+// error wraps ErrRejected. It is installed as the storage admission.
+func (r *Registry) Check(mType string, series string) error {
+	name, labels, err := metrics.ParseSeriesName(series)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrRejected, err)
+	}
+	if matchAny(r.deny, name) {
+		return r.reject(series, "%s is denied", name)
+	}
+
+	r.mu.RLock()
+	m, ok := r.metrics[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		if matchAny(r.allow, name) {
+			return nil
+		}
+		switch r.mode {
+		case ModeWarn:
+			r.warn(series, "%s is not declared", name)
+			return nil
+		case ModeAuto:
+			r.declare(Metric{Name: name, Type: mType})
+			return nil
+		}
+		return r.reject(series, "%s is not declared", name)
+	}
+
+	if m.Type != mType {
+		return r.violate(series, "%s is declared as a %s, not a %s", name, m.Type, mType)
+	}
+	for label := range labels {
+		if !m.allowsLabel(label) {
+			return r.violate(series, "label %s is not declared for %s", label, name)
+		}
+	}
+	return nil
+}
+
+func (m Metric) allowsLabel(label string) bool {
+	if len(m.Labels) == 0 {
+		return true
+	}
+	for _, l := range m.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Registry) violate(series string, format string, args ...any) error {
+	if r.mode == ModeWarn {
+		r.warn(series, format, args...)
+		return nil
+	}
+	return r.reject(series, format, args...)
+}
+
+func (r *Registry) reject(series string, format string, args ...any) error {
+	err := fmt.Errorf("%w: %s", ErrRejected, fmt.Sprintf(format, args...))
+	if r.markWarned(series) {
+		log.Warn().Str("series", series).Err(err).Msg("series rejected")
+	}
+	return err
+}
+
+func (r *Registry) warn(series string, format string, args ...any) {
+	if r.markWarned(series) {
+		log.Warn().Str("series", series).Msgf("schema violation accepted: "+format, args...)
+	}
+}
+
+// maxWarned bounds the series remembered by markWarned, a client sending
+// endless new names gets them logged again after the set is cleared.
+const maxWarned = 10000
+
+// markWarned returns true the first time it is called for a series.
+func (r *Registry) markWarned(series string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.warned[series] {
+		return false
+	}
+	if len(r.warned) >= maxWarned {
+		r.warned = make(map[string]bool)
+	}
+	r.warned[series] = true
+	return true
+}
+
+func (r *Registry) declare(m Metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.metrics[m.Name]; ok {
+		return
+	}
+	r.metrics[m.Name] = m
+	log.Info().Str("name", m.Name).Str("type", m.Type).Msg("metric registered")
+}
+
+// Lookup returns the declaration of a metric name.
+func (r *Registry) Lookup(name string) (Metric, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.metrics[name]
+	return m, ok
+}
+
+// Metrics returns the declared metrics sorted by name.
+func (r *Registry) Metrics() MetricList {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res := make(MetricList, 0, len(r.metrics))
+	for _, m := range r.metrics {
+		res = append(res, m)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+const testSchema = `{
+	"mode": "%s",
+	"allow": ["^debug_"],
+	"deny": ["^tmp_"],
+	"metrics": [
+		{"name": "HeapAlloc", "type": "gauge", "unit": "bytes", "description": "Heap bytes", "labels": ["host"]},
+		{"name": "requests", "type": "counter"}
+	]
+}`
+
+func parseMode(t *testing.T, mode string) *Registry {
+	t.Helper()
+	registry, err := Parse([]byte(fmt.Sprintf(testSchema, mode)))
+	require.NoError(t, err)
+	return registry
+}
+
+func TestRegistry_Check(t *testing.T) {
+	tests := []struct {
+		name   string
+		mType  string
+		series string
+		// accepted by mode
+		reject, warn, auto bool
+	}{
+		{name: "declared", mType: metrics.GaugeName, series: `HeapAlloc{host="a"}`, reject: true, warn: true, auto: true},
+		{name: "any labels", mType: metrics.CounterName, series: `requests{code="200"}`, reject: true, warn: true, auto: true},
+		{name: "undeclared label", mType: metrics.GaugeName, series: `HeapAlloc{dc="eu"}`, warn: true},
+		{name: "wrong type", mType: metrics.CounterName, series: "HeapAlloc", warn: true},
+		{name: "undeclared", mType: metrics.GaugeName, series: "Typo", warn: true, auto: true},
+		{name: "allowed", mType: metrics.SetName, series: "debug_users", reject: true, warn: true, auto: true},
+		{name: "denied", mType: metrics.GaugeName, series: "tmp_load"},
+	}
+
+	for _, mode := range []string{ModeReject, ModeWarn, ModeAuto} {
+		registry := parseMode(t, mode)
+		for _, test := range tests {
+			t.Run(mode+"/"+test.name, func(t *testing.T) {
+				want := map[string]bool{ModeReject: test.reject, ModeWarn: test.warn, ModeAuto: test.auto}[mode]
+				err := registry.Check(test.mType, test.series)
+				if want {
+					assert.NoError(t, err)
+				} else {
+					assert.ErrorIs(t, err, ErrRejected)
+				}
+			})
+		}
+	}
+}
+
+func TestRegistry_Auto(t *testing.T) {
+	registry := parseMode(t, ModeAuto)
+
+	require.NoError(t, registry.Check(metrics.GaugeName, `Load{host="a"}`))
+	m, ok := registry.Lookup("Load")
+	require.True(t, ok)
+	assert.Equal(t, Metric{Name: "Load", Type: metrics.GaugeName}, m)
+
+	// the registered type sticks
+	assert.ErrorIs(t, registry.Check(metrics.CounterName, "Load"), ErrRejected)
+	assert.Equal(t, []string{"HeapAlloc", "Load", "requests"}, names(registry.Metrics()))
+}
+
+func names(list MetricList) []string {
+	res := make([]string, len(list))
+	for i, m := range list {
+		res[i] = m.Name
+	}
+	return res
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, data := range []string{
+		`{"mode": "strict"}`,
+		`{"deny": ["("]}`,
+		`{"metrics": [{"name": "a", "type": "meter"}]}`,
+		`{"metrics": [{"type": "gauge"}]}`,
+		`{"metrics": [{"name": "a", "type": "gauge"}, {"name": "a", "type": "counter"}]}`,
+		`{"metrics": `,
+	} {
+		_, err := Parse([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestRegistry_Admission(t *testing.T) {
+	registry := parseMode(t, ModeReject)
+	memstorage.SetAdmission(registry.Check)
+	defer memstorage.SetAdmission(nil)
+
+	storage := memstorage.NewMetricsStorage()
+	storage.AddGauge("HeapAlloc", 1)
+	storage.AddGauge("Typo", 1)
+	storage.AddCounter("requests", 1)
+	storage.AddCounter("HeapAlloc", 1)
+
+	assert.Equal(t, []string{"HeapAlloc"}, storage.Series(metrics.GaugeName))
+	assert.Equal(t, []string{"requests"}, storage.Series(metrics.CounterName))
+	assert.ErrorIs(t, storage.AddHistogram("tmp_latency", metrics.NewHistogram(nil)), ErrRejected)
+	assert.ErrorIs(t, storage.Admit(metrics.GaugeName, "Typo"), ErrRejected)
+	assert.NoError(t, storage.Admit(metrics.GaugeName, "HeapAlloc"))
+}
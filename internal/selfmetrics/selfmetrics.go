@@ -0,0 +1,209 @@
+package selfmetrics
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+)
+
+// Namespace prefixes the names of the metrics of the server itself.
+const Namespace = "metrics_server_"
+
+// DurationBuckets are the bounds in seconds of the latency histograms.
+var DurationBuckets = metrics.DefaultBuckets
+
+const (
+	RequestsTotal         = Namespace + "http_requests_total"
+	RequestDuration       = Namespace + "http_request_duration_seconds"
+	SnapshotDuration      = Namespace + "snapshot_duration_seconds"
+	SnapshotSize          = Namespace + "snapshot_size_bytes"
+	SnapshotFailures      = Namespace + "snapshot_failures_total"
+	DecompressionFailures = Namespace + "decompression_failures_total"
+	RejectedUpdatesTotal  = Namespace + "rejected_updates_total"
+	Goroutines            = Namespace + "goroutines"
+	MemoryAlloc           = Namespace + "memory_alloc_bytes"
+	MemorySys             = Namespace + "memory_sys_bytes"
+	HeapObjects           = Namespace + "memory_heap_objects"
+	GCCycles              = Namespace + "gc_cycles_total"
+)
+
+var help = map[string]string{
+	RequestsTotal:         "HTTP requests served by route, method and status.",
+	RequestDuration:       "Latency of the HTTP requests by route, method and status.",
+	SnapshotDuration:      "Time taken to write a snapshot of the storage.",
+	SnapshotSize:          "Size of the last snapshot of the storage.",
+	SnapshotFailures:      "Snapshots of the storage that failed.",
+	DecompressionFailures: "Request bodies that could not be decompressed.",
+	RejectedUpdatesTotal:  "Updates rejected by the server by reason.",
+	Goroutines:            "Number of goroutines of the server.",
+	MemoryAlloc:           "Bytes of allocated heap objects.",
+	MemorySys:             "Bytes of memory obtained from the OS.",
+	HeapObjects:           "Number of allocated heap objects.",
+	GCCycles:              "Completed garbage collection cycles.",
+}
+
+type requestKey struct {
+	route  string
+	method string
+	status int
+}
+
+type requestStats struct {
+	count    metrics.Counter
+	duration metrics.Histogram
+}
+
+var (
+	mu sync.Mutex
+
+	requests              = make(map[requestKey]*requestStats)
+	snapshotDuration      = metrics.NewHistogram(DurationBuckets)
+	snapshotSize          metrics.Gauge
+	snapshotFailures      metrics.Counter
+	decompressionFailures metrics.Counter
+	rejected              = make(map[string]metrics.Counter)
+	gauges                = make(map[string]gaugeFunc)
+)
+
+type gaugeFunc struct {
+	help string
+	f    func() float64
+}
+
+// ObserveRequest records a served request, route is the pattern the request
+// matched rather than its path so that the number of series stays bounded.
+func ObserveRequest(route string, method string, status int, d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	key := requestKey{route: route, method: method, status: status}
+	stats, ok := requests[key]
+	if !ok {
+		stats = &requestStats{duration: metrics.NewHistogram(DurationBuckets)}
+		requests[key] = stats
+	}
+	stats.count++
+	stats.duration.Observe(d.Seconds())
+}
+
+// ObserveSnapshot records a write of the storage file of size bytes.
+func ObserveSnapshot(d time.Duration, size int, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err != nil {
+		snapshotFailures++
+		return
+	}
+	snapshotDuration.Observe(d.Seconds())
+	snapshotSize = metrics.Gauge(size)
+}
+
+func CountDecompressionFailure() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	decompressionFailures++
+}
+
+// CountRejected counts an update rejected for reason.
+func CountRejected(reason string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	rejected[reason]++
+}
+
+// RejectedUpdates returns the number of rejected updates by reason.
+func RejectedUpdates() map[string]uint64 {
+	mu.Lock()
+	defer mu.Unlock()
+
+	res := make(map[string]uint64, len(rejected))
+	for reason, n := range rejected {
+		res[reason] = uint64(n)
+	}
+	return res
+}
+
+// SetGauge exposes the value returned by f as the gauge Namespace+name, f is
+// called on every Iterate.
+func SetGauge(name string, description string, f func() float64) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	gauges[Namespace+name] = gaugeFunc{help: description, f: f}
+}
+
+// Help returns the description of a metric of the server.
+func Help(name string) string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if g, ok := gauges[name]; ok {
+		return g.help
+	}
+	return help[name]
+}
+
+type sample struct {
+	series string
+	mType  string
+	val    fmt.Stringer
+}
+
+// Iterate calls fn with the current value of every metric of the server, in
+// the form of the storage Iterate.
+func Iterate(fn func(series string, mType string, val fmt.Stringer)) {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	samples := []sample{
+		{Goroutines, metrics.GaugeName, metrics.Gauge(runtime.NumGoroutine())},
+		{MemoryAlloc, metrics.GaugeName, metrics.Gauge(mem.Alloc)},
+		{MemorySys, metrics.GaugeName, metrics.Gauge(mem.Sys)},
+		{HeapObjects, metrics.GaugeName, metrics.Gauge(mem.HeapObjects)},
+		{GCCycles, metrics.CounterName, metrics.Counter(mem.NumGC)},
+	}
+
+	// the values are copied so that neither fn nor the gauges run with mu
+	// held, the gauges may lock the storage which counts rejections under
+	// its own lock
+	mu.Lock()
+	for key, stats := range requests {
+		labels := map[string]string{
+			"route":  key.route,
+			"method": key.method,
+			"status": strconv.Itoa(key.status),
+		}
+		samples = append(samples,
+			sample{metrics.SeriesName(RequestsTotal, labels), metrics.CounterName, stats.count},
+			sample{metrics.SeriesName(RequestDuration, labels), metrics.HistogramName, stats.duration.Clone()},
+		)
+	}
+	samples = append(samples,
+		sample{SnapshotDuration, metrics.HistogramName, snapshotDuration.Clone()},
+		sample{SnapshotSize, metrics.GaugeName, snapshotSize},
+		sample{SnapshotFailures, metrics.CounterName, snapshotFailures},
+		sample{DecompressionFailures, metrics.CounterName, decompressionFailures},
+	)
+	for reason, n := range rejected {
+		samples = append(samples, sample{metrics.SeriesName(RejectedUpdatesTotal, map[string]string{"reason": reason}), metrics.CounterName, n})
+	}
+	funcs := make(map[string]func() float64, len(gauges))
+	for name, g := range gauges {
+		funcs[name] = g.f
+	}
+	mu.Unlock()
+
+	for name, f := range funcs {
+		samples = append(samples, sample{name, metrics.GaugeName, metrics.Gauge(f())})
+	}
+	for _, s := range samples {
+		fn(s.series, s.mType, s.val)
+	}
+}
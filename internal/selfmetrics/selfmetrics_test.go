@@ -0,0 +1,59 @@
+package selfmetrics
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+)
+
+func collect() map[string]fmt.Stringer {
+	res := make(map[string]fmt.Stringer)
+	Iterate(func(series string, mType string, val fmt.Stringer) {
+		res[series] = val
+	})
+	return res
+}
+
+func TestIterate(t *testing.T) {
+	ObserveRequest("/update/", "POST", 200, 20*time.Millisecond)
+	ObserveRequest("/update/", "POST", 200, 2*time.Second)
+	ObserveRequest("/update/", "POST", 400, time.Millisecond)
+	ObserveSnapshot(30*time.Millisecond, 1024, nil)
+	ObserveSnapshot(time.Second, 0, errors.New("disk full"))
+	CountDecompressionFailure()
+	CountRejected("series_limit")
+	SetGauge("test_gauge", "A test gauge.", func() float64 { return 42 })
+
+	got := collect()
+
+	labels := map[string]string{"route": "/update/", "method": "POST", "status": "200"}
+	assert.Equal(t, metrics.Counter(2), got[metrics.SeriesName(RequestsTotal, labels)])
+	hist, ok := got[metrics.SeriesName(RequestDuration, labels)].(metrics.Histogram)
+	require.True(t, ok)
+	assert.Equal(t, uint64(2), hist.Count)
+	assert.InDelta(t, 2.02, hist.Sum, 1e-9)
+
+	labels["status"] = "400"
+	assert.Equal(t, metrics.Counter(1), got[metrics.SeriesName(RequestsTotal, labels)])
+
+	snapshots, ok := got[SnapshotDuration].(metrics.Histogram)
+	require.True(t, ok)
+	assert.Equal(t, uint64(1), snapshots.Count)
+	assert.Equal(t, metrics.Gauge(1024), got[SnapshotSize])
+	assert.Equal(t, metrics.Counter(1), got[SnapshotFailures])
+	assert.Equal(t, metrics.Counter(1), got[DecompressionFailures])
+	assert.Equal(t, metrics.Counter(1), got[metrics.SeriesName(RejectedUpdatesTotal, map[string]string{"reason": "series_limit"})])
+	assert.Equal(t, metrics.Gauge(42), got[Namespace+"test_gauge"])
+	assert.Positive(t, float64(got[Goroutines].(metrics.Gauge)))
+	assert.Contains(t, got, MemoryAlloc)
+
+	assert.Equal(t, "A test gauge.", Help(Namespace+"test_gauge"))
+	assert.Equal(t, "Number of goroutines of the server.", Help(Goroutines))
+	assert.Empty(t, Help("Alloc"))
+}
@@ -0,0 +1,275 @@
+package statsd
+
+import (
+	"bufio"
+	"errors"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	"github.com/a-palonskaa/metrics-server/internal/quota"
+	"github.com/a-palonskaa/metrics-server/internal/selfmetrics"
+)
+
+const maxPacketSize = 65535
+
+// TimerPercentiles are flushed as "<name>.p<percentile>" gauges.
+var TimerPercentiles = []float64{90, 99}
+
+// Listener applies StatsD lines to the storage. Counters, gauges and sets
+// are stored as soon as they arrive, timer values are buffered and flushed
+// as gauges once per flush interval.
+type Listener struct {
+	storage       *memstorage.MetricsStorage
+	flushInterval time.Duration
+	// quota limits the lines of every client host, nil if no limit is set
+	quota *quota.Limiter
+
+	mu     sync.Mutex
+	timers map[string][]timerValue
+}
+
+// timerValue is a received timing, a sampled one stands for 1/rate timings.
+type timerValue struct {
+	value  float64
+	weight float64
+}
+
+func NewListener(storage *memstorage.MetricsStorage, flushInterval time.Duration) *Listener {
+	return &Listener{
+		storage:       storage,
+		flushInterval: flushInterval,
+		timers:        make(map[string][]timerValue),
+	}
+}
+
+// SetQuota limits the lines of every client host like the updates of an
+// agent, a timer line takes a single update and the series of all the gauges
+// it is flushed as.
+func (l *Listener) SetQuota(limiter *quota.Limiter) {
+	l.quota = limiter
+}
+
+// storedTypes are the storage types the lines are counted as by the quota.
+var storedTypes = map[string]string{
+	TypeCounter: metrics.CounterName,
+	TypeGauge:   metrics.GaugeName,
+	TypeSet:     metrics.SetName,
+}
+
+// timerSuffixes returns the suffixes of the gauges a timer is flushed as.
+func timerSuffixes() []string {
+	suffixes := []string{".count", ".sum", ".mean", ".lower", ".upper"}
+	for _, p := range TimerPercentiles {
+		suffixes = append(suffixes, ".p"+strconv.FormatFloat(p, 'f', -1, 64))
+	}
+	return suffixes
+}
+
+// admit takes a line sent by agent from the quota, a rejection is counted.
+func (l *Listener) admit(agent string, line Line) error {
+	mType, ok := storedTypes[line.Type]
+	if ok {
+		return l.quota.Admit(agent, mType, metrics.SeriesName(line.Name, line.Tags))
+	}
+
+	suffixes := timerSuffixes()
+	if err := l.quota.Admit(agent, metrics.GaugeName, metrics.SeriesName(line.Name+suffixes[0], line.Tags)); err != nil {
+		return err
+	}
+	for _, suffix := range suffixes[1:] {
+		if err := l.quota.AdmitSeries(agent, metrics.GaugeName, metrics.SeriesName(line.Name+suffix, line.Tags)); err != nil {
+			selfmetrics.CountRejected(quota.RejectedSeries)
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply stores a single parsed line, the error is the one the storage
+// rejected it with.
+func (l *Listener) Apply(line Line) error {
+	switch line.Type {
+	case TypeCounter:
+		val := math.Round(line.Value / line.SampleRate)
+		return l.storage.AddCounter(metrics.SeriesName(line.Name, line.Tags), metrics.Counter(val))
+	case TypeGauge:
+		name := metrics.SeriesName(line.Name, line.Tags)
+		if line.Relative {
+			return l.storage.AdjustGauge(name, metrics.Gauge(line.Value))
+		}
+		return l.storage.AddGauge(name, metrics.Gauge(line.Value))
+	case TypeSet:
+		return l.storage.AddSetMembers(metrics.SeriesName(line.Name, line.Tags), line.Member)
+	case TypeTimer, TypeHistogram, TypeDistribution:
+		key := metrics.SeriesName(line.Name, line.Tags)
+		l.mu.Lock()
+		l.timers[key] = append(l.timers[key], timerValue{value: line.Value, weight: 1 / line.SampleRate})
+		l.mu.Unlock()
+	}
+	return nil
+}
+
+// HandlePacket parses and applies every line of a packet sent by agent.
+// Rejected lines are logged, the storage and the quota count them.
+func (l *Listener) HandlePacket(agent string, packet string) {
+	lines, errs := ParsePacket(packet)
+	for _, err := range errs {
+		log.Error().Err(err).Msg("failed to parse statsd line")
+	}
+	for _, line := range lines {
+		if l.quota != nil {
+			if err := l.admit(agent, line); err != nil {
+				log.Error().Err(err).Str("agent", agent).Str("name", line.Name).Msg("statsd line rejected")
+				continue
+			}
+		}
+		if err := l.Apply(line); err != nil {
+			log.Error().Err(err).Str("name", line.Name).Msg("statsd line rejected")
+		}
+	}
+}
+
+// Flush stores count, sum, mean, lower, upper and TimerPercentiles of every
+// timer received since the previous flush.
+func (l *Listener) Flush() {
+	l.mu.Lock()
+	timers := l.timers
+	l.timers = make(map[string][]timerValue)
+	l.mu.Unlock()
+
+	for series, values := range timers {
+		name, labels, err := metrics.ParseSeriesName(series)
+		if err != nil || len(values) == 0 {
+			continue
+		}
+		sort.Slice(values, func(i, j int) bool { return values[i].value < values[j].value })
+
+		var count, sum float64
+		for _, v := range values {
+			count += v.weight
+			sum += v.value * v.weight
+		}
+
+		gauges := []float64{count, sum, sum / count, values[0].value, values[len(values)-1].value}
+		for _, p := range TimerPercentiles {
+			gauges = append(gauges, percentile(values, count, p))
+		}
+
+		for i, suffix := range timerSuffixes() {
+			if err := l.storage.AddGauge(metrics.SeriesName(name+suffix, labels), metrics.Gauge(gauges[i])); err != nil {
+				log.Error().Err(err).Str("name", name+suffix).Msg("statsd timer rejected")
+			}
+		}
+	}
+}
+
+func (l *Listener) RunFlushRoutine() {
+	go func() {
+		ticker := time.NewTicker(l.flushInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			l.Flush()
+		}
+	}()
+}
+
+// ServeUDP reads packets from conn until it is closed.
+func (l *Listener) ServeUDP(conn net.PacketConn) error {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		l.HandlePacket(quota.Host(addr), string(buf[:n]))
+	}
+}
+
+// ServeTCP accepts connections from ln until it is closed. Every connection
+// carries newline separated lines.
+func (l *Listener) ServeTCP(ln net.Listener) error {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go l.handleConn(conn)
+	}
+}
+
+func (l *Listener) handleConn(conn net.Conn) {
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Error().Err(err).Msg("failed to close statsd connection")
+		}
+	}()
+
+	agent := quota.Host(conn.RemoteAddr())
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 4096), maxPacketSize)
+	for scanner.Scan() {
+		l.HandlePacket(agent, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		log.Error().Err(err).Msg("failed to read statsd connection")
+	}
+}
+
+// ListenAndServe starts the UDP and/or TCP listeners on the given addresses
+// in background goroutines. An empty address disables the listener.
+func (l *Listener) ListenAndServe(udpAddr, tcpAddr string) error {
+	if udpAddr != "" {
+		conn, err := net.ListenPacket("udp", udpAddr)
+		if err != nil {
+			return err
+		}
+		go func() {
+			if err := l.ServeUDP(conn); err != nil {
+				log.Error().Err(err).Msg("statsd udp listener stopped")
+			}
+		}()
+	}
+
+	if tcpAddr != "" {
+		ln, err := net.Listen("tcp", tcpAddr)
+		if err != nil {
+			return err
+		}
+		go func() {
+			if err := l.ServeTCP(ln); err != nil {
+				log.Error().Err(err).Msg("statsd tcp listener stopped")
+			}
+		}()
+	}
+
+	l.RunFlushRoutine()
+	return nil
+}
+
+// percentile returns the smallest value whose cumulative weight reaches p
+// percent of the total. values must be sorted.
+func percentile(values []timerValue, total float64, p float64) float64 {
+	rank := p / 100 * total
+	var seen float64
+	for _, v := range values {
+		seen += v.weight
+		if seen >= rank {
+			return v.value
+		}
+	}
+	return values[len(values)-1].value
+}
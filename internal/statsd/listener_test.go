@@ -0,0 +1,144 @@
+package statsd
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+	"github.com/a-palonskaa/metrics-server/internal/quota"
+)
+
+func TestListener_Apply(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	l := NewListener(storage, time.Second)
+
+	l.HandlePacket("test", "hits:2|c|@0.5\nhits:1|c\nqueue:10|g\nqueue:-3|g\nusers:alice|s\nusers:bob|s\nusers:alice|s")
+	l.HandlePacket("test", "cpu:0.5|g|#host:a")
+
+	hits, ok := storage.GetCounterValue("hits")
+	require.True(t, ok)
+	assert.Equal(t, metrics.Counter(5), hits)
+
+	queue, ok := storage.GetGaugeValue("queue")
+	require.True(t, ok)
+	assert.Equal(t, metrics.Gauge(7), queue)
+
+	users, ok := storage.GetSetValue("users")
+	require.True(t, ok)
+	assert.Equal(t, uint64(2), users.Cardinality())
+
+	cpu, ok := storage.GetGaugeValue(`cpu{host="a"}`)
+	require.True(t, ok)
+	assert.Equal(t, metrics.Gauge(0.5), cpu)
+}
+
+func TestListener_ApplyRejected(t *testing.T) {
+	memstorage.SetMaxSeries(1)
+	defer memstorage.SetMaxSeries(0)
+
+	storage := memstorage.NewMetricsStorage()
+	l := NewListener(storage, time.Second)
+
+	require.NoError(t, l.Apply(Line{Name: "hits", Type: TypeCounter, Value: 1, SampleRate: 1}))
+	assert.ErrorIs(t, l.Apply(Line{Name: "queue", Type: TypeGauge, Value: 1, SampleRate: 1}), memstorage.ErrSeriesLimit)
+	assert.Equal(t, []string{}, storage.Series(metrics.GaugeName))
+}
+
+func TestListener_Quota(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	l := NewListener(storage, time.Second)
+	l.SetQuota(quota.NewLimiter(quota.Limits{MaxSeries: 1}))
+
+	l.HandlePacket("a", "quota_hits:1|c\nquota_queue:1|g")
+	l.HandlePacket("b", "quota_queue:2|g")
+
+	assert.Equal(t, []string{"quota_hits"}, storage.Series(metrics.CounterName))
+	queue, ok := storage.GetGaugeValue("quota_queue")
+	require.True(t, ok)
+	assert.Equal(t, metrics.Gauge(2), queue)
+}
+
+func TestListener_QuotaTimer(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	l := NewListener(storage, time.Second)
+	l.SetQuota(quota.NewLimiter(quota.Limits{MaxSeries: len(timerSuffixes())}))
+
+	// a timer is flushed as several gauges and takes a series for each
+	l.HandlePacket("a", "quota_rt:10|ms\nquota_load:1|g")
+	l.HandlePacket("b", "quota_load:1|g\nquota_rt:10|ms")
+	l.Flush()
+
+	assert.Equal(t, []string{
+		"quota_load", "quota_rt.count", "quota_rt.lower", "quota_rt.mean",
+		"quota_rt.p90", "quota_rt.p99", "quota_rt.sum", "quota_rt.upper",
+	}, storage.Series(metrics.GaugeName))
+	count, ok := storage.GetGaugeValue("quota_rt.count")
+	require.True(t, ok)
+	assert.Equal(t, metrics.Gauge(1), count)
+}
+
+func TestListener_FlushTimers(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	l := NewListener(storage, time.Second)
+
+	for _, line := range []string{"rt:10|ms", "rt:20|ms", "rt:30|ms", "rt:40|ms|@0.5"} {
+		l.HandlePacket("test", line)
+	}
+	l.Flush()
+
+	want := map[string]metrics.Gauge{
+		"rt.count": 5,
+		"rt.sum":   140,
+		"rt.mean":  28,
+		"rt.lower": 10,
+		"rt.upper": 40,
+		"rt.p90":   40,
+	}
+	for name, val := range want {
+		got, ok := storage.GetGaugeValue(name)
+		require.True(t, ok, name)
+		assert.Equal(t, val, got, name)
+	}
+
+	// timers are reset after every flush
+	l.Flush()
+	count, _ := storage.GetGaugeValue("rt.count")
+	assert.Equal(t, metrics.Gauge(5), count)
+}
+
+func TestListener_ServeUDPAndTCP(t *testing.T) {
+	storage := memstorage.NewMetricsStorage()
+	l := NewListener(storage, time.Second)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = l.ServeUDP(conn) }()
+	go func() { _ = l.ServeTCP(ln) }()
+	defer conn.Close()
+	defer ln.Close()
+
+	udp, err := net.Dial("udp", conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer udp.Close()
+	_, err = udp.Write([]byte("udp_hits:1|c"))
+	require.NoError(t, err)
+
+	tcp, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	_, err = tcp.Write([]byte("tcp_hits:2|c\ntcp_hits:3|c\n"))
+	require.NoError(t, err)
+	require.NoError(t, tcp.Close())
+
+	assert.Eventually(t, func() bool {
+		udpHits, _ := storage.GetCounterValue("udp_hits")
+		tcpHits, _ := storage.GetCounterValue("tcp_hits")
+		return udpHits == 1 && tcpHits == 5
+	}, time.Second, 10*time.Millisecond)
+}
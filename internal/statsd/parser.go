@@ -0,0 +1,113 @@
+package statsd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	TypeCounter      = "c"
+	TypeGauge        = "g"
+	TypeTimer        = "ms"
+	TypeHistogram    = "h"
+	TypeDistribution = "d"
+	TypeSet          = "s"
+)
+
+// Line is a single parsed StatsD metric.
+type Line struct {
+	Name       string
+	Type       string
+	Value      float64
+	Member     string // set member, the raw value of "s" lines
+	Relative   bool   // gauge value starts with an explicit sign
+	SampleRate float64
+	Tags       map[string]string
+}
+
+// ParseLine parses `name:value|type[|@rate][|#tag:value,...]`.
+func ParseLine(line string) (Line, error) {
+	colon := strings.LastIndexByte(line, ':')
+	if i := strings.IndexByte(line, '|'); i >= 0 {
+		colon = strings.LastIndexByte(line[:i], ':')
+	}
+	if colon <= 0 {
+		return Line{}, fmt.Errorf("missing metric name in %q", line)
+	}
+
+	parts := strings.Split(line[colon+1:], "|")
+	if len(parts) < 2 {
+		return Line{}, fmt.Errorf("missing metric type in %q", line)
+	}
+
+	l := Line{
+		Name:       line[:colon],
+		Type:       parts[1],
+		SampleRate: 1,
+	}
+
+	switch l.Type {
+	case TypeSet:
+		l.Member = parts[0]
+	case TypeCounter, TypeGauge, TypeTimer, TypeHistogram, TypeDistribution:
+		val, err := strconv.ParseFloat(parts[0], 64)
+		if err != nil {
+			return Line{}, fmt.Errorf("invalid value in %q: %w", line, err)
+		}
+		l.Value = val
+		l.Relative = l.Type == TypeGauge && (parts[0][0] == '+' || parts[0][0] == '-')
+	default:
+		return Line{}, fmt.Errorf("unknown metric type %q in %q", l.Type, line)
+	}
+
+	for _, part := range parts[2:] {
+		switch {
+		case strings.HasPrefix(part, "@"):
+			rate, err := strconv.ParseFloat(part[1:], 64)
+			if err != nil || rate <= 0 || rate > 1 {
+				return Line{}, fmt.Errorf("invalid sample rate in %q", line)
+			}
+			l.SampleRate = rate
+		case strings.HasPrefix(part, "#"):
+			l.Tags = parseTags(part[1:])
+		default:
+			return Line{}, fmt.Errorf("unknown section %q in %q", part, line)
+		}
+	}
+	return l, nil
+}
+
+// parseTags parses DogStatsD tags `key:value,key2:value2`. A tag without a
+// value is kept with an empty one.
+func parseTags(s string) map[string]string {
+	tags := make(map[string]string)
+	for _, tag := range strings.Split(s, ",") {
+		if tag == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(tag, ":")
+		tags[key] = value
+	}
+	return tags
+}
+
+// ParsePacket parses every non-empty newline separated line of a packet.
+// Lines that fail to parse are returned as errors and skipped.
+func ParsePacket(packet string) ([]Line, []error) {
+	var lines []Line
+	var errs []error
+	for _, raw := range strings.Split(packet, "\n") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		l, err := ParseLine(raw)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		lines = append(lines, l)
+	}
+	return lines, errs
+}
@@ -0,0 +1,75 @@
+package statsd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    Line
+		wantErr bool
+	}{
+		{
+			name: "counter",
+			line: "requests:1|c",
+			want: Line{Name: "requests", Type: TypeCounter, Value: 1, SampleRate: 1},
+		},
+		{
+			name: "sampled-counter-with-tags",
+			line: "requests:3|c|@0.5|#host:web-1,region:eu",
+			want: Line{
+				Name: "requests", Type: TypeCounter, Value: 3, SampleRate: 0.5,
+				Tags: map[string]string{"host": "web-1", "region": "eu"},
+			},
+		},
+		{
+			name: "gauge",
+			line: "temperature:21.5|g",
+			want: Line{Name: "temperature", Type: TypeGauge, Value: 21.5, SampleRate: 1},
+		},
+		{
+			name: "relative-gauge",
+			line: "queue:-4|g",
+			want: Line{Name: "queue", Type: TypeGauge, Value: -4, Relative: true, SampleRate: 1},
+		},
+		{
+			name: "timer",
+			line: "db.query:320|ms",
+			want: Line{Name: "db.query", Type: TypeTimer, Value: 320, SampleRate: 1},
+		},
+		{
+			name: "set",
+			line: "users:alice|s|#app",
+			want: Line{Name: "users", Type: TypeSet, Member: "alice", SampleRate: 1, Tags: map[string]string{"app": ""}},
+		},
+		{name: "no-name", line: ":1|c", wantErr: true},
+		{name: "no-type", line: "requests:1", wantErr: true},
+		{name: "unknown-type", line: "requests:1|x", wantErr: true},
+		{name: "invalid-value", line: "requests:abc|c", wantErr: true},
+		{name: "invalid-rate", line: "requests:1|c|@2", wantErr: true},
+		{name: "unknown-section", line: "requests:1|c|foo", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLine(tt.line)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParsePacket(t *testing.T) {
+	lines, errs := ParsePacket("a:1|c\n\nb:2|g\nbroken\n")
+	assert.Len(t, lines, 2)
+	assert.Len(t, errs, 1)
+}
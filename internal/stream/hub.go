@@ -0,0 +1,168 @@
+package stream
+
+import (
+	"fmt"
+	"path"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+const (
+	// OverflowDrop discards updates a slow subscriber has no room for and
+	// counts them, see Subscriber.TakeDropped.
+	OverflowDrop = "drop"
+	// OverflowDisconnect closes a subscriber as soon as its buffer is full.
+	OverflowDisconnect = "disconnect"
+)
+
+// Hub fans storage updates out to subscribers. It installs the storage update
+// hook while it has at least one subscriber.
+type Hub struct {
+	mu   sync.RWMutex
+	subs map[*Subscriber]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[*Subscriber]struct{})}
+}
+
+// Subscriber receives updates of metrics whose name matches one of its
+// patterns. Patterns use path.Match syntax, e.g. "Heap*"; no patterns match
+// every metric.
+type Subscriber struct {
+	patterns   []string
+	disconnect bool
+
+	events  chan metrics.Metrics
+	dropped atomic.Uint64
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// Subscribe registers a subscriber with a buffer of the given size. overflow
+// is OverflowDrop or OverflowDisconnect.
+func (h *Hub) Subscribe(patterns []string, buffer int, overflow string) (*Subscriber, error) {
+	for _, p := range patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+	if overflow != OverflowDrop && overflow != OverflowDisconnect {
+		return nil, fmt.Errorf("unknown overflow policy %q", overflow)
+	}
+
+	s := &Subscriber{
+		patterns:   patterns,
+		disconnect: overflow == OverflowDisconnect,
+		events:     make(chan metrics.Metrics, buffer),
+		done:       make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subs[s] = struct{}{}
+	if len(h.subs) == 1 {
+		memstorage.SetUpdateHook(h.Publish)
+	}
+	return s, nil
+}
+
+func (h *Hub) Unsubscribe(s *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[s]; !ok {
+		return
+	}
+	delete(h.subs, s)
+	s.close()
+	if len(h.subs) == 0 {
+		memstorage.SetUpdateHook(nil)
+	}
+}
+
+// Len returns the number of subscribers.
+func (h *Hub) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs)
+}
+
+// Publish passes an update to every matching subscriber without blocking. An
+// update that cannot be converted is skipped, the following ones are still
+// published.
+func (h *Hub) Publish(series string, mType string, val fmt.Stringer) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.subs) == 0 {
+		return
+	}
+	metric, err := metrics.FromValue(series, mType, val)
+	if err != nil {
+		log.Error().Err(err).Msgf("skipped update of %s", series)
+		return
+	}
+
+	for s := range h.subs {
+		if s.closed() || !s.matches(metric.ID) {
+			continue
+		}
+
+		select {
+		case s.events <- metric:
+		default:
+			if s.disconnect {
+				s.close()
+			} else {
+				s.dropped.Add(1)
+			}
+		}
+	}
+}
+
+// Events delivers the updates. It is never closed, use Done to stop reading.
+func (s *Subscriber) Events() <-chan metrics.Metrics {
+	return s.events
+}
+
+// Done is closed once the subscriber is unsubscribed or disconnected for
+// being too slow.
+func (s *Subscriber) Done() <-chan struct{} {
+	return s.done
+}
+
+// TakeDropped returns the number of updates dropped since the previous call.
+func (s *Subscriber) TakeDropped() uint64 {
+	return s.dropped.Swap(0)
+}
+
+func (s *Subscriber) matches(name string) bool {
+	if len(s.patterns) == 0 {
+		return true
+	}
+	for _, p := range s.patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Subscriber) close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+func (s *Subscriber) closed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,72 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metrics "github.com/a-palonskaa/metrics-server/internal/metrics"
+	memstorage "github.com/a-palonskaa/metrics-server/internal/metrics_storage"
+)
+
+func TestHub_Patterns(t *testing.T) {
+	hub := NewHub()
+	sub, err := hub.Subscribe([]string{"Heap*"}, 4, OverflowDrop)
+	require.NoError(t, err)
+	defer hub.Unsubscribe(sub)
+
+	storage := memstorage.NewMetricsStorage()
+	storage.AddGauge("HeapAlloc", 10)
+	storage.AddGauge("Sys", 20)
+	storage.AddGauge(`HeapInuse{host="a"}`, 30)
+
+	got := []string{(<-sub.Events()).ID, (<-sub.Events()).ID}
+	assert.Equal(t, []string{"HeapAlloc", "HeapInuse"}, got)
+	assert.Empty(t, sub.Events())
+}
+
+func TestHub_SkipInvalid(t *testing.T) {
+	hub := NewHub()
+	sub, err := hub.Subscribe(nil, 4, OverflowDrop)
+	require.NoError(t, err)
+	defer hub.Unsubscribe(sub)
+
+	hub.Publish(`Load{host=`, metrics.GaugeName, metrics.Gauge(1))
+	hub.Publish("Load", "unknown", metrics.Gauge(2))
+	hub.Publish("Load", metrics.GaugeName, metrics.Gauge(3))
+
+	require.Len(t, sub.Events(), 1)
+	got := <-sub.Events()
+	assert.Equal(t, 3.0, *got.Value)
+}
+
+func TestHub_Overflow(t *testing.T) {
+	hub := NewHub()
+	dropping, err := hub.Subscribe(nil, 1, OverflowDrop)
+	require.NoError(t, err)
+	defer hub.Unsubscribe(dropping)
+	disconnecting, err := hub.Subscribe(nil, 1, OverflowDisconnect)
+	require.NoError(t, err)
+	defer hub.Unsubscribe(disconnecting)
+
+	for i := range 3 {
+		hub.Publish("PollCount", metrics.CounterName, metrics.Counter(i))
+	}
+
+	assert.Equal(t, uint64(2), dropping.TakeDropped())
+	assert.Zero(t, dropping.TakeDropped())
+	select {
+	case <-disconnecting.Done():
+	default:
+		t.Error("slow subscriber was not disconnected")
+	}
+}
+
+func TestHub_Subscribe(t *testing.T) {
+	hub := NewHub()
+	_, err := hub.Subscribe([]string{"["}, 1, OverflowDrop)
+	assert.Error(t, err)
+	_, err = hub.Subscribe(nil, 1, "block")
+	assert.Error(t, err)
+}